@@ -0,0 +1,365 @@
+// Command pksctl runs administrative tasks against the PKS-DB database.
+//
+// Usage:
+//
+//	pksctl access-report -nik <NIK> [-format json|html] [-o file]
+//	pksctl audit-export -o <file> [-format csv|ndjson] [-redact none|hash|redact] [filters]
+//	pksctl audit-partitions [-list] [-retention months] [-archive dir]
+//	pksctl audit-replay [-file audit-spill.ndjson] [-batch 500] [-quarantine audit-quarantine.ndjson]
+//	pksctl verify-audit [-partner <partner_id> [-from seq] [-to seq]] [-o file]
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/username/go-gin-backend/internal/config"
+	"github.com/username/go-gin-backend/internal/db"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/internal/service"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "access-report":
+		err = accessReport(os.Args[2:])
+	case "audit-export":
+		err = auditExport(os.Args[2:])
+	case "audit-partitions":
+		err = auditPartitions(os.Args[2:])
+	case "audit-replay":
+		err = auditReplay(os.Args[2:])
+	case "verify-audit":
+		err = verifyAudit(os.Args[2:])
+	case "-h", "--help", "help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: pksctl <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	fmt.Fprintln(os.Stderr, "  access-report    Report which partners looked up a NIK (-nik, -format json|html, -o file)")
+	fmt.Fprintln(os.Stderr, "  audit-export     Export audit logs as CSV/NDJSON with a .sha256 checksum file (-o file, -format, -redact, filters)")
+	fmt.Fprintln(os.Stderr, "  audit-partitions Create upcoming audit partitions, archive and drop expired ones (-list, -retention, -archive)")
+	fmt.Fprintln(os.Stderr, "  audit-replay     Insert audit logs spilled by the server after failed writes (-file, -batch, -quarantine)")
+	fmt.Fprintln(os.Stderr, "  verify-audit     Verify the audit log hash chains and signed checkpoints (-partner, -from, -to, -o file); exit 1 on problems")
+}
+
+// connect opens the database configured in the environment / .env
+func connect() (*sql.DB, error) {
+	cfg := config.LoadConfig()
+	return db.ConnectDB(cfg.DatabaseURL)
+}
+
+// auditPrivacy returns the audit privacy policy of the environment (AUDIT_NIK_KEY for NIK lookups)
+func auditPrivacy(database *sql.DB) (*service.AuditPrivacy, error) {
+	cfg := config.LoadConfig()
+	key, err := utils.LoadNIKKey(cfg.AuditNIKKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AUDIT_NIK_KEY: %w", err)
+	}
+	return service.NewAuditPrivacy(repository.NewPartnerRepository(database), cfg.AuditPayloadPolicy, key), nil
+}
+
+// output returns the writer for -o (stdout when empty) and a function closing it
+func output(path string) (io.Writer, func() error, error) {
+	if path == "" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
+
+// accessReport writes the data-subject access report of a NIK
+func accessReport(args []string) error {
+	fs := flag.NewFlagSet("access-report", flag.ExitOnError)
+	nik := fs.String("nik", "", "NIK of the worker (required)")
+	format := fs.String("format", models.AccessReportFormatJSON, "output format: json or html")
+	out := fs.String("o", "", "output file (default stdout)")
+	fs.Parse(args)
+
+	if *nik == "" {
+		fs.Usage()
+		return fmt.Errorf("-nik is required")
+	}
+	if *format != models.AccessReportFormatJSON && *format != models.AccessReportFormatHTML {
+		return fmt.Errorf("-format must be json or html")
+	}
+
+	database, err := connect()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	privacy, err := auditPrivacy(database)
+	if err != nil {
+		return err
+	}
+
+	reports := service.NewAccessReportService(repository.NewAuditRepository(database), privacy)
+	report, err := reports.Generate(context.Background(), *nik)
+	if err != nil {
+		return err
+	}
+
+	w, closeOutput, err := output(*out)
+	if err != nil {
+		return err
+	}
+	if *format == models.AccessReportFormatHTML {
+		err = reports.WriteHTML(w, report)
+	} else {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	}
+	if cerr := closeOutput(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// auditExport streams the matching audit logs to a file and writes <file>.sha256 next to it
+func auditExport(args []string) error {
+	fs := flag.NewFlagSet("audit-export", flag.ExitOnError)
+	var req models.AuditExportRequest
+	fs.StringVar(&req.PartnerID, "partner", "", "partner ID")
+	fs.StringVar(&req.NIK, "nik", "", "NIK")
+	fs.StringVar(&req.From, "from", "", "from date, YYYY-MM-DD or RFC3339 (inclusive)")
+	fs.StringVar(&req.To, "to", "", "to date, YYYY-MM-DD (inclusive day) or RFC3339 (exclusive)")
+	fs.StringVar(&req.Outcome, "outcome", "", "comma-separated result codes, or found / not_found")
+	fs.StringVar(&req.Scope, "scope", "", "scope enabled at the time of the check")
+	fs.StringVar(&req.Format, "format", models.AuditExportFormatCSV, "output format: csv or ndjson")
+	fs.StringVar(&req.Redact, "redact", "", "NIK and payload redaction: none, hash or redact (default: hash with AUDIT_NIK_KEY, else redact)")
+	out := fs.String("o", "", "output file (required)")
+	fs.Parse(args)
+
+	if *out == "" {
+		fs.Usage()
+		return fmt.Errorf("-o is required")
+	}
+
+	database, err := connect()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	privacy, err := auditPrivacy(database)
+	if err != nil {
+		return err
+	}
+
+	audits := service.NewAuditService(repository.NewAuditRepository(database), privacy)
+	export, err := audits.NewExport(&req)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	result, err := audits.WriteExport(context.Background(), export, w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	checksum := fmt.Sprintf("%s  %s\n", result.SHA256, filepath.Base(*out))
+	if err := os.WriteFile(*out+".sha256", []byte(checksum), 0o644); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d audit logs to %s (sha256 %s)\n", result.Rows, *out, result.SHA256)
+	return nil
+}
+
+// auditReplay inserts the audit logs of a spill file. The file is renamed first so the server
+// starts a new one for later failures; replaying the renamed file again is safe.
+func auditReplay(args []string) error {
+	fs := flag.NewFlagSet("audit-replay", flag.ExitOnError)
+	file := fs.String("file", "audit-spill.ndjson", "spill file (AUDIT_SPILL_FILE of the server)")
+	batch := fs.Int("batch", 500, "audit logs per INSERT")
+	quarantine := fs.String("quarantine", "audit-quarantine.ndjson", "file receiving audit logs the database rejects (AUDIT_QUARANTINE_FILE of the server)")
+	fs.Parse(args)
+
+	if *batch < 1 || *batch > repository.MaxAuditBatchSize {
+		return fmt.Errorf("-batch must be between 1 and %d", repository.MaxAuditBatchSize)
+	}
+
+	path := *file
+	if !strings.HasSuffix(path, ".replaying") {
+		path = fmt.Sprintf("%s.%s.replaying", *file, time.Now().UTC().Format("20060102T150405Z"))
+		if err := os.Rename(*file, path); err != nil {
+			return err
+		}
+	}
+
+	database, err := connect()
+	if err != nil {
+		return fmt.Errorf("%w (spill file kept at %s)", err, path)
+	}
+	defer database.Close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	total, quarantined, err := service.ReplayAuditSpill(context.Background(), repository.NewAuditRepository(database), bufio.NewReader(f), *batch, *quarantine)
+	if err != nil {
+		return fmt.Errorf("%w (%d audit logs replayed; re-run with -file %s)", err, total, path)
+	}
+
+	fmt.Fprintf(os.Stderr, "Replayed %d audit logs from %s, the file can be deleted\n", total, path)
+	if quarantined > 0 {
+		fmt.Fprintf(os.Stderr, "%d audit logs rejected by the database were quarantined to %s\n", quarantined, *quarantine)
+	}
+	return nil
+}
+
+// auditPartitions runs the audit partition maintenance once (as the server does periodically) and
+// writes the JSON report; with -list it only lists the partitions
+func auditPartitions(args []string) error {
+	cfg := config.LoadConfig()
+
+	fs := flag.NewFlagSet("audit-partitions", flag.ExitOnError)
+	list := fs.Bool("list", false, "only list the partitions")
+	retention := fs.Int("retention", cfg.AuditRetentionMon, "full months kept before archiving, 0 keeps everything (AUDIT_RETENTION_MONTHS)")
+	archiveDir := fs.String("archive", cfg.AuditArchiveDir, "archive directory (AUDIT_ARCHIVE_DIR)")
+	fs.Parse(args)
+
+	database, err := db.ConnectDB(cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	ctx := context.Background()
+	auditRepo := repository.NewAuditRepository(database)
+
+	var report interface{}
+	if *list {
+		if report, err = auditRepo.ListPartitions(ctx); err != nil {
+			return err
+		}
+	} else {
+		partitions := service.NewAuditPartitionService(auditRepo, service.AuditPartitionConfig{
+			AheadMonths:     cfg.AuditPartsAhead,
+			RetentionMonths: *retention,
+			ArchiveDir:      *archiveDir,
+		})
+		result, err := partitions.Maintain(ctx)
+		if err != nil {
+			return err
+		}
+		if result.DefaultRows > 0 {
+			fmt.Fprintf(os.Stderr, "WARNING: %d audit logs are in audit_logs_default (outside every monthly partition)\n", result.DefaultRows)
+		}
+		for _, failed := range result.Failed {
+			fmt.Fprintf(os.Stderr, "WARNING: partition not created: %s\n", failed)
+		}
+		fmt.Fprintf(os.Stderr, "Created %d partitions (%d rows moved from the default partition), archived and dropped %d\n",
+			len(result.Created), result.MovedRows, len(result.Dropped))
+		report = result
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// verifyAudit walks the audit hash chains and writes the JSON report. Checkpoint signatures are
+// checked with RECEIPT_SIGNING_KEY / RECEIPT_KEY_ID; without the key only the chains are verified.
+func verifyAudit(args []string) error {
+	fs := flag.NewFlagSet("verify-audit", flag.ExitOnError)
+	partnerID := fs.String("partner", "", "partner ID (default all partners)")
+	fromSeq := fs.Int64("from", 0, "first chain position to verify, requires -partner (default chain start)")
+	toSeq := fs.Int64("to", 0, "last chain position to verify, requires -partner (default chain head)")
+	out := fs.String("o", "", "output file (default stdout)")
+	fs.Parse(args)
+
+	cfg := config.LoadConfig()
+	key, generated, err := utils.LoadReceiptKey(cfg.ReceiptSigningKey)
+	if err != nil {
+		return fmt.Errorf("invalid RECEIPT_SIGNING_KEY: %w", err)
+	}
+	if generated {
+		fmt.Fprintln(os.Stderr, "WARNING: RECEIPT_SIGNING_KEY is empty, checkpoint signatures are not verified")
+		key = nil
+	}
+
+	database, err := db.ConnectDB(cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	chain := service.NewAuditChainService(repository.NewAuditRepository(database), key, cfg.ReceiptKeyID)
+	report, err := chain.Verify(context.Background(), *partnerID, *fromSeq, *toSeq)
+	if err != nil {
+		return err
+	}
+
+	w, closeOutput, err := output(*out)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(report)
+	if cerr := closeOutput(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	if !report.Valid {
+		return fmt.Errorf("%d audit chain problems found in %d rows", len(report.Problems), report.Rows)
+	}
+	fmt.Fprintf(os.Stderr, "Audit chain intact: %d rows, %d partners, %d checkpoints (%d rows before the chain)\n",
+		report.Rows, report.Partners, report.Checkpoints, report.Unchained)
+	return nil
+}
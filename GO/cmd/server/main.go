@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/username/go-gin-backend/internal/config"
 	"github.com/username/go-gin-backend/internal/db"
+	"github.com/username/go-gin-backend/internal/repository"
 	"github.com/username/go-gin-backend/internal/routes"
+	"github.com/username/go-gin-backend/internal/service"
+	"github.com/username/go-gin-backend/pkg/utils"
 )
 
 func main() {
@@ -25,10 +30,101 @@ func main() {
 
 	fmt.Println("✅ Database connected successfully")
 
+	// Partner API keys are stored as HMAC hashes keyed with the pepper (changing it invalidates every pks_live_ key)
+	apiKeyPepper, err := utils.LoadAPIKeyPepper(cfg.APIKeyPepper)
+	if err != nil {
+		log.Fatalf("Invalid API_KEY_PEPPER: %v", err)
+	}
+	if apiKeyPepper == nil {
+		if cfg.Environment == "production" {
+			log.Fatal("API_KEY_PEPPER is required in production mode, API key secrets would be hashed without a pepper")
+		}
+		log.Println("WARNING: API_KEY_PEPPER is empty, API key secrets are hashed without a pepper")
+	}
+
+	// Audit redaction: NIK token and minimal payloads (full payloads for partners being debugged)
+	nikKey, err := utils.LoadNIKKey(cfg.AuditNIKKey)
+	if err != nil {
+		log.Fatalf("Invalid AUDIT_NIK_KEY: %v", err)
+	}
+	if nikKey == nil {
+		if cfg.Environment == "production" {
+			log.Fatal("AUDIT_NIK_KEY is required in production mode, audit logs would store the plaintext NIK")
+		}
+		log.Println("WARNING: AUDIT_NIK_KEY is empty, audit logs store the plaintext NIK")
+	}
+	auditPrivacy := service.NewAuditPrivacy(repository.NewPartnerRepository(database), cfg.AuditPayloadPolicy, nikKey)
+
+	// Audit pipeline: checks are queued and inserted in batches by a single writer
+	auditWriter := service.NewAuditWriter(repository.NewAuditRepository(database), auditPrivacy, service.AuditWriterConfig{
+		QueueSize:      cfg.AuditQueueSize,
+		BatchSize:      cfg.AuditBatchSize,
+		FlushInterval:  time.Duration(cfg.AuditFlushMillis) * time.Millisecond,
+		Policy:         cfg.AuditQueuePolicy,
+		SpillFile:      cfg.AuditSpillFile,
+		QuarantineFile: cfg.AuditQuarantine,
+	})
+	if queueSize := auditWriter.Config.QueueSize; queueSize < cfg.BatchCheckMaxItems || queueSize < cfg.CheckJobChunkSize {
+		log.Fatalf("AUDIT_QUEUE_SIZE (%d) must be at least BATCH_CHECK_MAX_ITEMS (%d) and CHECK_JOB_CHUNK_SIZE (%d), a batch is queued as a unit",
+			queueSize, cfg.BatchCheckMaxItems, cfg.CheckJobChunkSize)
+	}
+	auditWriter.Start()
+
+	// Start background worker for bulk check jobs (resumes unfinished jobs after a restart)
+	checkJobService := service.NewCheckJobService(
+		repository.NewCheckJobRepository(database),
+		repository.NewPartnerRepository(database),
+		repository.NewScopeRepository(database),
+		service.NewCheckingService(
+			repository.NewTKRepository(database),
+			auditWriter,
+			repository.NewTKHistoryRepository(database),
+			service.NewScopeRegistry(repository.NewScopeDefinitionRepository(database)),
+			repository.NewPurposeRepository(database),
+			repository.NewConsentRepository(database),
+			nil, // job results carry no receipts
+		),
+		cfg.CheckJobMaxRows,
+		cfg.CheckJobChunkSize,
+		time.Duration(cfg.CheckJobPollSecs)*time.Second,
+		time.Duration(cfg.CheckJobKeepDays)*24*time.Hour,
+	)
+	checkJobService.Start()
+
+	// Signing key for verification receipts
+	receiptKey, generated, err := utils.LoadReceiptKey(cfg.ReceiptSigningKey)
+	if err != nil {
+		log.Fatalf("Invalid RECEIPT_SIGNING_KEY: %v", err)
+	}
+	if generated {
+		if cfg.Environment == "production" {
+			log.Fatal("RECEIPT_SIGNING_KEY is required in production mode, receipts and checkpoints could not be verified after a restart")
+		}
+		log.Println("WARNING: RECEIPT_SIGNING_KEY is empty, using a temporary key (receipts cannot be verified after a restart)")
+	}
+	receiptService := service.NewReceiptService(repository.NewAuditRepository(database), auditPrivacy, receiptKey, cfg.ReceiptKeyID)
+
+	// Signed checkpoints of the audit hash chains (same key; none with a temporary key)
+	checkpointKey := receiptKey
+	if generated {
+		checkpointKey = nil
+	}
+	auditChainService := service.NewAuditChainService(repository.NewAuditRepository(database), checkpointKey, cfg.ReceiptKeyID)
+	auditChainService.Start(time.Duration(cfg.AuditCheckpointMin) * time.Minute)
+
+	// Monthly audit_logs partitions ahead of time, retention (archive then drop) of the old ones
+	auditPartitionService := service.NewAuditPartitionService(repository.NewAuditRepository(database), service.AuditPartitionConfig{
+		AheadMonths:     cfg.AuditPartsAhead,
+		RetentionMonths: cfg.AuditRetentionMon,
+		ArchiveDir:      cfg.AuditArchiveDir,
+	})
+	auditPartitionService.Start(time.Duration(cfg.AuditPartitionHrs) * time.Hour)
+
 	// Setup routes with Fiber
-	app := routes.SetupRoutes(database, cfg)
+	app := routes.SetupRoutes(database, cfg, checkJobService, receiptService, auditWriter, auditPrivacy, auditChainService, apiKeyPepper)
 
-	// Graceful shutdown
+	// Graceful shutdown: stop accepting requests and wait for in-flight ones, then drain the workers below
+	idle := make(chan struct{})
 	go func() {
 		sigint := make(chan os.Signal, 1)
 		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
@@ -36,17 +132,11 @@ func main() {
 
 		fmt.Println("\n🛑 Shutting down server...")
 
-		// Close database connection
-		if err := database.Close(); err != nil {
-			log.Printf("Error closing database: %v", err)
-		}
-
-		// Shutdown Fiber app gracefully
+		// Shutdown Fiber app gracefully (waits for in-flight requests)
 		if err := app.Shutdown(); err != nil {
 			log.Printf("Error shutting down server: %v", err)
 		}
-
-		os.Exit(0)
+		close(idle)
 	}()
 
 	// Start server
@@ -59,8 +149,15 @@ func main() {
 	fmt.Println()
 	fmt.Println("📍 Available Endpoints:")
 	fmt.Println("   - POST /api/checking (X-API-KEY header required)")
+	fmt.Println("   - POST /api/checking/batch (X-API-KEY header required)")
+	fmt.Println("   - POST /api/checking/verify (X-API-KEY header required)")
+	fmt.Println("   - POST /api/checking/jobs (X-API-KEY header required, CSV upload)")
+	fmt.Println("   - GET  /api/checking/jobs/:id (X-API-KEY header required)")
+	fmt.Println("   - GET  /api/checking/jobs/:id/results (X-API-KEY header required)")
 	fmt.Println("   - POST /api/v1/auth/admin/login")
 	fmt.Println("   - GET  /api/health")
+	fmt.Println("   - GET  /.well-known/jwks.json")
+	fmt.Println("   - POST /api/receipts/verify")
 	fmt.Println("   - POST /admin/partners (JWT)")
 	fmt.Println("   - GET  /admin/partners (JWT)")
 	fmt.Println("   - GET  /admin/partners/:id (JWT)")
@@ -68,12 +165,83 @@ func main() {
 	fmt.Println("   - DELETE /admin/partners/:id (JWT)")
 	fmt.Println("   - GET  /admin/partners/:id/scopes (JWT)")
 	fmt.Println("   - PUT  /admin/partners/:id/scopes (JWT)")
+	fmt.Println("   - GET  /admin/partners/:id/scope-versions (JWT)")
+	fmt.Println("   - GET  /admin/partners/:id/scope-versions/diff (JWT, ?from, ?to)")
+	fmt.Println("   - POST /admin/partners/:id/scopes/rollback (JWT)")
+	fmt.Println("   - GET  /admin/partners/:id/scope-package (JWT)")
+	fmt.Println("   - PUT  /admin/partners/:id/scope-package (JWT)")
+	fmt.Println("   - GET  /admin/partners/:id/purposes (JWT)")
+	fmt.Println("   - PUT  /admin/partners/:id/purposes (JWT)")
+	fmt.Println("   - PUT  /admin/partners/:id/audit-debug (JWT, {\"hours\": 0-168})")
+	fmt.Println("   - GET  /admin/partners/:id/api-keys (JWT)")
+	fmt.Println("   - POST /admin/partners/:id/api-keys (JWT, {\"label\", \"expires_at\"?})")
+	fmt.Println("   - POST /admin/partners/:id/api-keys/:keyId/rotate (JWT, {\"grace_hours\"?})")
+	fmt.Println("   - DELETE /admin/partners/:id/api-keys/:keyId (JWT)")
 	fmt.Println("   - POST /admin/partners/:id/reset-api-key (JWT)")
+	fmt.Println("   - GET  /admin/scopes (JWT)")
+	fmt.Println("   - POST /admin/scopes (JWT)")
+	fmt.Println("   - PUT  /admin/scopes/:name (JWT)")
+	fmt.Println("   - DELETE /admin/scopes/:name (JWT)")
+	fmt.Println("   - GET  /admin/purposes (JWT)")
+	fmt.Println("   - POST /admin/purposes (JWT)")
+	fmt.Println("   - PUT  /admin/purposes/:code (JWT)")
+	fmt.Println("   - GET  /admin/consents (JWT, ?nik, ?partner_id)")
+	fmt.Println("   - POST /admin/consents (JWT)")
+	fmt.Println("   - POST /admin/consents/import (JWT, CSV/XLSX, ?partner_id, ?dry_run=true)")
+	fmt.Println("   - GET  /admin/consents/:id (JWT)")
+	fmt.Println("   - POST /admin/consents/:id/revoke (JWT)")
+	fmt.Println("   - GET  /admin/scope-packages (JWT)")
+	fmt.Println("   - POST /admin/scope-packages (JWT)")
+	fmt.Println("   - GET  /admin/scope-packages/:id (JWT)")
+	fmt.Println("   - PUT  /admin/scope-packages/:id (JWT)")
+	fmt.Println("   - DELETE /admin/scope-packages/:id (JWT)")
+	fmt.Println("   - GET  /admin/audit-logs (JWT, ?partner_id, ?nik, ?from, ?to, ?outcome, ?scope, ?cursor)")
+	fmt.Println("   - GET  /admin/audit-logs/writer (JWT)")
+	fmt.Println("   - GET  /admin/audit-logs/verify (JWT, ?partner_id&from_seq&to_seq)")
+	fmt.Println("   - GET  /admin/audit-logs/export (JWT, ?format=csv|ndjson, ?redact=none|hash|redact, same filters)")
+	fmt.Println("   - GET  /admin/audit-logs/:id (JWT)")
+	fmt.Println("   - GET  /admin/tk (JWT, ?page, ?limit, ?q)")
+	fmt.Println("   - POST /admin/tk (JWT)")
+	fmt.Println("   - POST /admin/tk/import (JWT, CSV/XLSX, ?dry_run=true)")
+	fmt.Println("   - GET  /admin/tk/:nik (JWT)")
+	fmt.Println("   - GET  /admin/tk/:nik/history (JWT)")
+	fmt.Println("   - GET  /admin/tk/:nik/access-report (JWT, ?format=json|html)")
+	fmt.Println("   - PUT  /admin/tk/:nik (JWT)")
+	fmt.Println("   - DELETE /admin/tk/:nik (JWT)")
 	fmt.Println()
 
 	if err := app.Listen(addr); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
+	<-idle
+
+	// Stop partition maintenance (an interrupted archive is resumed on next start)
+	auditPartitionService.Stop()
+
+	// Stop job worker before closing the database (in-flight chunk is resumed on next start)
+	checkJobService.Stop()
+
+	// Flush queued audit logs (rows that cannot be inserted go to the spill or quarantine file)
+	auditWriter.Stop()
+	if m := auditWriter.Metrics(); m.Spilled > 0 || m.Lost > 0 {
+		log.Printf("WARNING: %d audit logs spilled to %s, %d lost; replay with: pksctl audit-replay -file %s", m.Spilled, m.SpillFile, m.Lost, m.SpillFile)
+	}
+	if m := auditWriter.Metrics(); m.Quarantined > 0 {
+		log.Printf("WARNING: %d audit logs rejected by the database were quarantined to %s", m.Quarantined, m.QuarantineFile)
+	}
+
+	// Sign a last checkpoint covering the flushed audit logs
+	auditChainService.Stop()
+	if !generated {
+		if _, err := auditChainService.Checkpoint(context.Background()); err != nil {
+			log.Printf("Error signing audit checkpoint: %v", err)
+		}
+	}
+
+	// Close database connection
+	if err := database.Close(); err != nil {
+		log.Printf("Error closing database: %v", err)
+	}
 }
 
 // maskAPIKey masks the API key for security in logs
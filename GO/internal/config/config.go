@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -16,6 +17,30 @@ type Config struct {
 	JWTSecret      string
 	PlatformAPIKey string // API key for server-to-server authentication
 	PartnerTokenTTL int64  // TTL in seconds for partner JWT
+	BatchCheckMaxItems int // Maximum number of items accepted by /api/checking/batch
+	MaxUploadSizeMB    int // Maximum request body size (CSV uploads)
+	CheckJobMaxRows    int // Maximum rows per bulk check job
+	CheckJobChunkSize  int // Rows processed per chunk by the job worker
+	CheckJobPollSecs   int // Interval in seconds between job worker polls
+	CheckJobKeepDays   int // Days finished jobs and their results are kept (0 = keep forever)
+	ReceiptSigningKey  string // Base64 Ed25519 seed (32 bytes) for verification receipts
+	ReceiptKeyID       string // kid published in the JWKS
+	AuditQueueSize     int    // Audit logs buffered in memory before the queue-full policy applies
+	AuditBatchSize     int    // Audit logs per INSERT
+	AuditFlushMillis   int    // Maximum time in milliseconds an audit log waits for its batch
+	AuditQueuePolicy   string // block, drop or reject when the audit queue is full
+	AuditSpillFile     string // NDJSON file receiving audit logs that could not be inserted
+	AuditQuarantine    string // NDJSON file receiving audit logs the database rejected
+	AuditCheckpointMin int    // Interval in minutes between signed audit chain checkpoints
+	AuditPartsAhead    int    // Monthly audit_logs partitions created ahead of the current month
+	AuditRetentionMon  int    // Full months of audit logs kept before archiving (0 = keep forever)
+	AuditArchiveDir    string // Directory receiving archived audit partitions
+	AuditPartitionHrs  int    // Interval in hours between audit partition maintenance runs
+	AuditNIKKey        string // Base64 HMAC key (32+ bytes) for NIK tokens in audit_logs (empty = plaintext NIK)
+	AuditPayloadPolicy string // minimal (field names only) or full audit payloads
+	APIKeyPepper       string // Base64 HMAC key (32+ bytes) for stored partner API key hashes
+	APIKeyGraceHours   int    // Hours a rotated partner API key stays valid (0 = revoked at once)
+	PartnerPurposes    []string // Purposes put in the contract of a new partner created without purposes
 }
 
 // LoadConfig loads configuration from environment variables
@@ -32,6 +57,30 @@ func LoadConfig() *Config {
 		JWTSecret:      getEnv("JWT_SECRET", "default-secret-change-in-production"),
 		PlatformAPIKey: getEnv("PLATFORM_API_KEY", ""),
 		PartnerTokenTTL: getEnvInt("PARTNER_TOKEN_TTL", 24*3600), // default 72 hours
+		BatchCheckMaxItems: int(getEnvInt("BATCH_CHECK_MAX_ITEMS", 1000)),
+		MaxUploadSizeMB:    int(getEnvInt("MAX_UPLOAD_SIZE_MB", 50)),
+		CheckJobMaxRows:    int(getEnvInt("CHECK_JOB_MAX_ROWS", 500000)),
+		CheckJobChunkSize:  int(getEnvInt("CHECK_JOB_CHUNK_SIZE", 1000)),
+		CheckJobPollSecs:   int(getEnvInt("CHECK_JOB_POLL_SECONDS", 5)),
+		CheckJobKeepDays:   int(getEnvInt("CHECK_JOB_RETENTION_DAYS", 30)),
+		ReceiptSigningKey:  getEnv("RECEIPT_SIGNING_KEY", ""),
+		ReceiptKeyID:       getEnv("RECEIPT_KEY_ID", "receipt-1"),
+		AuditQueueSize:     int(getEnvInt("AUDIT_QUEUE_SIZE", 10000)),
+		AuditBatchSize:     int(getEnvInt("AUDIT_BATCH_SIZE", 500)),
+		AuditFlushMillis:   int(getEnvInt("AUDIT_FLUSH_INTERVAL_MS", 200)),
+		AuditQueuePolicy:   getEnv("AUDIT_QUEUE_POLICY", "block"),
+		AuditSpillFile:     getEnv("AUDIT_SPILL_FILE", "audit-spill.ndjson"),
+		AuditQuarantine:    getEnv("AUDIT_QUARANTINE_FILE", "audit-quarantine.ndjson"),
+		AuditCheckpointMin: int(getEnvInt("AUDIT_CHECKPOINT_MINUTES", 60)),
+		AuditPartsAhead:    int(getEnvInt("AUDIT_PARTITIONS_AHEAD", 3)),
+		AuditRetentionMon:  int(getEnvInt("AUDIT_RETENTION_MONTHS", 0)),
+		AuditArchiveDir:    getEnv("AUDIT_ARCHIVE_DIR", "audit-archive"),
+		AuditPartitionHrs:  int(getEnvInt("AUDIT_PARTITION_INTERVAL_HOURS", 6)),
+		AuditNIKKey:        getEnv("AUDIT_NIK_KEY", ""),
+		AuditPayloadPolicy: getEnv("AUDIT_PAYLOAD_POLICY", "minimal"),
+		APIKeyPepper:       getEnv("API_KEY_PEPPER", ""),
+		APIKeyGraceHours:   int(getEnvInt("API_KEY_ROTATION_GRACE_HOURS", 24)),
+		PartnerPurposes:    getEnvList("PARTNER_DEFAULT_PURPOSES", "employment_verification"),
 	}
 
 	if config.PlatformAPIKey == "" && config.Environment == "production" {
@@ -50,6 +99,17 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+// getEnvList gets a comma-separated environment variable as a list without empty items
+func getEnvList(key, defaultValue string) []string {
+	var list []string
+	for _, item := range strings.Split(getEnv(key, defaultValue), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 // getEnvInt gets environment variable as int64 with fallback
 func getEnvInt(key string, defaultValue int64) int64 {
 	value := os.Getenv(key)
@@ -0,0 +1,38 @@
+-- Migration V10: Versioned history of partner scope changes
+-- Every write to a partner's partner_access_scopes rows stores a snapshot of all its rows
+-- (after the change) together with the acting admin, so changes can be diffed and rolled back.
+
+-- Step 1: Snapshot table
+CREATE TABLE IF NOT EXISTS partner_scope_versions (
+    id BIGSERIAL PRIMARY KEY,
+    partner_id UUID NOT NULL REFERENCES partners(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    scopes JSONB NOT NULL, -- [{scope_name, enabled, valid_from, valid_until}] ordered by scope_name
+    changed_by UUID, -- admin ID, NULL for the baseline snapshot
+    change_type VARCHAR(20) NOT NULL, -- baseline, create, update, rollback
+    rollback_of INT, -- restored version (rollback only)
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (partner_id, version),
+    CONSTRAINT chk_scope_version_change_type CHECK (change_type IN ('baseline', 'create', 'update', 'rollback'))
+);
+
+-- Step 2: Baseline snapshot of the current scopes of existing partners
+INSERT INTO partner_scope_versions (partner_id, version, scopes, change_type)
+SELECT p.id, 1,
+       COALESCE((
+           SELECT jsonb_agg(jsonb_build_object(
+                      'scope_name', s.scope_name,
+                      'enabled', s.enabled,
+                      'valid_from', s.valid_from,
+                      'valid_until', s.valid_until
+                  ) ORDER BY s.scope_name)
+           FROM partner_access_scopes s
+           WHERE s.partner_id = p.id
+       ), '[]'::jsonb),
+       'baseline'
+FROM partners p
+WHERE NOT EXISTS (SELECT 1 FROM partner_scope_versions v WHERE v.partner_id = p.id);
+
+-- Verification
+SELECT 'Migration V10 completed successfully!' as status;
+SELECT COUNT(*) AS baseline_versions FROM partner_scope_versions WHERE change_type = 'baseline';
@@ -0,0 +1,20 @@
+-- Migration V11: Verify-only scopes
+-- A scope either discloses its tk_field in check responses (mode 'disclose') or only allows
+-- the partner to compare a value it already holds against that field (mode 'verify').
+-- Verify scopes never return the stored value.
+
+-- Step 1: Scope mode
+ALTER TABLE scope_definitions ADD COLUMN IF NOT EXISTS mode VARCHAR(10) NOT NULL DEFAULT 'disclose';
+ALTER TABLE scope_definitions DROP CONSTRAINT IF EXISTS chk_scope_definition_mode;
+ALTER TABLE scope_definitions ADD CONSTRAINT chk_scope_definition_mode
+    CHECK (mode IN ('disclose', 'verify') AND (mode = 'disclose' OR tk_field IS NOT NULL));
+
+-- Step 2: Verify scopes for the attributes partners usually hold
+INSERT INTO scope_definitions (name, description, tk_field, sensitive, mode) VALUES
+    ('verify_name', 'Cocokkan nama yang dimiliki partner (tanpa mengembalikan nama)', 'nama', false, 'verify'),
+    ('verify_alamat', 'Cocokkan alamat yang dimiliki partner (tanpa mengembalikan alamat)', 'alamat', false, 'verify')
+ON CONFLICT (name) DO NOTHING;
+
+-- Verification
+SELECT 'Migration V11 completed successfully!' as status;
+SELECT name, tk_field, mode, active FROM scope_definitions ORDER BY mode, name;
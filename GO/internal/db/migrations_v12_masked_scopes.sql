@@ -0,0 +1,27 @@
+-- Migration V12: Masked-disclosure scopes
+-- Mode 'mask' returns the tk_field partially masked (e.g. "B*** S*****"). The rule is stored per scope:
+-- mask_visible = characters left visible at the start of each token,
+-- mask_tokens  = 'each' (every word masked separately) or 'whole' (value masked as one token).
+-- A partner holding both the full (disclose) and the masked scope of a field gets the full value.
+
+-- Step 1: Allow the mask mode
+ALTER TABLE scope_definitions DROP CONSTRAINT IF EXISTS chk_scope_definition_mode;
+ALTER TABLE scope_definitions ADD CONSTRAINT chk_scope_definition_mode
+    CHECK (mode IN ('disclose', 'verify', 'mask') AND (mode = 'disclose' OR tk_field IS NOT NULL));
+
+-- Step 2: Masking rule columns
+ALTER TABLE scope_definitions ADD COLUMN IF NOT EXISTS mask_visible SMALLINT NOT NULL DEFAULT 1;
+ALTER TABLE scope_definitions ADD COLUMN IF NOT EXISTS mask_tokens VARCHAR(10) NOT NULL DEFAULT 'each';
+ALTER TABLE scope_definitions DROP CONSTRAINT IF EXISTS chk_scope_definition_mask;
+ALTER TABLE scope_definitions ADD CONSTRAINT chk_scope_definition_mask
+    CHECK (mask_visible BETWEEN 0 AND 10 AND mask_tokens IN ('each', 'whole'));
+
+-- Step 3: Masked variants of name and alamat
+INSERT INTO scope_definitions (name, description, tk_field, sensitive, mode, mask_visible, mask_tokens) VALUES
+    ('name_masked', 'Nama tenaga kerja tersamar (mis. B*** S*****)', 'nama', false, 'mask', 1, 'each'),
+    ('alamat_masked', 'Alamat tenaga kerja tersamar', 'alamat', false, 'mask', 2, 'each')
+ON CONFLICT (name) DO NOTHING;
+
+-- Verification
+SELECT 'Migration V12 completed successfully!' as status;
+SELECT name, tk_field, mode, mask_visible, mask_tokens FROM scope_definitions WHERE mode = 'mask' ORDER BY name;
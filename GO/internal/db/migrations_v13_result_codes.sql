@@ -0,0 +1,17 @@
+-- Migration V13: Detailed check result codes
+-- Every check is audited with its precise outcome (NIK_NOT_FOUND, DOB_MISMATCH, MATCHED, DATA_INACTIVE).
+-- Partners only see the code in responses when they hold the result_detail scope.
+
+-- Step 1: Outcome column on audit logs (NULL for rows written before V13)
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS result_code VARCHAR(20);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_result_code ON audit_logs(result_code);
+
+-- Step 2: Permission-only scope exposing the code
+INSERT INTO scope_definitions (name, description, tk_field, sensitive) VALUES
+    ('result_detail', 'Tampilkan result_code (NIK_NOT_FOUND, DOB_MISMATCH, MATCHED, DATA_INACTIVE) pada hasil cek', NULL, false)
+ON CONFLICT (name) DO NOTHING;
+
+-- Verification
+SELECT 'Migration V13 completed successfully!' as status;
+SELECT column_name, data_type FROM information_schema.columns
+WHERE table_name = 'audit_logs' AND column_name = 'result_code';
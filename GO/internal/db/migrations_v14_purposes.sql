@@ -0,0 +1,63 @@
+-- Migration V14: Purpose of use and consent reference per check (UU PDP lawful basis)
+-- Every check must state a purpose code from the configurable purposes list; partners may only
+-- use the purposes listed in their contract (partner_purposes). The purpose and the optional
+-- consent reference are stored on every audit log row.
+
+-- Step 1: Configurable purposes list
+CREATE TABLE IF NOT EXISTS purposes (
+    code VARCHAR(50) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+INSERT INTO purposes (code, description) VALUES
+    ('employment_verification', 'Verifikasi status kerja / kepesertaan calon atau karyawan'),
+    ('credit_assessment', 'Penilaian kelayakan kredit atau pembiayaan'),
+    ('kyc_onboarding', 'Know Your Customer saat pembukaan akun'),
+    ('claims_processing', 'Pemrosesan klaim asuransi atau manfaat')
+ON CONFLICT (code) DO NOTHING;
+
+DROP TRIGGER IF EXISTS trg_update_purposes ON purposes;
+CREATE TRIGGER trg_update_purposes
+BEFORE UPDATE ON purposes
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+-- Step 2: Purposes allowed by each partner's contract
+CREATE TABLE IF NOT EXISTS partner_purposes (
+    partner_id UUID NOT NULL REFERENCES partners(id) ON DELETE CASCADE,
+    purpose_code VARCHAR(50) NOT NULL REFERENCES purposes(code),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (partner_id, purpose_code)
+);
+
+-- Step 2b: Backfill. Before V14 checks had no purpose restriction, so partners that already exist
+-- keep access to every active purpose until their contract is narrowed with
+-- PUT /admin/partners/:id/purposes. Only runs while no purpose has been assigned yet, so
+-- re-running the migration does not undo contracts set afterwards.
+INSERT INTO partner_purposes (partner_id, purpose_code)
+SELECT p.id, pu.code
+FROM partners p
+CROSS JOIN purposes pu
+WHERE pu.active
+  AND NOT EXISTS (SELECT 1 FROM partner_purposes)
+ON CONFLICT (partner_id, purpose_code) DO NOTHING;
+
+-- Step 3: Lawful basis on audit logs (NULL for rows written before V14)
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS purpose VARCHAR(50);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS consent_ref VARCHAR(100);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_purpose ON audit_logs(purpose);
+
+-- Step 4: Bulk check jobs carry one purpose for all of their rows
+ALTER TABLE check_jobs ADD COLUMN IF NOT EXISTS purpose VARCHAR(50);
+ALTER TABLE check_jobs ADD COLUMN IF NOT EXISTS consent_ref VARCHAR(100);
+
+-- Verification
+SELECT 'Migration V14 completed successfully!' as status;
+SELECT code, active FROM purposes ORDER BY code;
+-- Partners without any contracted purpose (all their checks are rejected until purposes are assigned;
+-- after the backfill these are only partners created later without purposes)
+SELECT id, company_name FROM partners p
+WHERE NOT EXISTS (SELECT 1 FROM partner_purposes pp WHERE pp.partner_id = p.id);
@@ -0,0 +1,42 @@
+-- Migration V15: Worker consent registry
+-- Scopes flagged sensitive in scope_definitions are only disclosed (or masked) for a NIK when the
+-- worker has given the partner a consent covering that scope, valid today and not revoked.
+-- Without such a consent the check falls back to the partner's non-sensitive scopes.
+
+-- Step 1: Consents recorded by admins or imported from offline forms
+CREATE TABLE IF NOT EXISTS worker_consents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    nik VARCHAR(16) NOT NULL,
+    partner_id UUID NOT NULL REFERENCES partners(id) ON DELETE CASCADE,
+    scopes TEXT[] NOT NULL, -- sensitive scope names covered by the consent
+    valid_from DATE NOT NULL DEFAULT CURRENT_DATE,
+    valid_until DATE, -- inclusive, NULL = until revoked
+    consent_ref VARCHAR(100), -- reference of the signed consent form
+    source VARCHAR(10) NOT NULL DEFAULT 'admin', -- admin or import
+    created_by UUID, -- admin ID
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_by UUID, -- admin ID
+    revoke_reason TEXT,
+    CONSTRAINT chk_worker_consent_validity CHECK (valid_until IS NULL OR valid_until >= valid_from),
+    CONSTRAINT chk_worker_consent_scopes CHECK (cardinality(scopes) > 0),
+    CONSTRAINT chk_worker_consent_source CHECK (source IN ('admin', 'import'))
+);
+
+-- Step 2: Lookup by NIK during checks, one consent form per reference (re-imports are idempotent)
+CREATE INDEX IF NOT EXISTS idx_worker_consents_partner_nik ON worker_consents(partner_id, nik);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_worker_consents_partner_ref
+    ON worker_consents(partner_id, consent_ref) WHERE consent_ref IS NOT NULL;
+
+-- Step 3: tanggal_lahir is supplied by the partner on every check, so it does not need consent
+UPDATE scope_definitions SET sensitive = false WHERE name = 'tanggal_lahir';
+
+-- Verification
+SELECT 'Migration V15 completed successfully!' as status;
+SELECT name, tk_field, mode FROM scope_definitions WHERE sensitive ORDER BY name;
+-- Partners holding sensitive scopes (these are withheld until consents are recorded)
+SELECT DISTINCT p.id, p.company_name, s.scope_name
+FROM partner_access_scopes s
+JOIN partners p ON p.id = s.partner_id
+JOIN scope_definitions d ON d.name = s.scope_name AND d.sensitive
+WHERE s.enabled;
@@ -0,0 +1,32 @@
+-- Migration V16: Indexes for the admin audit log search (/admin/audit-logs)
+-- Results are ordered by (created_at DESC, id DESC) and paginated with a keyset cursor on the
+-- same pair, so every filter has a composite index ending in (created_at, id). The single-column
+-- indexes they replace are dropped (the composite indexes serve the same lookups).
+-- CONCURRENTLY keeps audit_logs writable while the indexes are built on large tables; run this
+-- file outside a transaction (e.g. psql -f, not wrapped in BEGIN/COMMIT).
+
+-- Step 1: Unfiltered listing and date range
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_audit_logs_created_id
+    ON audit_logs (created_at DESC, id DESC);
+
+-- Step 2: Partner, NIK and outcome filters
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_audit_logs_partner_created
+    ON audit_logs (partner_id, created_at DESC, id DESC);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_audit_logs_nik_created
+    ON audit_logs (nik, created_at DESC, id DESC);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_audit_logs_result_created
+    ON audit_logs (result_code, created_at DESC, id DESC);
+
+-- Step 3: Scope filter (scopes_used @> '[{"scope_name": "...", "enabled": true}]')
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_audit_logs_scopes_used
+    ON audit_logs USING GIN (scopes_used jsonb_path_ops);
+
+-- Step 4: Drop the indexes covered by the composite ones above
+DROP INDEX CONCURRENTLY IF EXISTS idx_audit_partner_id;
+DROP INDEX CONCURRENTLY IF EXISTS idx_audit_nik;
+DROP INDEX CONCURRENTLY IF EXISTS idx_audit_created_at;
+DROP INDEX CONCURRENTLY IF EXISTS idx_audit_logs_result_code;
+
+-- Verification
+SELECT 'Migration V16 completed successfully!' as status;
+SELECT indexname, indexdef FROM pg_indexes WHERE tablename = 'audit_logs' ORDER BY indexname;
@@ -0,0 +1,42 @@
+-- Migration V17: Tamper-evident audit log (hash chain per partner + signed checkpoints)
+-- Every audit row written from V17 on carries chain_seq (1, 2, ... per partner), prev_hash (the
+-- row_hash of the previous row of the partner, 64 zeros for the first) and row_hash, the SHA-256
+-- of its canonical content including chain_seq and prev_hash. audit_chain_heads holds the last
+-- link of each chain; writers lock the partner's head row, so concurrent inserts stay ordered.
+-- audit_chain_checkpoints stores heads signed with the receipt key (Ed25519): rewriting rows
+-- and recomputing every hash after a checkpoint is detected because the signature cannot be
+-- forged. Rows written before V17 stay unchained (chain_seq NULL) and are reported as such.
+
+-- Step 1: Chain columns on audit_logs
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS chain_seq BIGINT;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash CHAR(64);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS row_hash CHAR(64);
+
+-- Step 2: One row per chain position (also the order used by the verifier)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_logs_partner_chain
+    ON audit_logs (partner_id, chain_seq) WHERE chain_seq IS NOT NULL;
+
+-- Step 3: Last link of each partner's chain
+CREATE TABLE IF NOT EXISTS audit_chain_heads (
+    partner_id UUID PRIMARY KEY REFERENCES partners(id) ON DELETE CASCADE,
+    last_seq BIGINT NOT NULL DEFAULT 0,
+    last_hash CHAR(64) NOT NULL DEFAULT repeat('0', 64),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Step 4: Signed checkpoints (compact JWS over partner_id, seq, row_hash)
+CREATE TABLE IF NOT EXISTS audit_chain_checkpoints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    partner_id UUID NOT NULL REFERENCES partners(id) ON DELETE CASCADE,
+    chain_seq BIGINT NOT NULL,
+    row_hash CHAR(64) NOT NULL,
+    key_id VARCHAR(100) NOT NULL,
+    signature TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT uq_audit_chain_checkpoint UNIQUE (partner_id, chain_seq)
+);
+
+-- Verification
+SELECT 'Migration V17 completed successfully!' as status;
+SELECT column_name, data_type FROM information_schema.columns
+WHERE table_name = 'audit_logs' AND column_name IN ('chain_seq', 'prev_hash', 'row_hash');
@@ -0,0 +1,109 @@
+-- Migration V18: Monthly range partitioning of audit_logs on created_at
+-- audit_logs becomes a partitioned table with one partition per UTC month (audit_logs_pYYYYMM)
+-- plus a default partition catching rows outside the created months. The application creates
+-- partitions ahead of time and applies the retention policy (detach, archive to .ndjson.gz,
+-- drop; see AuditPartitionService), so no partition needs to be created by hand after this.
+-- Existing rows are copied into the new partitions in a single transaction: run it in a
+-- maintenance window with the server stopped (the copy takes as long as the table is large).
+-- Changes to the table:
+--   * the primary key becomes (id, created_at) and created_at is NOT NULL (partition key)
+--   * the unique index on (partner_id, chain_seq) becomes a plain index (a partitioned unique
+--     index must contain created_at); chain positions stay unique through the chain head lock
+--     and duplicates are reported by verify-audit
+--   * the user_id foreign key is dropped: ON DELETE SET NULL would rewrite hash-chained rows
+
+BEGIN;
+
+-- Step 1: Keep the current table aside and free its index names
+ALTER TABLE audit_logs RENAME TO audit_logs_unpartitioned;
+ALTER TABLE audit_logs_unpartitioned RENAME CONSTRAINT audit_logs_pkey TO audit_logs_unpartitioned_pkey;
+DROP INDEX IF EXISTS idx_audit_logs_created_id;
+DROP INDEX IF EXISTS idx_audit_logs_partner_created;
+DROP INDEX IF EXISTS idx_audit_logs_nik_created;
+DROP INDEX IF EXISTS idx_audit_logs_result_created;
+DROP INDEX IF EXISTS idx_audit_logs_scopes_used;
+DROP INDEX IF EXISTS idx_audit_logs_partner_chain;
+DROP INDEX IF EXISTS idx_audit_logs_purpose;
+DROP INDEX IF EXISTS idx_audit_logs_result_code;
+DROP INDEX IF EXISTS idx_audit_user_id;
+DROP INDEX IF EXISTS idx_audit_partner_created;
+DROP INDEX IF EXISTS idx_audit_partner_id;
+DROP INDEX IF EXISTS idx_audit_nik;
+DROP INDEX IF EXISTS idx_audit_created_at;
+
+-- Step 2: Partitioned table
+CREATE TABLE audit_logs (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    partner_id UUID NOT NULL REFERENCES partners(id) ON DELETE CASCADE,
+    user_id UUID,
+    nik VARCHAR(20) NOT NULL,
+    scopes_used JSONB NOT NULL,
+    request_payload JSONB NOT NULL,
+    response_payload JSONB NOT NULL,
+    result_code VARCHAR(20),
+    purpose VARCHAR(50),
+    consent_ref VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    chain_seq BIGINT,
+    prev_hash CHAR(64),
+    row_hash CHAR(64),
+    CONSTRAINT audit_logs_pkey PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+-- Step 3: Indexes (created on every partition, including future ones)
+CREATE INDEX idx_audit_logs_created_id ON audit_logs (created_at DESC, id DESC);
+CREATE INDEX idx_audit_logs_partner_created ON audit_logs (partner_id, created_at DESC, id DESC);
+CREATE INDEX idx_audit_logs_nik_created ON audit_logs (nik, created_at DESC, id DESC);
+CREATE INDEX idx_audit_logs_result_created ON audit_logs (result_code, created_at DESC, id DESC);
+CREATE INDEX idx_audit_logs_scopes_used ON audit_logs USING GIN (scopes_used jsonb_path_ops);
+CREATE INDEX idx_audit_logs_purpose ON audit_logs (purpose);
+CREATE INDEX idx_audit_logs_partner_chain ON audit_logs (partner_id, chain_seq) WHERE chain_seq IS NOT NULL;
+
+-- Step 4: Default partition, then one partition per UTC month from the oldest row to 3 months ahead
+CREATE TABLE audit_logs_default PARTITION OF audit_logs DEFAULT;
+
+DO $$
+DECLARE
+    month DATE;
+    last_month DATE := date_trunc('month', (NOW() AT TIME ZONE 'UTC') + INTERVAL '3 months')::date;
+BEGIN
+    SELECT date_trunc('month', COALESCE(MIN(created_at), NOW()) AT TIME ZONE 'UTC')::date INTO month
+    FROM audit_logs_unpartitioned;
+
+    WHILE month <= last_month LOOP
+        EXECUTE format('CREATE TABLE IF NOT EXISTS %I PARTITION OF audit_logs FOR VALUES FROM (%L) TO (%L)',
+            'audit_logs_p' || to_char(month, 'YYYYMM'),
+            month::timestamp AT TIME ZONE 'UTC',
+            (month + INTERVAL '1 month')::timestamp AT TIME ZONE 'UTC');
+        month := (month + INTERVAL '1 month')::date;
+    END LOOP;
+END $$;
+
+-- Step 5: Copy the rows and drop the old table once every row is accounted for
+INSERT INTO audit_logs (id, partner_id, user_id, nik, scopes_used, request_payload, response_payload,
+                        result_code, purpose, consent_ref, created_at, chain_seq, prev_hash, row_hash)
+SELECT id, partner_id, user_id, nik, scopes_used, request_payload, response_payload,
+       result_code, purpose, consent_ref, COALESCE(created_at, NOW()), chain_seq, prev_hash, row_hash
+FROM audit_logs_unpartitioned;
+
+DO $$
+BEGIN
+    IF (SELECT COUNT(*) FROM audit_logs) <> (SELECT COUNT(*) FROM audit_logs_unpartitioned) THEN
+        RAISE EXCEPTION 'audit_logs row count mismatch after copy';
+    END IF;
+END $$;
+
+DROP TABLE audit_logs_unpartitioned;
+
+-- Step 6: Last purged chain position per partner (rows dropped by the retention policy)
+ALTER TABLE audit_chain_heads ADD COLUMN IF NOT EXISTS purged_seq BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE audit_chain_heads ADD COLUMN IF NOT EXISTS purged_hash CHAR(64) NOT NULL DEFAULT repeat('0', 64);
+
+COMMIT;
+
+-- Verification
+SELECT 'Migration V18 completed successfully!' as status;
+SELECT c.relname AS partition, pg_get_expr(c.relpartbound, c.oid) AS bounds
+FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+WHERE i.inhparent = 'audit_logs'::regclass
+ORDER BY c.relname;
@@ -0,0 +1,17 @@
+-- Migration V19: PII minimisation in stored audit logs
+-- With AUDIT_NIK_KEY set, audit_logs.nik holds a keyed token (nikh1: + HMAC-SHA256 hex, 70
+-- characters) instead of the plaintext NIK, and with AUDIT_PAYLOAD_POLICY=minimal the payloads
+-- keep only field names and non-personal values. Lookups by NIK match both the plaintext (rows
+-- written before) and the token, so existing rows are not rewritten (that would break the hash chain).
+-- partners.audit_debug_until flags a partner whose full payloads are stored until that time.
+
+-- Step 1: Room for the NIK token (metadata-only change, no table rewrite)
+ALTER TABLE audit_logs ALTER COLUMN nik TYPE VARCHAR(80);
+
+-- Step 2: Debug flag with an expiry (NULL = not debugging)
+ALTER TABLE partners ADD COLUMN IF NOT EXISTS audit_debug_until TIMESTAMP WITH TIME ZONE;
+
+-- Verification
+SELECT 'Migration V19 completed successfully!' as status;
+SELECT table_name, column_name, data_type, character_maximum_length FROM information_schema.columns
+WHERE (table_name = 'audit_logs' AND column_name = 'nik') OR (table_name = 'partners' AND column_name = 'audit_debug_until');
@@ -0,0 +1,33 @@
+-- Migration V20: Hashed partner API keys with lookup by key ID
+-- New keys have the form pks_live_<key id>_<secret> (16 + 64 hex characters). Only the key ID
+-- and the HMAC-SHA256 of the secret keyed with API_KEY_PEPPER are stored; authentication looks
+-- the partner up by api_key_id and compares the hash in constant time.
+-- Existing keys (UUIDs in plaintext in partners.api_key) are replaced by their SHA-256 in
+-- legacy_api_key_hash and keep working until the admin resets them, which issues a pks_live_ key
+-- and clears the legacy hash. A UUID has 122 random bits, so the unpeppered hash cannot be
+-- brute-forced. The plaintext column is dropped: keys can no longer be revealed, only reset.
+
+-- Step 1: Key ID, peppered secret hash and legacy key hash
+ALTER TABLE partners ADD COLUMN IF NOT EXISTS api_key_id VARCHAR(32) UNIQUE;
+ALTER TABLE partners ADD COLUMN IF NOT EXISTS api_key_hash CHAR(64);
+ALTER TABLE partners ADD COLUMN IF NOT EXISTS legacy_api_key_hash CHAR(64) UNIQUE;
+
+-- Step 2: Hash the existing plaintext keys, then drop them (skipped when V20 already ran)
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'partners' AND column_name = 'api_key') THEN
+        UPDATE partners SET legacy_api_key_hash = encode(sha256(convert_to(api_key, 'UTF8')), 'hex')
+        WHERE api_key IS NOT NULL AND api_key <> '' AND legacy_api_key_hash IS NULL;
+
+        DROP INDEX IF EXISTS idx_partners_api_key;
+        ALTER TABLE partners DROP COLUMN api_key;
+    END IF;
+END $$;
+
+-- Verification
+SELECT 'Migration V20 completed successfully!' as status;
+SELECT COUNT(*) FILTER (WHERE api_key_id IS NOT NULL) as hashed_keys,
+       COUNT(*) FILTER (WHERE legacy_api_key_hash IS NOT NULL) as legacy_keys,
+       COUNT(*) FILTER (WHERE api_key_id IS NULL AND legacy_api_key_hash IS NULL) as without_key
+FROM partners;
@@ -0,0 +1,74 @@
+-- Migration V21: Multiple named API keys per partner with overlapping rotation
+-- A partner can hold several keys (one per server or environment), each with a label, the admin
+-- who created it, an optional expiry, the time it was last used and a revoked state. Rotating a
+-- key issues a new one and leaves the old one valid for a grace period (API_KEY_ROTATION_GRACE_HOURS)
+-- by setting its expires_at, so servers can be switched one by one.
+-- The key of V20 (partners.api_key_id/api_key_hash) becomes a key labelled "default" and a legacy
+-- UUID key (legacy_api_key_hash) a key labelled "legacy" without key_id; both keep working.
+-- audit_logs.api_key_id and check_jobs.api_key_id record the key (partner_api_keys.id) used.
+
+-- Step 1: Partner API keys (key_hash = HMAC-SHA256 of the secret, or SHA-256 of a legacy key)
+CREATE TABLE IF NOT EXISTS partner_api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    partner_id UUID NOT NULL REFERENCES partners(id) ON DELETE CASCADE,
+    label VARCHAR(100) NOT NULL,
+    key_id VARCHAR(32) UNIQUE, -- key ID of a pks_live_ key, NULL = legacy UUID key
+    key_hash CHAR(64) NOT NULL,
+    created_by UUID, -- admin ID
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE, -- NULL = until revoked
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_by UUID, -- admin ID
+    rotated_to UUID REFERENCES partner_api_keys(id) ON DELETE SET NULL -- key issued by rotating this one
+);
+
+-- Step 2: Listing per partner, lookup of legacy keys by hash
+CREATE INDEX IF NOT EXISTS idx_partner_api_keys_partner ON partner_api_keys(partner_id, created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_partner_api_keys_legacy_hash
+    ON partner_api_keys(key_hash) WHERE key_id IS NULL;
+
+-- Step 3: Move the V20 keys, then drop the partner columns (skipped when V21 already ran)
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'partners' AND column_name = 'api_key_id') THEN
+        INSERT INTO partner_api_keys (partner_id, label, key_id, key_hash)
+        SELECT id, 'default', api_key_id, api_key_hash FROM partners
+        WHERE api_key_id IS NOT NULL AND api_key_hash IS NOT NULL;
+
+        INSERT INTO partner_api_keys (partner_id, label, key_id, key_hash)
+        SELECT id, 'legacy', NULL, legacy_api_key_hash FROM partners
+        WHERE legacy_api_key_hash IS NOT NULL;
+
+        ALTER TABLE partners DROP COLUMN api_key_id;
+        ALTER TABLE partners DROP COLUMN api_key_hash;
+        ALTER TABLE partners DROP COLUMN legacy_api_key_hash;
+    END IF;
+END $$;
+
+-- Step 4: Key used by each check (NULL for rows written before V21)
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS api_key_id UUID;
+ALTER TABLE check_jobs ADD COLUMN IF NOT EXISTS api_key_id UUID;
+
+-- Step 5: Partitions detached but not yet archived (V18 retention) are read with the same columns
+DO $$
+DECLARE
+    part RECORD;
+BEGIN
+    FOR part IN
+        SELECT c.relname FROM pg_class c
+        JOIN pg_namespace n ON n.oid = c.relnamespace
+        WHERE c.relkind = 'r' AND n.nspname = current_schema() AND c.relname ~ '^audit_logs_p[0-9]{6}$'
+          AND NOT EXISTS (SELECT 1 FROM pg_inherits i WHERE i.inhrelid = c.oid)
+    LOOP
+        EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS api_key_id UUID', part.relname);
+    END LOOP;
+END $$;
+
+-- Verification
+SELECT 'Migration V21 completed successfully!' as status;
+SELECT label, COUNT(*) as keys FROM partner_api_keys GROUP BY label ORDER BY label;
+-- Partners without any key (need POST /admin/partners/:id/api-keys or reset-api-key)
+SELECT p.id, p.company_name FROM partners p
+WHERE NOT EXISTS (SELECT 1 FROM partner_api_keys k WHERE k.partner_id = p.id);
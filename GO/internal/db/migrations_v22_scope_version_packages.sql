@@ -0,0 +1,47 @@
+-- Migration V22: Scope versions include the scope package
+-- Effective scopes are the package items overlaid by partner_access_scopes, so a version now also
+-- stores the assigned scope_package_id and the package items resolved at that moment. Assigning,
+-- clearing or editing a package records a 'package' version for every partner it affects, and a
+-- rollback restores the package assignment as well.
+-- package_scopes is NULL on versions written before V22: their package was not recorded and a
+-- rollback to them leaves the current assignment unchanged.
+
+-- Step 1: Package columns (no foreign key, the package may be deleted later)
+ALTER TABLE partner_scope_versions ADD COLUMN IF NOT EXISTS scope_package_id UUID;
+ALTER TABLE partner_scope_versions ADD COLUMN IF NOT EXISTS package_scopes JSONB; -- ["scope_name", ...] ordered
+
+-- Step 2: New change type for package assignments and package edits
+ALTER TABLE partner_scope_versions DROP CONSTRAINT IF EXISTS chk_scope_version_change_type;
+ALTER TABLE partner_scope_versions ADD CONSTRAINT chk_scope_version_change_type
+    CHECK (change_type IN ('baseline', 'create', 'update', 'rollback', 'package'));
+
+-- Step 3: Baseline snapshot including the current package for partners whose latest version has none
+INSERT INTO partner_scope_versions (partner_id, version, scopes, scope_package_id, package_scopes, change_type)
+SELECT p.id,
+       COALESCE((SELECT MAX(v.version) FROM partner_scope_versions v WHERE v.partner_id = p.id), 0) + 1,
+       COALESCE((
+           SELECT jsonb_agg(jsonb_build_object(
+                      'scope_name', s.scope_name,
+                      'enabled', s.enabled,
+                      'valid_from', s.valid_from,
+                      'valid_until', s.valid_until
+                  ) ORDER BY s.scope_name)
+           FROM partner_access_scopes s
+           WHERE s.partner_id = p.id
+       ), '[]'::jsonb),
+       p.scope_package_id,
+       COALESCE((
+           SELECT jsonb_agg(i.scope_name ORDER BY i.scope_name)
+           FROM scope_package_items i
+           WHERE i.package_id = p.scope_package_id
+       ), '[]'::jsonb),
+       'baseline'
+FROM partners p
+WHERE NOT EXISTS (
+    SELECT 1 FROM partner_scope_versions v
+    WHERE v.partner_id = p.id AND v.package_scopes IS NOT NULL
+);
+
+-- Verification
+SELECT 'Migration V22 completed successfully!' as status;
+SELECT COUNT(*) AS versions_with_package FROM partner_scope_versions WHERE package_scopes IS NOT NULL;
@@ -0,0 +1,53 @@
+-- Migration V5: Asynchronous bulk check jobs
+-- Partners upload a CSV of NIK + tanggal_lahir, the server processes it in the background
+-- and keeps all progress in Postgres so jobs survive a restart.
+
+-- Step 1: Job header (one row per uploaded file)
+CREATE TABLE IF NOT EXISTS check_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    partner_id UUID NOT NULL REFERENCES partners(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued', -- queued, running, completed, failed
+    file_name VARCHAR(255),
+    total_rows INT NOT NULL DEFAULT 0,
+    processed_rows INT NOT NULL DEFAULT 0,
+    found_rows INT NOT NULL DEFAULT 0,
+    not_found_rows INT NOT NULL DEFAULT 0,
+    invalid_rows INT NOT NULL DEFAULT 0,
+    result_columns TEXT[] NOT NULL DEFAULT '{}', -- union of result fields, used as CSV header
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    locked_until TIMESTAMP WITH TIME ZONE, -- worker lease, expired lease = job can be resumed
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT chk_check_job_status CHECK (status IN ('queued', 'running', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_check_jobs_partner_created ON check_jobs(partner_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_check_jobs_claimable ON check_jobs(created_at) WHERE status IN ('queued', 'running');
+
+-- Step 2: Job rows (input + result per CSV row)
+CREATE TABLE IF NOT EXISTS check_job_items (
+    job_id UUID NOT NULL REFERENCES check_jobs(id) ON DELETE CASCADE,
+    row_number INT NOT NULL,
+    nik TEXT NOT NULL,
+    tanggal_lahir TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, found, not_found, invalid
+    error TEXT,
+    result JSONB,
+    processed_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (job_id, row_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_check_job_items_pending ON check_job_items(job_id, row_number) WHERE status = 'pending';
+
+-- Step 3: Keep updated_at fresh
+DROP TRIGGER IF EXISTS trg_update_check_jobs ON check_jobs;
+CREATE TRIGGER trg_update_check_jobs
+BEFORE UPDATE ON check_jobs
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+-- Verification
+SELECT 'Migration V5 completed successfully!' as status;
@@ -0,0 +1,47 @@
+-- Migration V6: Status history timeline for TK participation (kepesertaan)
+-- Every change of tk_data.status_kepesertaan is recorded by a trigger, so changes made
+-- through the admin API, imports or manual SQL all end up in the timeline.
+
+-- Step 1: History table (no FK to tk_data, history is kept when a worker is deleted)
+CREATE TABLE IF NOT EXISTS tk_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    nik VARCHAR(20) NOT NULL,
+    old_status tk_status_enum,
+    new_status tk_status_enum NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tk_status_history_nik_changed ON tk_status_history(nik, changed_at DESC, id DESC);
+
+-- Step 2: Trigger function
+CREATE OR REPLACE FUNCTION log_tk_status_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.status_kepesertaan IS DISTINCT FROM OLD.status_kepesertaan THEN
+        INSERT INTO tk_status_history (nik, old_status, new_status, changed_at)
+        VALUES (
+            NEW.nik,
+            CASE WHEN TG_OP = 'UPDATE' THEN OLD.status_kepesertaan END,
+            NEW.status_kepesertaan,
+            NOW()
+        );
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tk_status_history ON tk_data;
+CREATE TRIGGER trg_tk_status_history
+AFTER INSERT OR UPDATE OF status_kepesertaan ON tk_data
+FOR EACH ROW
+EXECUTE FUNCTION log_tk_status_change();
+
+-- Step 3: Backfill current status of existing workers (known since their last update)
+INSERT INTO tk_status_history (nik, old_status, new_status, changed_at)
+SELECT t.nik, NULL, t.status_kepesertaan, COALESCE(t.updated_at, NOW())
+FROM tk_data t
+WHERE NOT EXISTS (SELECT 1 FROM tk_status_history h WHERE h.nik = t.nik);
+
+-- Verification
+SELECT 'Migration V6 completed successfully!' as status;
+SELECT COUNT(*) AS history_rows FROM tk_status_history;
@@ -0,0 +1,38 @@
+-- Migration V7: Dynamic scope registry
+-- Scope names, the tk_data column each one exposes and whether it is sensitive are stored
+-- in the database, so a new field can be exposed to partners without a code change.
+
+-- Step 1: Registry table
+CREATE TABLE IF NOT EXISTS scope_definitions (
+    name VARCHAR(50) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    tk_field VARCHAR(100), -- tk_data column exposed by this scope, NULL for permission-only scopes
+    sensitive BOOLEAN NOT NULL DEFAULT false,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Step 2: Seed the scopes that were previously hard-coded
+INSERT INTO scope_definitions (name, description, tk_field, sensitive) VALUES
+    ('name', 'Nama tenaga kerja', 'nama', false),
+    ('tanggal_lahir', 'Tanggal lahir tenaga kerja', 'tanggal_lahir', true),
+    ('status_bpjs', 'Status kepesertaan saat ini', 'status_kepesertaan', false),
+    ('alamat', 'Alamat tenaga kerja', 'alamat', true),
+    ('status_history', 'Izin cek status kepesertaan pada tanggal tertentu (as_of)', NULL, false)
+ON CONFLICT (name) DO NOTHING;
+
+-- Step 3: Keep updated_at fresh
+DROP TRIGGER IF EXISTS trg_update_scope_definitions ON scope_definitions;
+CREATE TRIGGER trg_update_scope_definitions
+BEFORE UPDATE ON scope_definitions
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+-- Verification
+SELECT 'Migration V7 completed successfully!' as status;
+SELECT name, tk_field, sensitive, active FROM scope_definitions ORDER BY name;
+-- Scope names assigned to partners that are not in the registry (typos saved before V7)
+SELECT DISTINCT scope_name AS unregistered_scope
+FROM partner_access_scopes
+WHERE scope_name NOT IN (SELECT name FROM scope_definitions);
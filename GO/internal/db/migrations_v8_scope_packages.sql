@@ -0,0 +1,56 @@
+-- Migration V8: Scope packages (templates) assignable to partners
+-- A partner's effective scopes are the scopes of its package, overlaid by its own rows in
+-- partner_access_scopes (a partner row always wins, so it can add or disable a scope).
+
+-- Step 1: Packages and their scopes
+CREATE TABLE IF NOT EXISTS scope_packages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS scope_package_items (
+    package_id UUID NOT NULL REFERENCES scope_packages(id) ON DELETE CASCADE,
+    scope_name VARCHAR(50) NOT NULL,
+    PRIMARY KEY (package_id, scope_name)
+);
+
+-- Step 2: Package assignment on partners
+ALTER TABLE partners ADD COLUMN IF NOT EXISTS scope_package_id UUID REFERENCES scope_packages(id);
+
+CREATE INDEX IF NOT EXISTS idx_partners_scope_package_id ON partners(scope_package_id);
+
+-- Step 3: Keep updated_at fresh
+DROP TRIGGER IF EXISTS trg_update_scope_packages ON scope_packages;
+CREATE TRIGGER trg_update_scope_packages
+BEFORE UPDATE ON scope_packages
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+-- Step 4: Starter packages
+INSERT INTO scope_packages (name, description) VALUES
+    ('Basic verification', 'Nama dan status kepesertaan'),
+    ('Full profile', 'Nama, tanggal lahir, status kepesertaan dan alamat')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO scope_package_items (package_id, scope_name)
+SELECT p.id, s.scope_name
+FROM scope_packages p
+JOIN (VALUES
+    ('Basic verification', 'name'),
+    ('Basic verification', 'status_bpjs'),
+    ('Full profile', 'name'),
+    ('Full profile', 'tanggal_lahir'),
+    ('Full profile', 'status_bpjs'),
+    ('Full profile', 'alamat')
+) AS s(package_name, scope_name) ON s.package_name = p.name
+ON CONFLICT DO NOTHING;
+
+-- Verification
+SELECT 'Migration V8 completed successfully!' as status;
+SELECT p.name, array_agg(i.scope_name ORDER BY i.scope_name) AS scopes
+FROM scope_packages p
+LEFT JOIN scope_package_items i ON i.package_id = p.id
+GROUP BY p.name;
@@ -0,0 +1,18 @@
+-- Migration V9: Time-bound scope grants
+-- valid_from / valid_until are inclusive dates; NULL means no start / no end.
+-- Only grants active on CURRENT_DATE are loaded for partner requests.
+
+-- Step 1: Validity columns
+ALTER TABLE partner_access_scopes ADD COLUMN IF NOT EXISTS valid_from DATE;
+ALTER TABLE partner_access_scopes ADD COLUMN IF NOT EXISTS valid_until DATE;
+
+-- Step 2: Guard against inverted periods
+ALTER TABLE partner_access_scopes DROP CONSTRAINT IF EXISTS chk_partner_scope_validity;
+ALTER TABLE partner_access_scopes ADD CONSTRAINT chk_partner_scope_validity
+    CHECK (valid_from IS NULL OR valid_until IS NULL OR valid_until >= valid_from);
+
+-- Verification
+SELECT 'Migration V9 completed successfully!' as status;
+SELECT column_name, data_type
+FROM information_schema.columns
+WHERE table_name = 'partner_access_scopes' AND column_name IN ('valid_from', 'valid_until');
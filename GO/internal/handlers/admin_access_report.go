@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/service"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// AdminAccessReportHandler handles data-subject access reports for admins
+type AdminAccessReportHandler struct {
+	AccessReportService *service.AccessReportService
+}
+
+// NewAdminAccessReportHandler creates a new admin access report handler
+func NewAdminAccessReportHandler(accessReportService *service.AccessReportService) *AdminAccessReportHandler {
+	return &AdminAccessReportHandler{
+		AccessReportService: accessReportService,
+	}
+}
+
+// Get produces the access report of a NIK (?format=json|html, html is print-ready for PDF)
+func (h *AdminAccessReportHandler) Get(c *fiber.Ctx) error {
+	format := c.Query("format", models.AccessReportFormatJSON)
+	if format != models.AccessReportFormatJSON && format != models.AccessReportFormatHTML {
+		return utils.JSONError(c, fiber.StatusBadRequest, "format must be json or html")
+	}
+
+	report, err := h.AccessReportService.Generate(c.Context(), c.Params("nik"))
+	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+		}
+		log.Printf("AdminAccessReportHandler.Get - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to generate access report")
+	}
+
+	if format == models.AccessReportFormatJSON {
+		return utils.JSONSuccess(c, report)
+	}
+
+	var buf bytes.Buffer
+	if err := h.AccessReportService.WriteHTML(&buf, report); err != nil {
+		log.Printf("AdminAccessReportHandler.Get - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to render access report")
+	}
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="access-report-%s.html"`, report.NIK))
+	c.Type("html", "utf-8")
+	return c.Send(buf.Bytes())
+}
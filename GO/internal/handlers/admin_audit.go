@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/internal/service"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// AdminAuditHandler handles the admin audit log search
+type AdminAuditHandler struct {
+	AuditService *service.AuditService
+	AuditWriter  *service.AuditWriter
+	AuditChain   *service.AuditChainService
+}
+
+// NewAdminAuditHandler creates a new admin audit handler
+func NewAdminAuditHandler(auditService *service.AuditService, auditWriter *service.AuditWriter, auditChain *service.AuditChainService) *AdminAuditHandler {
+	return &AdminAuditHandler{
+		AuditService: auditService,
+		AuditWriter:  auditWriter,
+		AuditChain:   auditChain,
+	}
+}
+
+// List searches audit logs (?partner_id, ?nik, ?from, ?to, ?outcome, ?scope, ?limit, ?cursor)
+func (h *AdminAuditHandler) List(c *fiber.Ctx) error {
+	var req models.AuditLogSearchRequest
+	if err := c.QueryParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid query parameters")
+	}
+
+	page, err := h.AuditService.Search(c.Context(), &req)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+		}
+		log.Printf("AdminAuditHandler.List - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to retrieve audit logs")
+	}
+
+	return utils.JSONSuccess(c, page)
+}
+
+// SetPartnerDebug stores a partner's full audit payloads for a number of hours ({"hours": 0} stops)
+func (h *AdminAuditHandler) SetPartnerDebug(c *fiber.Ctx) error {
+	var req models.UpdateAuditDebugRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	status, err := h.AuditService.SetPartnerDebug(c.Context(), c.Params("id"), &req)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+		case errors.Is(err, repository.ErrPartnerNotFound):
+			return utils.JSONError(c, fiber.StatusNotFound, err.Error())
+		}
+		log.Printf("AdminAuditHandler.SetPartnerDebug - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to update partner audit debugging")
+	}
+
+	return utils.JSONSuccessWithMessage(c, "Partner audit debugging updated successfully", status)
+}
+
+// Export downloads every audit log matching the search filters as a zip holding the CSV or NDJSON
+// file and its SHA-256 checksum file (?format=csv|ndjson, ?redact=none|hash|redact, filters as List)
+func (h *AdminAuditHandler) Export(c *fiber.Ctx) error {
+	var req models.AuditExportRequest
+	if err := c.QueryParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid query parameters")
+	}
+
+	export, err := h.AuditService.NewExport(&req)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+		}
+		log.Printf("AdminAuditHandler.Export - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to export audit logs")
+	}
+
+	// Errors found before the first byte (database down, bad filter) still get a JSON response
+	if err := h.AuditService.CheckExport(c.Context(), export); err != nil {
+		log.Printf("AdminAuditHandler.Export - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to export audit logs")
+	}
+
+	fileName := export.FileName()
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.zip"`, fileName))
+
+	// Stream rows straight from the database into the archive; the checksum entry is
+	// written last, once the data file is complete. A failure mid-stream closes the pipe with
+	// the error, so the response ends without its final chunk and the connection is dropped
+	// instead of delivering a truncated archive that looks complete.
+	reader, writer := io.Pipe()
+	go func() {
+		archive := zip.NewWriter(writer)
+		if err := writeAuditExportArchive(h.AuditService, export, archive); err != nil {
+			log.Printf("AdminAuditHandler.Export - %s: %v", fileName, err)
+			writer.CloseWithError(err)
+			return
+		}
+		if err := archive.Close(); err != nil {
+			log.Printf("AdminAuditHandler.Export - %s: %v", fileName, err)
+			writer.CloseWithError(err)
+			return
+		}
+		writer.Close()
+	}()
+	c.Context().SetBodyStream(reader, -1)
+
+	return nil
+}
+
+// writeAuditExportArchive writes the export file and its sha256sum-compatible checksum file into archive
+func writeAuditExportArchive(auditService *service.AuditService, export *service.AuditExport, archive *zip.Writer) error {
+	fileName := export.FileName()
+
+	data, err := archive.CreateHeader(&zip.FileHeader{Name: fileName, Method: zip.Deflate, Modified: export.CreatedAt})
+	if err != nil {
+		return err
+	}
+	result, err := auditService.WriteExport(context.Background(), export, data)
+	if err != nil {
+		return err
+	}
+
+	checksum, err := archive.CreateHeader(&zip.FileHeader{Name: fileName + ".sha256", Method: zip.Deflate, Modified: export.CreatedAt})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(checksum, "%s  %s\n", result.SHA256, fileName)
+	return err
+}
+
+// WriterMetrics returns the audit writer queue backlog and counters
+func (h *AdminAuditHandler) WriterMetrics(c *fiber.Ctx) error {
+	return utils.JSONSuccess(c, h.AuditWriter.Metrics())
+}
+
+// Verify walks part of a partner's audit hash chain (?partner_id&from_seq&to_seq, bounded by
+// MaxAuditVerifyRange) and reports gaps, broken links, modified rows and checkpoint mismatches
+func (h *AdminAuditHandler) Verify(c *fiber.Ctx) error {
+	fromSeq := int64(c.QueryInt("from_seq", 0))
+	toSeq := int64(c.QueryInt("to_seq", 0))
+	report, err := h.AuditChain.VerifyRange(c.Context(), c.Query("partner_id"), fromSeq, toSeq)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+		}
+		log.Printf("AdminAuditHandler.Verify - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to verify audit logs")
+	}
+
+	message := "Audit chain is intact"
+	if !report.Valid {
+		message = "Audit chain integrity problems found"
+	}
+
+	return utils.JSONSuccessWithMessage(c, message, report)
+}
+
+// Get retrieves a single audit log
+func (h *AdminAuditHandler) Get(c *fiber.Ctx) error {
+	entry, err := h.AuditService.Get(c.Context(), c.Params("id"))
+	if err != nil {
+		log.Printf("AdminAuditHandler.Get - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to retrieve audit log")
+	}
+	if entry == nil {
+		return utils.JSONError(c, fiber.StatusNotFound, "audit log not found")
+	}
+
+	return utils.JSONSuccess(c, entry)
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/internal/service"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// AdminConsentHandler handles admin management of worker consents
+type AdminConsentHandler struct {
+	ConsentService *service.ConsentService
+}
+
+// NewAdminConsentHandler creates a new admin consent handler
+func NewAdminConsentHandler(consentService *service.ConsentService) *AdminConsentHandler {
+	return &AdminConsentHandler{
+		ConsentService: consentService,
+	}
+}
+
+// List retrieves consents (?nik, ?partner_id, ?limit, ?offset)
+func (h *AdminConsentHandler) List(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	offset := c.QueryInt("offset", 0)
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	filter := models.ConsentListFilter{NIK: c.Query("nik"), PartnerID: c.Query("partner_id")}
+
+	consents, err := h.ConsentService.List(c.Context(), filter, limit, offset)
+	if err != nil {
+		return consentError(c, "failed to retrieve consents", err)
+	}
+
+	return utils.JSONSuccess(c, consents)
+}
+
+// Get retrieves a single consent
+func (h *AdminConsentHandler) Get(c *fiber.Ctx) error {
+	consent, err := h.ConsentService.Get(c.Context(), c.Params("id"))
+	if err != nil {
+		return consentError(c, "failed to retrieve consent", err)
+	}
+	if consent == nil {
+		return utils.JSONError(c, fiber.StatusNotFound, "consent not found")
+	}
+
+	return utils.JSONSuccess(c, consent)
+}
+
+// Create records a worker consent
+func (h *AdminConsentHandler) Create(c *fiber.Ctx) error {
+	var req models.CreateConsentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	consent, err := h.ConsentService.Create(c.Context(), &req, adminIDFromContext(c))
+	if err != nil {
+		return consentError(c, "failed to create consent", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Consent recorded successfully",
+		Data:    consent,
+	})
+}
+
+// Revoke revokes a worker consent
+func (h *AdminConsentHandler) Revoke(c *fiber.Ctx) error {
+	var req models.RevokeConsentRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+		}
+	}
+
+	consent, err := h.ConsentService.Revoke(c.Context(), c.Params("id"), adminIDFromContext(c), req.Reason)
+	if err != nil {
+		return consentError(c, "failed to revoke consent", err)
+	}
+
+	return utils.JSONSuccessWithMessage(c, "Consent revoked successfully", consent)
+}
+
+// Import imports consents collected offline for a partner from a CSV or XLSX file
+// (multipart field "file", ?partner_id). With ?dry_run=true nothing is committed.
+func (h *AdminConsentHandler) Import(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "file is required (multipart field 'file')")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "failed to read uploaded file")
+	}
+	defer file.Close()
+
+	dryRun := c.QueryBool("dry_run", false)
+
+	report, err := h.ConsentService.Import(c.Context(), c.Query("partner_id"), fileHeader.Filename, file, dryRun, adminIDFromContext(c))
+	if err != nil {
+		return consentError(c, "failed to import consents", err)
+	}
+
+	message := "Import completed"
+	if dryRun {
+		message = "Dry run completed, no changes were committed"
+	}
+
+	return utils.JSONSuccessWithMessage(c, message, report)
+}
+
+// consentError maps consent errors to HTTP responses (400 validation, 404, 409, 500)
+func consentError(c *fiber.Ctx, message string, err error) error {
+	var validationErr *utils.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+	case errors.Is(err, repository.ErrConsentNotFound), errors.Is(err, repository.ErrPartnerNotFound):
+		return utils.JSONError(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, repository.ErrConsentAlreadyExists), errors.Is(err, repository.ErrConsentAlreadyRevoked):
+		return utils.JSONError(c, fiber.StatusConflict, err.Error())
+	}
+	log.Printf("AdminConsentHandler - %s: %v", message, err)
+	return utils.JSONError(c, fiber.StatusInternalServerError, message)
+}
@@ -2,11 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"strconv"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
 	"github.com/username/go-gin-backend/internal/service"
 	"github.com/username/go-gin-backend/pkg/utils"
 )
@@ -27,6 +31,15 @@ func mapPartnersForResponse(partners []*models.Partner) []*models.Partner {
 	return partners
 }
 
+// adminIDFromContext returns the authenticated admin ID set by AdminAuth (nil when absent)
+func adminIDFromContext(c *fiber.Ctx) *string {
+	adminID, _ := c.Locals("adminID").(string)
+	if adminID == "" {
+		return nil
+	}
+	return &adminID
+}
+
 // AdminPartnerHandler handles admin partner management
 type AdminPartnerHandler struct {
 	PartnerService *service.PartnerService
@@ -90,9 +103,24 @@ func (h *AdminPartnerHandler) Create(c *fiber.Ctx) error {
 	req.PICEmail = strings.TrimSpace(req.PICEmail)
 
 	fmt.Printf("CreatePartner - Calling service with: %+v\n", req)
-	partner, err := h.PartnerService.CreatePartner(c.Context(), &req)
+	partner, err := h.PartnerService.CreatePartner(c.Context(), &req, adminIDFromContext(c))
+	if errors.Is(err, service.ErrPartnerAPIKeyNotIssued) {
+		// The partner exists: return it so the admin can add its key instead of creating it again
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+			"data": fiber.Map{
+				"partner":    mapPartnerForResponse(partner.Partner),
+				"company_id": partner.Partner.CompanyID,
+			},
+		})
+	}
 	if err != nil {
 		fmt.Printf("CreatePartner - Service error: %v\n", err)
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+		}
 		return utils.JSONErrorWithDetail(c, fiber.StatusInternalServerError, "failed to create partner", err.Error())
 	}
 
@@ -227,42 +255,88 @@ func (h *AdminPartnerHandler) UpdateScopes(c *fiber.Ctx) error {
 		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
 	}
 
-	if err := h.PartnerService.UpdatePartnerScopes(c.Context(), id, &req); err != nil {
+	if err := h.PartnerService.UpdatePartnerScopes(c.Context(), id, &req, adminIDFromContext(c)); err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+		}
+		if errors.Is(err, repository.ErrPartnerNotFound) {
+			return utils.JSONError(c, fiber.StatusNotFound, err.Error())
+		}
 		return utils.JSONErrorWithDetail(c, fiber.StatusInternalServerError, "failed to update scopes", err.Error())
 	}
 
 	return utils.JSONSuccessWithMessage(c, "Scopes updated successfully", nil)
 }
 
-// RevealAPIKey reveals the current API key (returns plaintext once without resetting)
-func (h *AdminPartnerHandler) RevealAPIKey(c *fiber.Ctx) error {
+// ScopeVersions retrieves the scope change history of a partner (newest first)
+func (h *AdminPartnerHandler) ScopeVersions(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if id == "" {
 		return utils.JSONError(c, fiber.StatusBadRequest, "partner ID is required")
 	}
 
-	partner, err := h.PartnerService.GetPartner(c.Context(), id)
+	versions, err := h.PartnerService.ListScopeVersions(c.Context(), id)
 	if err != nil {
-		return utils.JSONError(c, fiber.StatusNotFound, "partner not found")
+		return scopeVersionError(c, "failed to retrieve scope versions", err)
 	}
 
-	if partner.APIKey == nil || *partner.APIKey == "" {
-		return utils.JSONError(c, fiber.StatusNotFound, "API key not found for this partner")
+	return utils.JSONSuccess(c, versions)
+}
+
+// DiffScopeVersions compares two scope versions (?from=1&to=3)
+func (h *AdminPartnerHandler) DiffScopeVersions(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "partner ID is required")
 	}
 
-	// Map status for UI display
-	mappedPartner := mapPartnerForResponse(partner)
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil || from < 1 {
+		return utils.JSONError(c, fiber.StatusBadRequest, "from must be a positive version number")
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil || to < 1 {
+		return utils.JSONError(c, fiber.StatusBadRequest, "to must be a positive version number")
+	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"message": "API key revealed successfully",
-		"data": fiber.Map{
-			"partner":    mappedPartner,
-			"company_id": partner.CompanyID,
-			"api_key":    *partner.APIKey, // Return current API key
-			"info":       fmt.Sprintf("Use API key '%s' in X-API-KEY header for API requests", *partner.APIKey),
-			"note":       "Copy this API key now; it will not be shown again. This is your current active API key.",
-		},
+	diff, err := h.PartnerService.DiffScopeVersions(c.Context(), id, from, to)
+	if err != nil {
+		return scopeVersionError(c, "failed to diff scope versions", err)
+	}
+
+	return utils.JSONSuccess(c, diff)
+}
+
+// RollbackScopes restores the scopes of an earlier version as a new version
+func (h *AdminPartnerHandler) RollbackScopes(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "partner ID is required")
+	}
+
+	var req models.RollbackScopesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+	if req.Version < 1 {
+		return utils.JSONError(c, fiber.StatusBadRequest, "version must be a positive version number")
+	}
+
+	newVersion, err := h.PartnerService.RollbackScopes(c.Context(), id, req.Version, adminIDFromContext(c))
+	if err != nil {
+		return scopeVersionError(c, "failed to roll back scopes", err)
+	}
+
+	scopes, err := h.PartnerService.GetPartnerScopes(c.Context(), id)
+	if err != nil {
+		return scopeVersionError(c, "failed to retrieve scopes", err)
+	}
+
+	return utils.JSONSuccessWithMessage(c, fmt.Sprintf("Scopes rolled back to version %d", req.Version), fiber.Map{
+		"version":     newVersion, // 0 when the restored scopes equal the current ones
+		"rollback_of": req.Version,
+		"scopes":      scopes,
 	})
 }
 
@@ -273,7 +347,7 @@ func (h *AdminPartnerHandler) ResetAPIKey(c *fiber.Ctx) error {
 		return utils.JSONError(c, fiber.StatusBadRequest, "partner ID is required")
 	}
 
-	resp, err := h.PartnerService.ResetAPIKey(c.Context(), id)
+	resp, err := h.PartnerService.ResetAPIKey(c.Context(), id, adminIDFromContext(c))
 	if err != nil {
 		return utils.JSONErrorWithDetail(c, fiber.StatusInternalServerError, "failed to reset API key", err.Error())
 	}
@@ -293,3 +367,18 @@ func (h *AdminPartnerHandler) ResetAPIKey(c *fiber.Ctx) error {
 		},
 	})
 }
+
+// scopeVersionError maps scope version errors to HTTP responses (400 validation, 404, 500)
+func scopeVersionError(c *fiber.Ctx, message string, err error) error {
+	var validationErr *utils.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+	case errors.Is(err, repository.ErrScopeVersionNotFound), errors.Is(err, repository.ErrPartnerNotFound):
+		return utils.JSONError(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, repository.ErrScopePackageNotFound): // package of the version deleted since
+		return utils.JSONError(c, fiber.StatusConflict, err.Error())
+	}
+	log.Printf("AdminPartnerHandler - %s: %v", message, err)
+	return utils.JSONError(c, fiber.StatusInternalServerError, message)
+}
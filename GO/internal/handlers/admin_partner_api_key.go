@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/internal/service"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// AdminPartnerAPIKeyHandler handles admin management of a partner's API keys
+type AdminPartnerAPIKeyHandler struct {
+	APIKeyService *service.PartnerAPIKeyService
+}
+
+// NewAdminPartnerAPIKeyHandler creates a new admin partner API key handler
+func NewAdminPartnerAPIKeyHandler(apiKeyService *service.PartnerAPIKeyService) *AdminPartnerAPIKeyHandler {
+	return &AdminPartnerAPIKeyHandler{
+		APIKeyService: apiKeyService,
+	}
+}
+
+// List retrieves all API keys of a partner (secrets are never returned)
+func (h *AdminPartnerAPIKeyHandler) List(c *fiber.Ctx) error {
+	keys, err := h.APIKeyService.List(c.Context(), c.Params("id"))
+	if err != nil {
+		return apiKeyError(c, "failed to retrieve API keys", err)
+	}
+
+	return utils.JSONSuccess(c, keys)
+}
+
+// Create adds a named API key to a partner ({"label", "expires_at"?}) and returns it in plaintext once
+func (h *AdminPartnerAPIKeyHandler) Create(c *fiber.Ctx) error {
+	var req models.CreatePartnerAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	key, err := h.APIKeyService.Create(c.Context(), c.Params("id"), &req, adminIDFromContext(c))
+	if err != nil {
+		return apiKeyError(c, "failed to create API key", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "API key created successfully. Copy it now; it will not be shown again.",
+		"data":    key,
+	})
+}
+
+// Rotate issues a replacement for an API key ({"grace_hours"?}); the old key stays valid for the grace period
+func (h *AdminPartnerAPIKeyHandler) Rotate(c *fiber.Ctx) error {
+	var req models.RotatePartnerAPIKeyRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+		}
+	}
+
+	rotation, err := h.APIKeyService.Rotate(c.Context(), c.Params("id"), c.Params("keyId"), &req, adminIDFromContext(c))
+	if err != nil {
+		return apiKeyError(c, "failed to rotate API key", err)
+	}
+
+	return utils.JSONSuccessWithMessage(c, "API key rotated successfully. Copy the new key now; it will not be shown again.", rotation)
+}
+
+// Revoke revokes an API key immediately
+func (h *AdminPartnerAPIKeyHandler) Revoke(c *fiber.Ctx) error {
+	key, err := h.APIKeyService.Revoke(c.Context(), c.Params("id"), c.Params("keyId"), adminIDFromContext(c))
+	if err != nil {
+		return apiKeyError(c, "failed to revoke API key", err)
+	}
+
+	return utils.JSONSuccessWithMessage(c, "API key revoked successfully", key)
+}
+
+// apiKeyError maps API key errors to HTTP responses (400 validation, 404, 409, 500)
+func apiKeyError(c *fiber.Ctx, message string, err error) error {
+	var validationErr *utils.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+	case errors.Is(err, repository.ErrAPIKeyNotFound), errors.Is(err, repository.ErrPartnerNotFound):
+		return utils.JSONError(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, repository.ErrAPIKeyNotActive), errors.Is(err, service.ErrAPIKeyLimitReached):
+		return utils.JSONError(c, fiber.StatusConflict, err.Error())
+	}
+	log.Printf("AdminPartnerAPIKeyHandler - %s: %v", message, err)
+	return utils.JSONError(c, fiber.StatusInternalServerError, message)
+}
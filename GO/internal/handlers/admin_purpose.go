@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/internal/service"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// AdminPurposeHandler handles admin management of purposes of use and partner contracts
+type AdminPurposeHandler struct {
+	PurposeService *service.PurposeService
+}
+
+// NewAdminPurposeHandler creates a new admin purpose handler
+func NewAdminPurposeHandler(purposeService *service.PurposeService) *AdminPurposeHandler {
+	return &AdminPurposeHandler{
+		PurposeService: purposeService,
+	}
+}
+
+// List retrieves all purposes
+func (h *AdminPurposeHandler) List(c *fiber.Ctx) error {
+	purposes, err := h.PurposeService.List(c.Context())
+	if err != nil {
+		log.Printf("AdminPurposeHandler.List - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to retrieve purposes")
+	}
+
+	return utils.JSONSuccess(c, purposes)
+}
+
+// Create registers a new purpose
+func (h *AdminPurposeHandler) Create(c *fiber.Ctx) error {
+	var req models.CreatePurposeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	purpose, err := h.PurposeService.Create(c.Context(), &req)
+	if err != nil {
+		return purposeError(c, "failed to create purpose", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Purpose created successfully",
+		Data:    purpose,
+	})
+}
+
+// Update updates a purpose's description or active flag
+func (h *AdminPurposeHandler) Update(c *fiber.Ctx) error {
+	code := c.Params("code")
+	if code == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "purpose code is required")
+	}
+
+	var req models.UpdatePurposeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	purpose, err := h.PurposeService.Update(c.Context(), code, &req)
+	if err != nil {
+		return purposeError(c, "failed to update purpose", err)
+	}
+
+	return utils.JSONSuccessWithMessage(c, "Purpose updated successfully", purpose)
+}
+
+// GetPartnerPurposes retrieves the purposes in a partner's contract
+func (h *AdminPurposeHandler) GetPartnerPurposes(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "partner ID is required")
+	}
+
+	purposes, err := h.PurposeService.GetPartnerPurposes(c.Context(), id)
+	if err != nil {
+		return purposeError(c, "failed to retrieve partner purposes", err)
+	}
+
+	return utils.JSONSuccess(c, purposes)
+}
+
+// SetPartnerPurposes replaces the purposes in a partner's contract
+func (h *AdminPurposeHandler) SetPartnerPurposes(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "partner ID is required")
+	}
+
+	var req models.UpdatePartnerPurposesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	purposes, err := h.PurposeService.SetPartnerPurposes(c.Context(), id, req.Purposes)
+	if err != nil {
+		return purposeError(c, "failed to update partner purposes", err)
+	}
+
+	return utils.JSONSuccessWithMessage(c, "Partner purposes updated successfully", purposes)
+}
+
+// purposeError maps purpose errors to HTTP responses (400 validation, 404, 409, 500)
+func purposeError(c *fiber.Ctx, message string, err error) error {
+	var validationErr *utils.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+	case errors.Is(err, repository.ErrPurposeNotFound), errors.Is(err, repository.ErrPartnerNotFound):
+		return utils.JSONError(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, repository.ErrPurposeAlreadyExists):
+		return utils.JSONError(c, fiber.StatusConflict, err.Error())
+	}
+	log.Printf("AdminPurposeHandler - %s: %v", message, err)
+	return utils.JSONError(c, fiber.StatusInternalServerError, message)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/internal/service"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// AdminScopeHandler handles admin management of the scope registry
+type AdminScopeHandler struct {
+	ScopeRegistry *service.ScopeRegistry
+}
+
+// NewAdminScopeHandler creates a new admin scope handler
+func NewAdminScopeHandler(scopeRegistry *service.ScopeRegistry) *AdminScopeHandler {
+	return &AdminScopeHandler{
+		ScopeRegistry: scopeRegistry,
+	}
+}
+
+// List retrieves all scope definitions
+func (h *AdminScopeHandler) List(c *fiber.Ctx) error {
+	defs, err := h.ScopeRegistry.List(c.Context())
+	if err != nil {
+		log.Printf("AdminScopeHandler.List - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to retrieve scopes")
+	}
+
+	return utils.JSONSuccess(c, defs)
+}
+
+// Create registers a new scope
+func (h *AdminScopeHandler) Create(c *fiber.Ctx) error {
+	var req models.CreateScopeDefinitionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	def, err := h.ScopeRegistry.Create(c.Context(), &req)
+	if err != nil {
+		return scopeError(c, "failed to create scope", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Scope created successfully",
+		Data:    def,
+	})
+}
+
+// Update updates a scope definition
+func (h *AdminScopeHandler) Update(c *fiber.Ctx) error {
+	name := c.Params("name")
+	if name == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "scope name is required")
+	}
+
+	var req models.UpdateScopeDefinitionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	def, err := h.ScopeRegistry.Update(c.Context(), name, &req)
+	if err != nil {
+		return scopeError(c, "failed to update scope", err)
+	}
+
+	return utils.JSONSuccessWithMessage(c, "Scope updated successfully", def)
+}
+
+// Delete removes a scope that is not assigned to any partner
+func (h *AdminScopeHandler) Delete(c *fiber.Ctx) error {
+	name := c.Params("name")
+	if name == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "scope name is required")
+	}
+
+	if err := h.ScopeRegistry.Delete(c.Context(), name); err != nil {
+		return scopeError(c, "failed to delete scope", err)
+	}
+
+	return utils.JSONSuccessWithMessage(c, "Scope deleted successfully", nil)
+}
+
+// scopeError maps scope registry errors to HTTP responses (400 validation, 404, 409, 500)
+func scopeError(c *fiber.Ctx, message string, err error) error {
+	var validationErr *utils.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+	case errors.Is(err, repository.ErrScopeDefinitionNotFound):
+		return utils.JSONError(c, fiber.StatusNotFound, "scope not found")
+	case errors.Is(err, repository.ErrScopeDefinitionAlreadyExists), errors.Is(err, repository.ErrScopeDefinitionInUse):
+		return utils.JSONError(c, fiber.StatusConflict, err.Error())
+	}
+	log.Printf("AdminScopeHandler - %s: %v", message, err)
+	return utils.JSONError(c, fiber.StatusInternalServerError, message)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/internal/service"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// AdminScopePackageHandler handles admin management of scope packages
+type AdminScopePackageHandler struct {
+	ScopePackageService *service.ScopePackageService
+}
+
+// NewAdminScopePackageHandler creates a new admin scope package handler
+func NewAdminScopePackageHandler(scopePackageService *service.ScopePackageService) *AdminScopePackageHandler {
+	return &AdminScopePackageHandler{
+		ScopePackageService: scopePackageService,
+	}
+}
+
+// List retrieves all scope packages
+func (h *AdminScopePackageHandler) List(c *fiber.Ctx) error {
+	packages, err := h.ScopePackageService.List(c.Context())
+	if err != nil {
+		log.Printf("AdminScopePackageHandler.List - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to retrieve scope packages")
+	}
+
+	return utils.JSONSuccess(c, packages)
+}
+
+// Get retrieves a single scope package
+func (h *AdminScopePackageHandler) Get(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "scope package ID is required")
+	}
+
+	pkg, err := h.ScopePackageService.Get(c.Context(), id)
+	if err != nil {
+		log.Printf("AdminScopePackageHandler.Get - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to retrieve scope package")
+	}
+	if pkg == nil {
+		return utils.JSONError(c, fiber.StatusNotFound, "scope package not found")
+	}
+
+	return utils.JSONSuccess(c, pkg)
+}
+
+// Create creates a new scope package
+func (h *AdminScopePackageHandler) Create(c *fiber.Ctx) error {
+	var req models.CreateScopePackageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	pkg, err := h.ScopePackageService.Create(c.Context(), &req)
+	if err != nil {
+		return scopePackageError(c, "failed to create scope package", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Scope package created successfully",
+		Data:    pkg,
+	})
+}
+
+// Update updates a scope package (applies to every partner on it)
+func (h *AdminScopePackageHandler) Update(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "scope package ID is required")
+	}
+
+	var req models.UpdateScopePackageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	pkg, err := h.ScopePackageService.Update(c.Context(), id, &req, adminIDFromContext(c))
+	if err != nil {
+		return scopePackageError(c, "failed to update scope package", err)
+	}
+
+	return utils.JSONSuccessWithMessage(c, "Scope package updated successfully", pkg)
+}
+
+// Delete deletes a scope package that is not assigned to any partner
+func (h *AdminScopePackageHandler) Delete(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "scope package ID is required")
+	}
+
+	if err := h.ScopePackageService.Delete(c.Context(), id); err != nil {
+		return scopePackageError(c, "failed to delete scope package", err)
+	}
+
+	return utils.JSONSuccessWithMessage(c, "Scope package deleted successfully", nil)
+}
+
+// GetPartnerPackage retrieves the package assigned to a partner (data is null when none)
+func (h *AdminScopePackageHandler) GetPartnerPackage(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "partner ID is required")
+	}
+
+	pkg, err := h.ScopePackageService.GetPartnerPackage(c.Context(), id)
+	if err != nil {
+		log.Printf("AdminScopePackageHandler.GetPartnerPackage - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to retrieve scope package")
+	}
+
+	return utils.JSONSuccess(c, pkg)
+}
+
+// AssignToPartner assigns a package to a partner ({"scope_package_id": null} removes it)
+func (h *AdminScopePackageHandler) AssignToPartner(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "partner ID is required")
+	}
+
+	var req models.AssignScopePackageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	pkg, err := h.ScopePackageService.AssignToPartner(c.Context(), id, req.ScopePackageID, adminIDFromContext(c))
+	if err != nil {
+		return scopePackageError(c, "failed to assign scope package", err)
+	}
+
+	return utils.JSONSuccessWithMessage(c, "Scope package assigned successfully", pkg)
+}
+
+// scopePackageError maps scope package errors to HTTP responses (400 validation, 404, 409, 500)
+func scopePackageError(c *fiber.Ctx, message string, err error) error {
+	var validationErr *utils.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+	case errors.Is(err, repository.ErrScopePackageNotFound), errors.Is(err, repository.ErrPartnerNotFound):
+		return utils.JSONError(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, repository.ErrScopePackageAlreadyExists), errors.Is(err, repository.ErrScopePackageInUse):
+		return utils.JSONError(c, fiber.StatusConflict, err.Error())
+	}
+	log.Printf("AdminScopePackageHandler - %s: %v", message, err)
+	return utils.JSONError(c, fiber.StatusInternalServerError, message)
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/internal/service"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// AdminTKHandler handles admin management of TK master data
+type AdminTKHandler struct {
+	TKService *service.TKService
+}
+
+// NewAdminTKHandler creates a new admin TK handler
+func NewAdminTKHandler(tkService *service.TKService) *AdminTKHandler {
+	return &AdminTKHandler{
+		TKService: tkService,
+	}
+}
+
+// List retrieves TK data with pagination (?page=&limit=) and search (?q= NIK or name)
+func (h *AdminTKHandler) List(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	result, err := h.TKService.List(c.Context(), c.Query("q"), page, limit)
+	if err != nil {
+		log.Printf("AdminTKHandler.List - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to retrieve TK data")
+	}
+
+	return utils.JSONSuccess(c, result)
+}
+
+// Get retrieves a single TK record by NIK
+func (h *AdminTKHandler) Get(c *fiber.Ctx) error {
+	nik := c.Params("nik")
+	if nik == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "nik is required")
+	}
+
+	tk, err := h.TKService.Get(c.Context(), nik)
+	if err != nil {
+		log.Printf("AdminTKHandler.Get - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to retrieve TK data")
+	}
+	if tk == nil {
+		return utils.JSONError(c, fiber.StatusNotFound, "TK data not found")
+	}
+
+	return utils.JSONSuccess(c, tk)
+}
+
+// Create creates a new TK record
+func (h *AdminTKHandler) Create(c *fiber.Ctx) error {
+	var req models.CreateTKDataRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	tk, err := h.TKService.Create(c.Context(), &req)
+	if err != nil {
+		return tkError(c, "failed to create TK data", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "TK data created successfully",
+		Data:    tk,
+	})
+}
+
+// Update updates an existing TK record
+func (h *AdminTKHandler) Update(c *fiber.Ctx) error {
+	nik := c.Params("nik")
+	if nik == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "nik is required")
+	}
+
+	var req models.UpdateTKDataRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	tk, err := h.TKService.Update(c.Context(), nik, &req)
+	if err != nil {
+		return tkError(c, "failed to update TK data", err)
+	}
+
+	return utils.JSONSuccessWithMessage(c, "TK data updated successfully", tk)
+}
+
+// Delete deletes a TK record
+func (h *AdminTKHandler) Delete(c *fiber.Ctx) error {
+	nik := c.Params("nik")
+	if nik == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "nik is required")
+	}
+
+	if err := h.TKService.Delete(c.Context(), nik); err != nil {
+		return tkError(c, "failed to delete TK data", err)
+	}
+
+	return utils.JSONSuccessWithMessage(c, "TK data deleted successfully", nil)
+}
+
+// History retrieves the participation status timeline of a worker
+func (h *AdminTKHandler) History(c *fiber.Ctx) error {
+	nik := c.Params("nik")
+	if nik == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "nik is required")
+	}
+
+	timeline, err := h.TKService.History(c.Context(), nik)
+	if err != nil {
+		return tkError(c, "failed to retrieve status history", err)
+	}
+
+	return utils.JSONSuccess(c, timeline)
+}
+
+// Import imports TK data from a CSV or XLSX file (multipart field "file").
+// With ?dry_run=true the report is produced without committing anything.
+func (h *AdminTKHandler) Import(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "file is required (multipart field 'file')")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "failed to read uploaded file")
+	}
+	defer file.Close()
+
+	dryRun := c.QueryBool("dry_run", false)
+
+	report, err := h.TKService.Import(c.Context(), fileHeader.Filename, file, dryRun)
+	if err != nil {
+		return tkError(c, "failed to import TK data", err)
+	}
+
+	message := "Import completed"
+	if dryRun {
+		message = "Dry run completed, no changes were committed"
+	}
+
+	return utils.JSONSuccessWithMessage(c, message, report)
+}
+
+// tkError maps TK service errors to HTTP responses (400 validation, 404, 409, 500)
+func tkError(c *fiber.Ctx, message string, err error) error {
+	var validationErr *utils.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+	case errors.Is(err, repository.ErrTKNotFound):
+		return utils.JSONError(c, fiber.StatusNotFound, "TK data not found")
+	case errors.Is(err, repository.ErrTKAlreadyExists):
+		return utils.JSONError(c, fiber.StatusConflict, "TK data with this NIK already exists")
+	}
+	log.Printf("AdminTKHandler - %s: %v", message, err)
+	return utils.JSONError(c, fiber.StatusInternalServerError, message)
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/service"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// mapCheckJobForResponse fills display-only fields of a job
+func mapCheckJobForResponse(job *models.CheckJob) *models.CheckJob {
+	if job != nil {
+		job.Progress = 100
+		if job.TotalRows > 0 {
+			job.Progress = float64(job.ProcessedRows) * 100 / float64(job.TotalRows)
+		}
+	}
+	return job
+}
+
+// CheckJobHandler handles asynchronous bulk check jobs for partners
+type CheckJobHandler struct {
+	CheckJobService *service.CheckJobService
+}
+
+// NewCheckJobHandler creates a new check job handler
+func NewCheckJobHandler(checkJobService *service.CheckJobService) *CheckJobHandler {
+	return &CheckJobHandler{
+		CheckJobService: checkJobService,
+	}
+}
+
+// Create uploads a CSV file (multipart field "file", plus "purpose" and optional "consent_ref") and queues a new job
+func (h *CheckJobHandler) Create(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "file is required (multipart field 'file')")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "failed to read uploaded file")
+	}
+	defer file.Close()
+
+	partnerID := c.Locals("partnerID").(string)
+	apiKeyID, _ := c.Locals("apiKeyID").(string)
+
+	purpose := strings.TrimSpace(c.FormValue("purpose"))
+	consentRef := strings.TrimSpace(c.FormValue("consent_ref"))
+
+	job, err := h.CheckJobService.CreateJob(c.Context(), partnerID, apiKeyID, fileHeader.Filename, purpose, consentRef, file)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+		}
+		if errors.Is(err, service.ErrPurposeNotAllowed) {
+			return utils.JSONError(c, fiber.StatusForbidden, err.Error())
+		}
+		log.Printf("CheckJobHandler.Create - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to create job")
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Job queued successfully",
+		Data:    mapCheckJobForResponse(job),
+	})
+}
+
+// List retrieves the partner's jobs
+func (h *CheckJobHandler) List(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 20)
+	offset := c.QueryInt("offset", 0)
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	partnerID := c.Locals("partnerID").(string)
+
+	jobs, err := h.CheckJobService.ListJobs(c.Context(), partnerID, limit, offset)
+	if err != nil {
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to retrieve jobs")
+	}
+
+	for _, job := range jobs {
+		mapCheckJobForResponse(job)
+	}
+
+	return utils.JSONSuccess(c, jobs)
+}
+
+// Get retrieves job status and progress
+func (h *CheckJobHandler) Get(c *fiber.Ctx) error {
+	job, err := h.findJob(c)
+	if err != nil || job == nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, mapCheckJobForResponse(job))
+}
+
+// Results downloads job results as CSV (default) or NDJSON (?format=ndjson)
+func (h *CheckJobHandler) Results(c *fiber.Ctx) error {
+	format := c.Query("format", "csv")
+	if format != "csv" && format != "ndjson" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "format must be csv or ndjson")
+	}
+
+	job, err := h.findJob(c)
+	if err != nil || job == nil {
+		return err
+	}
+
+	if job.Status != models.CheckJobCompleted {
+		return utils.JSONError(c, fiber.StatusConflict, fmt.Sprintf("job is %s, results are available once completed", job.Status))
+	}
+
+	write := h.CheckJobService.WriteResultsCSV
+	contentType := "text/csv"
+	if format == "ndjson" {
+		write = h.CheckJobService.WriteResultsNDJSON
+		contentType = "application/x-ndjson"
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="check-job-%s.%s"`, job.ID, format))
+
+	// Stream rows straight from the database to the client
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := write(context.Background(), job, w); err != nil {
+			log.Printf("CheckJobHandler.Results - job %s: %v", job.ID, err)
+		}
+		w.Flush()
+	})
+
+	return nil
+}
+
+// findJob loads the job from the :id param, scoped to the authenticated partner.
+// It writes the error response itself and returns a nil job in that case.
+func (h *CheckJobHandler) findJob(c *fiber.Ctx) (*models.CheckJob, error) {
+	id := c.Params("id")
+	if id == "" {
+		return nil, utils.JSONError(c, fiber.StatusBadRequest, "job ID is required")
+	}
+
+	partnerID := c.Locals("partnerID").(string)
+
+	job, err := h.CheckJobService.GetJob(c.Context(), id, partnerID)
+	if err != nil {
+		return nil, utils.JSONError(c, fiber.StatusInternalServerError, "failed to retrieve job")
+	}
+	if job == nil {
+		return nil, utils.JSONError(c, fiber.StatusNotFound, "job not found")
+	}
+
+	return job, nil
+}
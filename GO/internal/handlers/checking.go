@@ -1,6 +1,10 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"log"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/username/go-gin-backend/internal/models"
 	"github.com/username/go-gin-backend/internal/service"
@@ -10,12 +14,14 @@ import (
 // CheckingHandler handles TK checking requests
 type CheckingHandler struct {
 	CheckingService *service.CheckingService
+	MaxBatchItems   int
 }
 
 // NewCheckingHandler creates a new checking handler
-func NewCheckingHandler(checkingService *service.CheckingService) *CheckingHandler {
+func NewCheckingHandler(checkingService *service.CheckingService, maxBatchItems int) *CheckingHandler {
 	return &CheckingHandler{
 		CheckingService: checkingService,
+		MaxBatchItems:   maxBatchItems,
 	}
 }
 
@@ -30,6 +36,7 @@ func (h *CheckingHandler) CheckTK(c *fiber.Ctx) error {
 	partnerID := c.Locals("partnerID").(string)
 	rawScopes := c.Locals("partnerScopes")
 	scopes := rawScopes.([]models.PartnerScope)
+	apiKeyID, _ := c.Locals("apiKeyID").(string)
 
 	// Perform TK check
 	response, err := h.CheckingService.CheckTK(
@@ -38,9 +45,20 @@ func (h *CheckingHandler) CheckTK(c *fiber.Ctx) error {
 		partnerID,
 		scopes,
 		nil, // userID not used (only admin login)
+		apiKeyID,
 	)
 	if err != nil {
-		return utils.JSONError(c, fiber.StatusInternalServerError, err.Error())
+		var validationErr *utils.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+		case errors.Is(err, service.ErrStatusHistoryScopeRequired), errors.Is(err, service.ErrPurposeNotAllowed):
+			return utils.JSONError(c, fiber.StatusForbidden, err.Error())
+		case errors.Is(err, service.ErrAuditQueueFull):
+			return utils.JSONError(c, fiber.StatusServiceUnavailable, err.Error())
+		}
+		log.Printf("CheckingHandler.CheckTK - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to check TK data")
 	}
 
 	// Check if TK was found
@@ -50,3 +68,93 @@ func (h *CheckingHandler) CheckTK(c *fiber.Ctx) error {
 
 	return utils.JSONSuccessWithMessage(c, "TK data found and verified", response)
 }
+
+// VerifyTK handles verify-only requests: partner-supplied nama/alamat are matched against TK data
+// and only per-field match results are returned
+func (h *CheckingHandler) VerifyTK(c *fiber.Ctx) error {
+	var req models.VerifyTKRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	// Get partner info and scopes from context (set by middleware)
+	partnerID := c.Locals("partnerID").(string)
+	scopes := c.Locals("partnerScopes").([]models.PartnerScope)
+	apiKeyID, _ := c.Locals("apiKeyID").(string)
+
+	response, err := h.CheckingService.VerifyTK(
+		c.Context(),
+		req,
+		partnerID,
+		scopes,
+		nil, // userID not used (only admin login)
+		apiKeyID,
+	)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			return utils.JSONErrorWithDetail(c, fiber.StatusBadRequest, "validation failed", validationErr.Error())
+		case errors.Is(err, service.ErrVerifyScopeRequired), errors.Is(err, service.ErrPurposeNotAllowed):
+			return utils.JSONError(c, fiber.StatusForbidden, err.Error())
+		case errors.Is(err, service.ErrAuditQueueFull):
+			return utils.JSONError(c, fiber.StatusServiceUnavailable, err.Error())
+		}
+		log.Printf("CheckingHandler.VerifyTK - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to verify TK data")
+	}
+
+	if !response.Found {
+		return utils.JSONSuccessWithMessage(c, "TK data not found or date of birth mismatch", response)
+	}
+
+	return utils.JSONSuccessWithMessage(c, "TK data verified", response)
+}
+
+// CheckTKBatch handles batch TK checking request (multiple NIK/DOB pairs)
+func (h *CheckingHandler) CheckTKBatch(c *fiber.Ctx) error {
+	var req models.BatchCheckTKRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	if len(req.Items) == 0 {
+		return utils.JSONError(c, fiber.StatusBadRequest, "items is required")
+	}
+	if len(req.Items) > h.MaxBatchItems {
+		return utils.JSONError(c, fiber.StatusBadRequest, fmt.Sprintf("too many items, maximum is %d per request", h.MaxBatchItems))
+	}
+
+	// Request-level purpose / consent_ref apply to items that do not set their own
+	for i := range req.Items {
+		if req.Items[i].Purpose == "" {
+			req.Items[i].Purpose = req.Purpose
+		}
+		if req.Items[i].ConsentRef == "" {
+			req.Items[i].ConsentRef = req.ConsentRef
+		}
+	}
+
+	// Get partner info and scopes from context (set by middleware)
+	partnerID := c.Locals("partnerID").(string)
+	scopes := c.Locals("partnerScopes").([]models.PartnerScope)
+	apiKeyID, _ := c.Locals("apiKeyID").(string)
+
+	response, err := h.CheckingService.CheckTKBatch(
+		c.Context(),
+		req.Items,
+		partnerID,
+		scopes,
+		nil, // userID not used (only admin login)
+		apiKeyID,
+	)
+	if err != nil {
+		if errors.Is(err, service.ErrAuditQueueFull) {
+			return utils.JSONError(c, fiber.StatusServiceUnavailable, err.Error())
+		}
+		log.Printf("CheckingHandler.CheckTKBatch - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to check TK data")
+	}
+
+	return utils.JSONSuccessWithMessage(c, "Batch check completed", response)
+}
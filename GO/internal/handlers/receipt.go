@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/service"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// ReceiptHandler handles the public receipt verification endpoints
+type ReceiptHandler struct {
+	ReceiptService *service.ReceiptService
+}
+
+// NewReceiptHandler creates a new receipt handler
+func NewReceiptHandler(receiptService *service.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{
+		ReceiptService: receiptService,
+	}
+}
+
+// JWKS returns the public keys used to sign receipts (plain JWKS document, no response envelope)
+func (h *ReceiptHandler) JWKS(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(h.ReceiptService.JWKS())
+}
+
+// Verify validates a receipt signature and checks it against audit_logs
+func (h *ReceiptHandler) Verify(c *fiber.Ctx) error {
+	var req models.VerifyReceiptRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.JSONError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+	req.Receipt = strings.TrimSpace(req.Receipt)
+	if req.Receipt == "" {
+		return utils.JSONError(c, fiber.StatusBadRequest, "receipt is required")
+	}
+
+	result, err := h.ReceiptService.Verify(c.Context(), req.Receipt)
+	if err != nil {
+		log.Printf("ReceiptHandler.Verify - %v", err)
+		return utils.JSONError(c, fiber.StatusInternalServerError, "failed to verify receipt")
+	}
+
+	if !result.Valid {
+		return utils.JSONSuccessWithMessage(c, "Receipt is not valid", result)
+	}
+	return utils.JSONSuccessWithMessage(c, "Receipt is valid", result)
+}
@@ -5,10 +5,11 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/internal/service"
 )
 
 // PartnerAPIKeyAuth validates API key from header, checks status and contract period
-func PartnerAPIKeyAuth(partnerRepo *repository.PartnerRepository, scopeRepo *repository.ScopeRepository) fiber.Handler {
+func PartnerAPIKeyAuth(apiKeyService *service.PartnerAPIKeyService, scopeRepo *repository.ScopeRepository) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// 1. Get API key from header
 		apiKey := c.Get("X-API-KEY")
@@ -19,8 +20,8 @@ func PartnerAPIKeyAuth(partnerRepo *repository.PartnerRepository, scopeRepo *rep
 			})
 		}
 
-		// 2. Query database to find partner by API key
-		partner, err := partnerRepo.GetByAPIKey(c.Context(), apiKey)
+		// 2. Find partner by any active API key (key ID lookup + constant-time hash check)
+		partner, key, err := apiKeyService.Authenticate(c.Context(), apiKey)
 		if err != nil || partner == nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"success": false,
@@ -51,8 +52,8 @@ func PartnerAPIKeyAuth(partnerRepo *repository.PartnerRepository, scopeRepo *rep
 			})
 		}
 
-		// 5. Load scopes from database (source of truth)
-		scopes, err := scopeRepo.GetByPartnerID(c.Context(), partner.ID)
+		// 5. Load scopes active today from database (source of truth)
+		scopes, err := scopeRepo.GetActiveByPartnerID(c.Context(), partner.ID)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"success": false,
@@ -64,6 +65,7 @@ func PartnerAPIKeyAuth(partnerRepo *repository.PartnerRepository, scopeRepo *rep
 		c.Locals("partnerID", partner.ID)
 		c.Locals("partnerScopes", scopes)
 		c.Locals("partner", partner)
+		c.Locals("apiKeyID", key.ID) // recorded in audit_logs
 
 		return c.Next()
 	}
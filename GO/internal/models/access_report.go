@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AccessRecord is an audit log row of a NIK joined with the partner that made the check
+type AccessRecord struct {
+	AuditID         string          `db:"id"`
+	PartnerID       string          `db:"partner_id"`
+	PartnerName     string          `db:"company_name"`
+	CompanyID       string          `db:"company_id"`
+	ScopesUsed      json.RawMessage `db:"scopes_used"`
+	ResponsePayload json.RawMessage `db:"response_payload"`
+	Purpose         *string         `db:"purpose"`
+	ConsentRef      *string         `db:"consent_ref"`
+	ResultCode      *string         `db:"result_code"`
+	AccessedAt      time.Time       `db:"created_at"`
+}
+
+// AccessReportEntry represents one lookup of a worker's NIK by a partner (field names only, never values)
+type AccessReportEntry struct {
+	AuditID     string    `json:"audit_id"`
+	PartnerID   string    `json:"partner_id"`
+	PartnerName string    `json:"partner_name"`
+	CompanyID   string    `json:"company_id"`
+	AccessedAt  time.Time `json:"accessed_at"`
+	Purpose     *string   `json:"purpose"` // nil for checks made before purposes were required
+	ConsentRef  *string   `json:"consent_ref,omitempty"`
+	Found       bool      `json:"found"`
+	Scopes      []string  `json:"scopes"` // scopes enabled for the partner at the time
+	Fields      []string  `json:"fields"` // data fields returned to the partner
+}
+
+// AccessReport is the data-subject access report of a NIK: every partner lookup, oldest first
+type AccessReport struct {
+	NIK          string              `json:"nik"`
+	GeneratedAt  time.Time           `json:"generated_at"`
+	TotalLookups int                 `json:"total_lookups"`
+	Partners     int                 `json:"partners"` // distinct partners
+	Entries      []AccessReportEntry `json:"entries"`
+}
+
+// Access report output formats
+const (
+	AccessReportFormatJSON = "json"
+	AccessReportFormatHTML = "html"
+)
@@ -14,15 +14,126 @@ type AuditLog struct {
 	ScopesUsed      json.RawMessage `db:"scopes_used" json:"scopes_used"`           // JSONB - scopes used in this check
 	RequestPayload  json.RawMessage `db:"request_payload" json:"request_payload"`   // JSONB
 	ResponsePayload json.RawMessage `db:"response_payload" json:"response_payload"` // JSONB
+	ResultCode      *string         `db:"result_code" json:"result_code,omitempty"` // check outcome, nil before V13
+	Purpose         *string         `db:"purpose" json:"purpose,omitempty"`         // purpose of use, nil before V14
+	ConsentRef      *string         `db:"consent_ref" json:"consent_ref,omitempty"`
+	APIKeyID        *string         `db:"api_key_id" json:"api_key_id,omitempty"` // partner_api_keys.id used, nil before V21
 	CreatedAt       time.Time       `db:"created_at" json:"created_at"`
 }
 
 // CreateAuditLogRequest represents data needed to create an audit log
 type CreateAuditLogRequest struct {
+	ID              string      `json:"id"` // generated by the caller so receipts can reference it
 	PartnerID       string      `json:"partner_id"`
 	UserID          *string     `json:"user_id,omitempty"`
 	NIK             string      `json:"nik"`
 	ScopesUsed      interface{} `json:"scopes_used"`
 	RequestPayload  interface{} `json:"request_payload"`
 	ResponsePayload interface{} `json:"response_payload"`
+	ResultCode      string      `json:"result_code"`
+	Purpose         string      `json:"purpose"`
+	ConsentRef      string      `json:"consent_ref,omitempty"`
+	APIKeyID        string      `json:"api_key_id,omitempty"` // partner API key used for the check
+	CreatedAt       time.Time   `json:"created_at"`           // time of the check, set when queued (NOW() when zero)
+}
+
+// AuditLogFilter filters the admin audit log search (zero values are ignored)
+type AuditLogFilter struct {
+	PartnerID   string
+	NIK         string
+	NIKToken    string     // keyed token of NIK, matched as well (rows written with AUDIT_NIK_KEY)
+	From        *time.Time // inclusive
+	To          *time.Time // exclusive
+	ResultCodes []string   // any of these result codes
+	Scope       string     // scope enabled for the partner at the time of the check
+	Limit       int
+	// Keyset cursor: only rows strictly after (older than) this position
+	AfterCreatedAt *time.Time
+	AfterID        string
+}
+
+// AuditLogPage represents a page of audit logs ordered by created_at, id descending
+type AuditLogPage struct {
+	Items      []*AuditLog `json:"items"`
+	NextCursor *string     `json:"next_cursor"` // nil on the last page
+}
+
+// Outcome filter aliases accepted by the audit log search in addition to result codes
+const (
+	AuditOutcomeFound    = "found"     // MATCHED or DATA_INACTIVE
+	AuditOutcomeNotFound = "not_found" // NIK_NOT_FOUND or DOB_MISMATCH
+)
+
+// AuditLogSearchRequest represents the query string of the admin audit log search
+type AuditLogSearchRequest struct {
+	PartnerID string `query:"partner_id"`
+	NIK       string `query:"nik"`
+	From      string `query:"from"`    // YYYY-MM-DD or RFC3339, inclusive
+	To        string `query:"to"`      // YYYY-MM-DD (inclusive day) or RFC3339 (exclusive)
+	Outcome   string `query:"outcome"` // comma-separated result codes, or found / not_found
+	Scope     string `query:"scope"`
+	Cursor    string `query:"cursor"` // next_cursor of the previous page
+	Limit     int    `query:"limit"`
+}
+
+// Audit export formats
+const (
+	AuditExportFormatCSV    = "csv"
+	AuditExportFormatNDJSON = "ndjson"
+)
+
+// Audit export redaction modes for the NIK and payload values
+const (
+	AuditRedactNone   = "none"   // values exported as stored
+	AuditRedactHash   = "hash"   // values replaced by their SHA-256 hex (joinable across exports)
+	AuditRedactRedact = "redact" // NIK masked, payload values replaced by "[REDACTED]"
+)
+
+// AuditExportRequest represents the query string of an audit log export. The filters are
+// those of the audit log search; there is no cursor or limit, every matching row is exported.
+type AuditExportRequest struct {
+	PartnerID string `query:"partner_id"`
+	NIK       string `query:"nik"`
+	From      string `query:"from"`
+	To        string `query:"to"`
+	Outcome   string `query:"outcome"`
+	Scope     string `query:"scope"`
+	Format    string `query:"format"` // csv (default) or ndjson
+	Redact    string `query:"redact"` // none, hash or redact (default: hash with AUDIT_NIK_KEY, else redact)
+}
+
+// AuditExportResult summarizes a completed export
+type AuditExportResult struct {
+	Rows   int    `json:"rows"`
+	SHA256 string `json:"sha256"` // hex checksum of the exported file
+}
+
+// AuditWriterMetrics represents the backlog and counters of the audit writer since startup
+type AuditWriterMetrics struct {
+	Policy         string     `json:"policy"`       // block, drop or reject when the queue is full
+	QueueLength    int        `json:"queue_length"` // entries waiting to be inserted
+	QueueCapacity  int        `json:"queue_capacity"`
+	Enqueued       int64      `json:"enqueued"`
+	Written        int64      `json:"written"`
+	Dropped        int64      `json:"dropped"`     // discarded by the drop policy
+	Rejected       int64      `json:"rejected"`    // checks refused by the reject policy
+	Spilled        int64      `json:"spilled"`     // written to the spill file after a failed insert
+	Quarantined    int64      `json:"quarantined"` // rejected by the database, written to the quarantine file
+	Lost           int64      `json:"lost"`        // neither inserted, spilled nor quarantined
+	FailedBatches  int64      `json:"failed_batches"`
+	SpillFile      string     `json:"spill_file"`
+	QuarantineFile string     `json:"quarantine_file"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastErrorAt    *time.Time `json:"last_error_at,omitempty"`
+}
+
+// UpdateAuditDebugRequest represents request to store a partner's full audit payloads for a while
+type UpdateAuditDebugRequest struct {
+	Hours *int `json:"hours"` // Required: 1-168; 0 stops debugging
+}
+
+// AuditDebugStatus represents whether a partner's full audit payloads are stored
+type AuditDebugStatus struct {
+	PartnerID       string     `json:"partner_id"`
+	AuditDebugUntil *time.Time `json:"audit_debug_until"` // null = minimal payloads
 }
@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// AuditChainRow represents an audit log with its hash chain link
+type AuditChainRow struct {
+	AuditLog
+	ChainSeq int64  `json:"chain_seq"`
+	PrevHash string `json:"prev_hash"`
+	RowHash  string `json:"row_hash"`
+}
+
+// AuditChainHead represents the last link of a partner's audit chain
+type AuditChainHead struct {
+	PartnerID  string    `json:"partner_id"`
+	LastSeq    int64     `json:"last_seq"`
+	LastHash   string    `json:"last_hash"`
+	PurgedSeq  int64     `json:"purged_seq"`  // last position dropped by the retention policy (0 = none)
+	PurgedHash string    `json:"purged_hash"` // row_hash at purged_seq
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// AuditChainCheckpoint represents a signed audit chain head
+type AuditChainCheckpoint struct {
+	ID        string    `json:"id"`
+	PartnerID string    `json:"partner_id"`
+	ChainSeq  int64     `json:"chain_seq"`
+	RowHash   string    `json:"row_hash"`
+	KeyID     string    `json:"key_id"`
+	Signature string    `json:"signature"` // compact JWS (EdDSA) over partner_id, chain_seq, row_hash
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Problems reported by the audit chain verifier
+const (
+	AuditChainGap                = "gap"                  // chain positions missing (rows deleted)
+	AuditChainDuplicate          = "duplicate"            // chain position used by more than one row
+	AuditChainBrokenLink         = "broken_link"          // prev_hash differs from the previous row's row_hash
+	AuditChainModified           = "modified"             // row content does not match its row_hash
+	AuditChainHeadMismatch       = "head_mismatch"        // last row differs from audit_chain_heads (tail deleted)
+	AuditChainCheckpointMismatch = "checkpoint_mismatch"  // row_hash differs from a signed checkpoint
+	AuditChainCheckpointMissing  = "checkpoint_missing"   // signed checkpoint points to a missing row
+	AuditChainCheckpointInvalid  = "checkpoint_signature" // checkpoint signature or content invalid
+)
+
+// AuditChainProblem represents one integrity problem found in an audit chain
+type AuditChainProblem struct {
+	PartnerID string `json:"partner_id"`
+	ChainSeq  int64  `json:"chain_seq"`
+	AuditID   string `json:"audit_id,omitempty"`
+	Kind      string `json:"kind"`
+	Detail    string `json:"detail"`
+}
+
+// AuditChainReport represents the result of an audit chain verification
+type AuditChainReport struct {
+	Valid               bool                `json:"valid"`
+	CheckedAt           time.Time           `json:"checked_at"`
+	PartnerID           string              `json:"partner_id,omitempty"` // empty = all partners
+	FromSeq             int64               `json:"from_seq,omitempty"`   // first chain position checked (0 = chain start)
+	ToSeq               int64               `json:"to_seq,omitempty"`     // last chain position checked (0 = chain head)
+	Partners            int                 `json:"partners"`
+	Rows                int64               `json:"rows"`
+	Unchained           int64               `json:"unchained"` // rows written before V17, not verifiable
+	Checkpoints         int                 `json:"checkpoints"`
+	CheckpointsVerified bool                `json:"checkpoints_verified"` // false without a persistent signing key
+	Problems            []AuditChainProblem `json:"problems"`
+	ProblemsTruncated   bool                `json:"problems_truncated"`
+}
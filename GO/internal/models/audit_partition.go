@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// AuditPartition represents a monthly audit_logs partition
+type AuditPartition struct {
+	Name         string    `json:"name"` // audit_logs_pYYYYMM
+	From         time.Time `json:"from"` // first instant of the month (UTC), inclusive
+	To           time.Time `json:"to"`   // first instant of the next month (UTC), exclusive
+	Attached     bool      `json:"attached"`
+	RowsEstimate int64     `json:"rows_estimate"` // planner estimate, -1 when never analyzed
+}
+
+// AuditPartitionArchive represents a partition archived by the retention policy
+type AuditPartitionArchive struct {
+	Partition string `json:"partition"`
+	File      string `json:"file"`
+	Rows      int64  `json:"rows"`
+	SHA256    string `json:"sha256"`
+}
+
+// AuditPartitionReport represents the result of one audit partition maintenance run
+type AuditPartitionReport struct {
+	RanAt       time.Time               `json:"ran_at"`
+	Created     []string                `json:"created"`
+	MovedRows   int64                   `json:"moved_rows"` // rows moved from the default partition into created partitions
+	Failed      []string                `json:"failed"`     // partitions that could not be created ("name: error"), retried next run
+	Archived    []AuditPartitionArchive `json:"archived"`
+	Dropped     []string                `json:"dropped"`
+	DefaultRows int64                   `json:"default_rows"` // rows outside every monthly partition
+	Partitions  []*AuditPartition       `json:"partitions"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CheckJob represents an asynchronous bulk check job uploaded by a partner
+type CheckJob struct {
+	ID            string     `db:"id" json:"id"`
+	PartnerID     string     `db:"partner_id" json:"partner_id"`
+	Status        string     `db:"status" json:"status"` // queued, running, completed, failed
+	FileName      *string    `db:"file_name" json:"file_name,omitempty"`
+	Purpose       *string    `db:"purpose" json:"purpose,omitempty"`         // Applies to every row
+	ConsentRef    *string    `db:"consent_ref" json:"consent_ref,omitempty"` // Applies to every row
+	APIKeyID      *string    `db:"api_key_id" json:"api_key_id,omitempty"`   // Key that uploaded the file, nil before V21
+	TotalRows     int        `db:"total_rows" json:"total_rows"`
+	ProcessedRows int        `db:"processed_rows" json:"processed_rows"`
+	FoundRows     int        `db:"found_rows" json:"found_rows"`
+	NotFoundRows  int        `db:"not_found_rows" json:"not_found_rows"`
+	InvalidRows   int        `db:"invalid_rows" json:"invalid_rows"`
+	ResultColumns []string   `db:"result_columns" json:"-"`
+	Attempts      int        `db:"attempts" json:"-"`
+	LastError     *string    `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	StartedAt     *time.Time `db:"started_at" json:"started_at,omitempty"`
+	FinishedAt    *time.Time `db:"finished_at" json:"finished_at,omitempty"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+	Progress      float64    `db:"-" json:"progress_percent"` // For UI/polling display
+}
+
+// CheckJobItem represents a single row of a bulk check job
+type CheckJobItem struct {
+	JobID        string          `db:"job_id" json:"-"`
+	RowNumber    int             `db:"row_number" json:"row_number"`
+	NIK          string          `db:"nik" json:"nik"`
+	TanggalLahir string          `db:"tanggal_lahir" json:"tanggal_lahir"`
+	Status       string          `db:"status" json:"status"` // pending, found, not_found, invalid
+	Error        *string         `db:"error" json:"error,omitempty"`
+	Result       json.RawMessage `db:"result" json:"result,omitempty"`
+}
+
+// Check job status values
+const (
+	CheckJobQueued    = "queued"
+	CheckJobRunning   = "running"
+	CheckJobCompleted = "completed"
+	CheckJobFailed    = "failed"
+)
+
+// CheckJobItemPending is the status of a job row that has not been processed yet
+const CheckJobItemPending = "pending"
@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// WorkerConsent represents a worker's consent allowing a partner to receive sensitive scopes for their NIK
+type WorkerConsent struct {
+	ID           string     `db:"id" json:"id"`
+	NIK          string     `db:"nik" json:"nik"`
+	PartnerID    string     `db:"partner_id" json:"partner_id"`
+	Scopes       []string   `db:"scopes" json:"scopes"`           // sensitive scopes covered
+	ValidFrom    Date       `db:"valid_from" json:"valid_from"`   // inclusive
+	ValidUntil   *Date      `db:"valid_until" json:"valid_until"` // inclusive, nil = until revoked
+	ConsentRef   *string    `db:"consent_ref" json:"consent_ref,omitempty"`
+	Source       string     `db:"source" json:"source"` // admin or import
+	CreatedBy    *string    `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	RevokedAt    *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	RevokedBy    *string    `db:"revoked_by" json:"revoked_by,omitempty"`
+	RevokeReason *string    `db:"revoke_reason" json:"revoke_reason,omitempty"`
+	State        string     `db:"-" json:"state"` // active, upcoming, expired or revoked
+}
+
+// CreateConsentRequest represents request to record a worker consent
+type CreateConsentRequest struct {
+	NIK        string   `json:"nik" binding:"required"`
+	PartnerID  string   `json:"partner_id" binding:"required"`
+	Scopes     []string `json:"scopes" binding:"required"`
+	ValidFrom  *Date    `json:"valid_from,omitempty"`  // Optional: YYYY-MM-DD, defaults to today
+	ValidUntil *Date    `json:"valid_until,omitempty"` // Optional: YYYY-MM-DD, nil = until revoked
+	ConsentRef string   `json:"consent_ref,omitempty"` // Optional: reference of the signed form
+}
+
+// RevokeConsentRequest represents request to revoke a worker consent
+type RevokeConsentRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// ConsentListFilter filters the consent list (empty fields are ignored)
+type ConsentListFilter struct {
+	NIK       string
+	PartnerID string
+}
+
+// ConsentImportRowResult represents the outcome of a single consent import row
+type ConsentImportRowResult struct {
+	Row    int    `json:"row"` // Row number in the file (header = row 1)
+	NIK    string `json:"nik"`
+	Status string `json:"status"` // inserted, unchanged (consent_ref already recorded), rejected
+	Reason string `json:"reason,omitempty"`
+}
+
+// ConsentImportReport represents the validation/insert report of a consent import
+type ConsentImportReport struct {
+	DryRun    bool                     `json:"dry_run"`
+	Total     int                      `json:"total"`
+	Inserted  int                      `json:"inserted"`
+	Unchanged int                      `json:"unchanged"`
+	Rejected  int                      `json:"rejected"`
+	Rows      []ConsentImportRowResult `json:"rows"`
+}
+
+// Consent sources
+const (
+	ConsentSourceAdmin  = "admin"
+	ConsentSourceImport = "import"
+)
+
+// ConsentStateRevoked is the state of a revoked consent (other states match ScopeState*)
+const ConsentStateRevoked = "revoked"
@@ -24,3 +24,12 @@ const (
 	TKStatusNonaktif = "nonaktif"
 	TKStatusUnknown  = "unknown"
 )
+
+// IsValidTKStatus reports whether s is a valid tk_status_enum value
+func IsValidTKStatus(s string) bool {
+	switch s {
+	case TKStatusAktif, TKStatusNonaktif, TKStatusUnknown:
+		return true
+	}
+	return false
+}
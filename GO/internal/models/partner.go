@@ -48,7 +48,6 @@ type Partner struct {
 	ID            string     `db:"id" json:"id"`
 	CompanyName   string     `db:"company_name" json:"company_name"`
 	CompanyID     string     `db:"company_id" json:"company_id"`
-	APIKey        *string    `db:"api_key" json:"-"`        // API key untuk authentication
 	CompanySecret string     `db:"company_secret" json:"-"` // Deprecated, kept for backward compatibility
 	NomorPKS      string     `db:"nomor_pks" json:"nomor_pks"`
 	PICName       string     `db:"pic_name" json:"pic_name"`
@@ -65,15 +64,17 @@ type Partner struct {
 
 // CreatePartnerRequest represents request to create a partner
 type CreatePartnerRequest struct {
-	CompanyName   string   `json:"company_name" binding:"required"`
-	CompanyID     string   `json:"company_id,omitempty"` // Optional: if empty, will be auto-generated
-	PICName       string   `json:"pic_name" binding:"required"`
-	PICEmail      string   `json:"pic_email" binding:"required,email"`
-	PICPhone      string   `json:"pic_phone"`
-	Notes         string   `json:"notes"`
-	Scopes        []string `json:"scopes"`                   // e.g., ["name","tanggal_lahir","status_bpjs","alamat"]
-	ContractStart *Date    `json:"contract_start,omitempty"` // Optional: if empty, will be set to today (accepts "YYYY-MM-DD" format)
-	ContractEnd   *Date    `json:"contract_end,omitempty"`   // Optional: if empty, will be set to 1 year from today (accepts "YYYY-MM-DD" format)
+	CompanyName    string   `json:"company_name" binding:"required"`
+	CompanyID      string   `json:"company_id,omitempty"` // Optional: if empty, will be auto-generated
+	PICName        string   `json:"pic_name" binding:"required"`
+	PICEmail       string   `json:"pic_email" binding:"required,email"`
+	PICPhone       string   `json:"pic_phone"`
+	Notes          string   `json:"notes"`
+	Scopes         []string `json:"scopes"`                     // e.g., ["name","tanggal_lahir","status_bpjs","alamat"]
+	ScopePackageID *string  `json:"scope_package_id,omitempty"` // Optional: scope package, Scopes are then per-partner additions
+	Purposes       []string `json:"purposes,omitempty"`         // Optional: purposes in the contract, if empty PARTNER_DEFAULT_PURPOSES
+	ContractStart  *Date    `json:"contract_start,omitempty"`   // Optional: if empty, will be set to today (accepts "YYYY-MM-DD" format)
+	ContractEnd    *Date    `json:"contract_end,omitempty"`     // Optional: if empty, will be set to 1 year from today (accepts "YYYY-MM-DD" format)
 }
 
 // UpdatePartnerRequest represents request to update a partner
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// PartnerAPIKey represents one of a partner's API keys (the secret itself is never stored)
+type PartnerAPIKey struct {
+	ID         string     `db:"id" json:"id"`
+	PartnerID  string     `db:"partner_id" json:"partner_id"`
+	Label      string     `db:"label" json:"label"`
+	KeyID      *string    `db:"key_id" json:"key_id"` // pks_live_<key_id>_..., nil = legacy UUID key
+	KeyHash    string     `db:"key_hash" json:"-"`
+	CreatedBy  *string    `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	ExpiresAt  *time.Time `db:"expires_at" json:"expires_at"` // nil = until revoked
+	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	RevokedBy  *string    `db:"revoked_by" json:"revoked_by,omitempty"`
+	RotatedTo  *string    `db:"rotated_to" json:"rotated_to,omitempty"` // key issued by rotating this one
+	State      string     `db:"-" json:"state"`                         // active, expired or revoked
+}
+
+// Partner API key states
+const (
+	APIKeyStateActive  = "active"
+	APIKeyStateExpired = "expired"
+	APIKeyStateRevoked = "revoked"
+)
+
+// CreatePartnerAPIKeyRequest represents request to add an API key to a partner
+type CreatePartnerAPIKeyRequest struct {
+	Label     string     `json:"label"`                // e.g. "server-1", "staging"
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Optional: RFC3339, nil = until revoked
+}
+
+// RotatePartnerAPIKeyRequest represents request to rotate a partner API key
+type RotatePartnerAPIKeyRequest struct {
+	GraceHours *int `json:"grace_hours,omitempty"` // Optional: old key stays valid this long (default API_KEY_ROTATION_GRACE_HOURS, 0 = revoke now)
+}
+
+// PartnerAPIKeyResponse represents a newly issued API key with its plaintext (returned only once)
+type PartnerAPIKeyResponse struct {
+	*PartnerAPIKey
+	APIKeyPlain string `json:"api_key"`
+}
+
+// PartnerAPIKeyRotation represents the result of a rotation: the new key and the old one with its grace expiry
+type PartnerAPIKeyRotation struct {
+	New *PartnerAPIKeyResponse `json:"new"`
+	Old *PartnerAPIKey         `json:"old"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Purpose represents a purpose-of-use code partners must state on every check
+type Purpose struct {
+	Code        string    `db:"code" json:"code"`
+	Description string    `db:"description" json:"description"`
+	Active      bool      `db:"active" json:"active"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// CreatePurposeRequest represents request to add a purpose code
+type CreatePurposeRequest struct {
+	Code        string `json:"code" binding:"required"`
+	Description string `json:"description"`
+}
+
+// UpdatePurposeRequest represents request to update a purpose (only provided fields are changed)
+type UpdatePurposeRequest struct {
+	Description *string `json:"description,omitempty"`
+	Active      *bool   `json:"active,omitempty"`
+}
+
+// UpdatePartnerPurposesRequest represents request to replace the purposes in a partner's contract
+type UpdatePartnerPurposesRequest struct {
+	Purposes []string `json:"purposes" binding:"required"`
+}
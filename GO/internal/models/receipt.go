@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Receipt outcomes used when the partner has no result_detail scope
+const (
+	ReceiptOutcomeFound    = "FOUND"
+	ReceiptOutcomeNotFound = "NOT_FOUND"
+)
+
+// VerifyReceiptRequest represents request to verify a signed verification receipt
+type VerifyReceiptRequest struct {
+	Receipt string `json:"receipt" binding:"required"`
+}
+
+// ReceiptVerification represents the result of verifying a receipt against audit_logs
+type ReceiptVerification struct {
+	Valid     bool       `json:"valid"`
+	Reason    string     `json:"reason,omitempty"` // why the receipt is not valid
+	AuditID   string     `json:"audit_id,omitempty"`
+	PartnerID string     `json:"partner_id,omitempty"`
+	NIKHash   string     `json:"nik_hash,omitempty"`
+	Outcome   string     `json:"outcome,omitempty"`
+	IssuedAt  *time.Time `json:"issued_at,omitempty"`
+}
+
+// JWK represents a public Ed25519 key in JWK format (RFC 8037)
+type JWK struct {
+	KeyType string `json:"kty"` // OKP
+	Curve   string `json:"crv"` // Ed25519
+	X       string `json:"x"`   // base64url public key
+	KeyID   string `json:"kid"`
+	Alg     string `json:"alg"` // EdDSA
+	Use     string `json:"use"` // sig
+}
+
+// JWKS represents a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
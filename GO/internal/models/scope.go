@@ -2,10 +2,14 @@ package models
 
 // PartnerScope represents access scope permissions for a partner
 type PartnerScope struct {
-	ID        string `db:"id" json:"id"`
-	PartnerID string `db:"partner_id" json:"partner_id"`
-	ScopeName string `db:"scope_name" json:"scope_name"` // e.g., "name", "tanggal_lahir", "status_bpjs", "alamat"
-	Enabled   bool   `db:"enabled" json:"enabled"`
+	ID         string `db:"id" json:"id"`
+	PartnerID  string `db:"partner_id" json:"partner_id"`
+	ScopeName  string `db:"scope_name" json:"scope_name"` // e.g., "name", "tanggal_lahir", "status_bpjs", "alamat"
+	Enabled    bool   `db:"enabled" json:"enabled"`
+	Source     string `db:"-" json:"source"`                // package or partner
+	ValidFrom  *Date  `db:"valid_from" json:"valid_from"`   // inclusive, nil = no start
+	ValidUntil *Date  `db:"valid_until" json:"valid_until"` // inclusive, nil = no end
+	State      string `db:"-" json:"state"`                 // active, upcoming or expired
 }
 
 // UpdateScopesRequest represents request to update partner scopes
@@ -15,16 +19,37 @@ type UpdateScopesRequest struct {
 
 // ScopeItem represents a single scope configuration
 type ScopeItem struct {
-	ScopeName string `json:"scope_name" binding:"required"`
-	Enabled   bool   `json:"enabled"`
+	ScopeName  string `json:"scope_name" binding:"required"`
+	Enabled    bool   `json:"enabled"`
+	ValidFrom  *Date  `json:"valid_from,omitempty"`  // Optional: YYYY-MM-DD, grant starts on this date
+	ValidUntil *Date  `json:"valid_until,omitempty"` // Optional: YYYY-MM-DD, grant ends after this date
 }
 
-// Available scope names
+// Scope sources of an effective scope
 const (
-	ScopeName         = "name"
-	ScopeTanggalLahir = "tanggal_lahir"
-	ScopeStatusBPJS   = "status_bpjs"
-	ScopeAlamat       = "alamat"
+	ScopeSourcePackage = "package" // inherited from the partner's scope package
+	ScopeSourcePartner = "partner" // per-partner row in partner_access_scopes
+)
+
+// Scope grant states (relative to the current date)
+const (
+	ScopeStateActive   = "active"
+	ScopeStateUpcoming = "upcoming"
+	ScopeStateExpired  = "expired"
+)
+
+// Built-in scope names (seeded in scope_definitions, which is the source of truth)
+const (
+	ScopeName          = "name"
+	ScopeTanggalLahir  = "tanggal_lahir"
+	ScopeStatusBPJS    = "status_bpjs"
+	ScopeAlamat        = "alamat"
+	ScopeStatusHistory = "status_history" // allows as_of checks on participation status
+	ScopeVerifyName    = "verify_name"    // verify-only match on nama
+	ScopeVerifyAlamat  = "verify_alamat"  // verify-only match on alamat
+	ScopeNameMasked    = "name_masked"    // nama, partially masked
+	ScopeAlamatMasked  = "alamat_masked"  // alamat, partially masked
+	ScopeResultDetail  = "result_detail"  // exposes result_code in check responses
 )
 
 // DefaultScopes returns the default scopes for a new partner
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// ScopeDefinition represents a registered scope and the TK field it exposes
+type ScopeDefinition struct {
+	Name        string    `db:"name" json:"name"`
+	Description string    `db:"description" json:"description"`
+	TKField     *string   `db:"tk_field" json:"tk_field"`         // tk_data column, nil for permission-only scopes
+	Mode        string    `db:"mode" json:"mode"`                 // disclose (field returned), verify (field only compared) or mask
+	MaskVisible int       `db:"mask_visible" json:"mask_visible"` // mask mode: characters left visible per token
+	MaskTokens  string    `db:"mask_tokens" json:"mask_tokens"`   // mask mode: each (per word) or whole (one token)
+	Sensitive   bool      `db:"sensitive" json:"sensitive"`
+	Active      bool      `db:"active" json:"active"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// CreateScopeDefinitionRequest represents request to register a new scope
+type CreateScopeDefinitionRequest struct {
+	Name        string  `json:"name" binding:"required"`
+	Description string  `json:"description"`
+	TKField     *string `json:"tk_field,omitempty"`
+	Mode        string  `json:"mode,omitempty"`         // disclose (default), verify or mask; verify and mask require tk_field
+	MaskVisible *int    `json:"mask_visible,omitempty"` // default 1
+	MaskTokens  string  `json:"mask_tokens,omitempty"`  // each (default) or whole
+	Sensitive   bool    `json:"sensitive"`
+}
+
+// UpdateScopeDefinitionRequest represents request to update a scope (only provided fields are changed)
+type UpdateScopeDefinitionRequest struct {
+	Description *string `json:"description,omitempty"`
+	TKField     *string `json:"tk_field,omitempty"` // empty string clears the field
+	Mode        *string `json:"mode,omitempty"`
+	MaskVisible *int    `json:"mask_visible,omitempty"`
+	MaskTokens  *string `json:"mask_tokens,omitempty"`
+	Sensitive   *bool   `json:"sensitive,omitempty"`
+	Active      *bool   `json:"active,omitempty"`
+}
+
+// Scope modes
+const (
+	ScopeModeDisclose = "disclose" // tk_field is returned in check responses
+	ScopeModeVerify   = "verify"   // tk_field is only compared with a partner-supplied value
+	ScopeModeMask     = "mask"     // tk_field is returned partially masked
+)
+
+// Token handling of mask scopes
+const (
+	MaskTokensEach  = "each"  // every word is masked separately
+	MaskTokensWhole = "whole" // the value is masked as a single token
+)
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// ScopePackage represents a named set of scopes that can be assigned to partners
+type ScopePackage struct {
+	ID          string    `db:"id" json:"id"`
+	Name        string    `db:"name" json:"name"`
+	Description string    `db:"description" json:"description"`
+	Scopes      []string  `db:"-" json:"scopes"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// CreateScopePackageRequest represents request to create a scope package
+type CreateScopePackageRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Scopes      []string `json:"scopes" binding:"required"`
+}
+
+// UpdateScopePackageRequest represents request to update a scope package (only provided fields are changed)
+type UpdateScopePackageRequest struct {
+	Name        *string  `json:"name,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"` // replaces all scopes of the package when provided
+}
+
+// AssignScopePackageRequest represents request to assign a package to a partner (null to unassign)
+type AssignScopePackageRequest struct {
+	ScopePackageID *string `json:"scope_package_id"`
+}
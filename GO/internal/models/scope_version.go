@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Scope version change types
+const (
+	ScopeChangeBaseline = "baseline"
+	ScopeChangeCreate   = "create"
+	ScopeChangeUpdate   = "update"
+	ScopeChangeRollback = "rollback"
+	ScopeChangePackage  = "package" // package assigned, cleared or its scopes edited
+)
+
+// ScopeSnapshotItem represents one partner_access_scopes row inside a version snapshot
+type ScopeSnapshotItem struct {
+	ScopeName  string `json:"scope_name"`
+	Enabled    bool   `json:"enabled"`
+	ValidFrom  *Date  `json:"valid_from"`
+	ValidUntil *Date  `json:"valid_until"`
+}
+
+// PartnerScopeVersion represents a snapshot of a partner's scope rows and scope package after a change
+type PartnerScopeVersion struct {
+	ID                int64               `db:"id" json:"id"`
+	PartnerID         string              `db:"partner_id" json:"partner_id"`
+	Version           int                 `db:"version" json:"version"`
+	Scopes            []ScopeSnapshotItem `db:"scopes" json:"scopes"`
+	ScopePackageID    *string             `db:"scope_package_id" json:"scope_package_id"`
+	PackageScopes     []string            `db:"package_scopes" json:"package_scopes"`     // nil for versions written before the package was recorded
+	ChangedBy         *string             `db:"changed_by" json:"changed_by"`             // admin ID
+	ChangedByUsername *string             `db:"-" json:"changed_by_username,omitempty"`   // resolved from admins
+	ChangeType        string              `db:"change_type" json:"change_type"`           // baseline, create, update, rollback, package
+	RollbackOf        *int                `db:"rollback_of" json:"rollback_of,omitempty"` // restored version
+	CreatedAt         time.Time           `db:"created_at" json:"created_at"`
+}
+
+// ScopeVersionDiff represents the differences between two scope versions
+type ScopeVersionDiff struct {
+	PartnerID   string              `json:"partner_id"`
+	FromVersion int                 `json:"from_version"`
+	ToVersion   int                 `json:"to_version"`
+	Added       []ScopeSnapshotItem `json:"added"`
+	Removed     []ScopeSnapshotItem `json:"removed"`
+	Changed     []ScopeItemChange   `json:"changed"`
+	Package     *ScopePackageChange `json:"package,omitempty"` // set when the package or its scopes differ
+}
+
+// ScopePackageChange represents a change of the assigned package or of its scopes between two versions
+type ScopePackageChange struct {
+	Before        *string  `json:"before"` // scope_package_id
+	After         *string  `json:"after"`
+	AddedScopes   []string `json:"added_scopes"`
+	RemovedScopes []string `json:"removed_scopes"`
+}
+
+// ScopeItemChange represents a scope row that exists in both versions with different values
+type ScopeItemChange struct {
+	ScopeName string            `json:"scope_name"`
+	Before    ScopeSnapshotItem `json:"before"`
+	After     ScopeSnapshotItem `json:"after"`
+}
+
+// RollbackScopesRequest represents request to restore an earlier scope version
+type RollbackScopesRequest struct {
+	Version int `json:"version" binding:"required"`
+}
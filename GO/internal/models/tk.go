@@ -4,19 +4,24 @@ import "time"
 
 // TKData represents worker data (Tenaga Kerja)
 type TKData struct {
-	NIK               string    `db:"nik" json:"nik"`
-	Nama              string    `db:"nama" json:"nama"`
-	TanggalLahir      time.Time `db:"tanggal_lahir" json:"-"`           // Internal use only
-	TanggalLahirStr   string    `db:"-" json:"tanggal_lahir,omitempty"` // For JSON response
-	Alamat            *string   `db:"alamat" json:"alamat,omitempty"`
-	StatusKepesertaan string    `db:"status_kepesertaan" json:"status_kepesertaan"` // aktif, nonaktif, unknown
-	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+	NIK               string                 `db:"nik" json:"nik"`
+	Nama              string                 `db:"nama" json:"nama"`
+	TanggalLahir      time.Time              `db:"tanggal_lahir" json:"-"`           // Internal use only
+	TanggalLahirStr   string                 `db:"-" json:"tanggal_lahir,omitempty"` // For JSON response
+	Alamat            *string                `db:"alamat" json:"alamat,omitempty"`
+	StatusKepesertaan string                 `db:"status_kepesertaan" json:"status_kepesertaan"` // aktif, nonaktif, unknown
+	UpdatedAt         time.Time              `db:"updated_at" json:"updated_at"`
+	Fields            map[string]interface{} `db:"-" json:"-"` // all tk_data columns (checking only), used by scope filtering
 }
 
 // CheckTKRequest represents request to check TK status
 type CheckTKRequest struct {
 	NIK          string `json:"nik" binding:"required"`
 	TanggalLahir string `json:"tanggal_lahir" binding:"required"` // Format: YYYY-MM-DD
+	AsOf         string `json:"as_of,omitempty"`                  // Optional, YYYY-MM-DD (requires status_history scope)
+	Purpose      string `json:"purpose" binding:"required"`       // purpose code from the partner contract
+	ConsentRef   string `json:"consent_ref,omitempty"`            // Optional reference to the data subject's consent
+	AuditID      string `json:"-"`                                // audit log id chosen by the caller (bulk jobs), generated when empty
 }
 
 // CheckTKResponse represents response for TK check (dynamic based on scopes)
@@ -38,3 +43,89 @@ type UpdateTKDataRequest struct {
 	Alamat            string `json:"alamat,omitempty"`
 	StatusKepesertaan string `json:"status_kepesertaan,omitempty" binding:"omitempty,oneof=aktif nonaktif unknown"`
 }
+
+// TKListResponse represents a paginated list of TK data
+type TKListResponse struct {
+	Items      []*TKData  `json:"items"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// Pagination represents page-based pagination metadata
+type Pagination struct {
+	Page       int `json:"page"`
+	Limit      int `json:"limit"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// BatchCheckTKRequest represents request to check multiple TK records at once
+type BatchCheckTKRequest struct {
+	Items      []CheckTKRequest `json:"items" binding:"required"`
+	Purpose    string           `json:"purpose,omitempty"`     // default for items without purpose
+	ConsentRef string           `json:"consent_ref,omitempty"` // default for items without consent_ref
+}
+
+// BatchCheckTKItemResult represents the result of a single item in a batch check
+type BatchCheckTKItemResult struct {
+	Index  int             `json:"index"`
+	NIK    string          `json:"nik"`
+	Status string          `json:"status"` // found, not_found, invalid
+	Error  string          `json:"error,omitempty"`
+	Data   CheckTKResponse `json:"data,omitempty"`
+}
+
+// BatchCheckTKResponse represents response for a batch TK check (results keep request order)
+type BatchCheckTKResponse struct {
+	Total    int                      `json:"total"`
+	Found    int                      `json:"found"`
+	NotFound int                      `json:"not_found"`
+	Invalid  int                      `json:"invalid"`
+	Results  []BatchCheckTKItemResult `json:"results"`
+}
+
+// Batch check item status values
+const (
+	BatchItemFound    = "found"
+	BatchItemNotFound = "not_found"
+	BatchItemInvalid  = "invalid"
+)
+
+// VerifyTKRequest represents request to verify partner-held attributes without disclosing TK data
+type VerifyTKRequest struct {
+	NIK          string  `json:"nik" binding:"required"`
+	TanggalLahir string  `json:"tanggal_lahir" binding:"required"` // Format: YYYY-MM-DD
+	Nama         string  `json:"nama" binding:"required"`          // requires a verify scope on nama
+	Alamat       *string `json:"alamat,omitempty"`                 // Optional, requires a verify scope on alamat
+	Purpose      string  `json:"purpose" binding:"required"`       // purpose code from the partner contract
+	ConsentRef   string  `json:"consent_ref,omitempty"`            // Optional reference to the data subject's consent
+}
+
+// AttributeMatch represents the match result of one partner-supplied attribute
+type AttributeMatch struct {
+	Result string   `json:"result"`          // exact, fuzzy, mismatch, no_data
+	Score  *float64 `json:"score,omitempty"` // similarity 0-1, fuzzy only
+}
+
+// VerifyTKResponse represents response for a verify-only check (stored values are never returned)
+type VerifyTKResponse struct {
+	NIK        string                    `json:"nik"`
+	Found      bool                      `json:"found"`
+	ResultCode string                    `json:"result_code,omitempty"` // result_detail scope only
+	Matches    map[string]AttributeMatch `json:"matches,omitempty"`     // keyed by tk_data field
+}
+
+// Attribute match results
+const (
+	MatchExact    = "exact"
+	MatchFuzzy    = "fuzzy"
+	MatchMismatch = "mismatch"
+	MatchNoData   = "no_data" // no stored value to compare with
+)
+
+// Check result codes (always audited, returned as result_code with the result_detail scope)
+const (
+	ResultNIKNotFound  = "NIK_NOT_FOUND"
+	ResultDOBMismatch  = "DOB_MISMATCH"
+	ResultMatched      = "MATCHED"
+	ResultDataInactive = "DATA_INACTIVE" // NIK and DOB match, status_kepesertaan is nonaktif
+)
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// TKStatusHistory represents a change of status_kepesertaan for a worker
+type TKStatusHistory struct {
+	ID        int64     `db:"id" json:"id"`
+	NIK       string    `db:"nik" json:"nik"`
+	OldStatus *string   `db:"old_status" json:"old_status"` // nil for the first known status
+	NewStatus string    `db:"new_status" json:"new_status"`
+	ChangedAt time.Time `db:"changed_at" json:"changed_at"`
+}
+
+// TKStatusTimeline represents the participation status timeline of a worker
+type TKStatusTimeline struct {
+	NIK           string            `json:"nik"`
+	CurrentStatus *string           `json:"current_status"` // nil if the worker no longer exists in tk_data
+	Timeline      []TKStatusHistory `json:"timeline"`       // oldest first
+}
+
+// StatusAsOfQuery represents a request for the participation status of a NIK on a given date
+type StatusAsOfQuery struct {
+	NIK  string
+	AsOf time.Time
+}
+
+// Key returns the lookup key used for as-of results
+func (q StatusAsOfQuery) Key() string {
+	return q.NIK + "|" + q.AsOf.Format("2006-01-02")
+}
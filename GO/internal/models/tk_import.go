@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// TKImportRow represents a validated row of a TK data import file
+type TKImportRow struct {
+	Row               int       `json:"row"`
+	NIK               string    `json:"nik"`
+	Nama              string    `json:"nama"`
+	TanggalLahir      time.Time `json:"-"`
+	Alamat            string    `json:"alamat,omitempty"`
+	StatusKepesertaan string    `json:"status_kepesertaan"`
+}
+
+// TKImportRowResult represents the outcome of a single import row
+type TKImportRowResult struct {
+	Row    int    `json:"row"` // Row number in the file (header = row 1)
+	NIK    string `json:"nik"`
+	Status string `json:"status"` // inserted, updated, unchanged, rejected
+	Reason string `json:"reason,omitempty"`
+}
+
+// TKImportReport represents the validation/upsert report of an import
+type TKImportReport struct {
+	DryRun    bool                `json:"dry_run"`
+	Total     int                 `json:"total"`
+	Inserted  int                 `json:"inserted"`
+	Updated   int                 `json:"updated"`
+	Unchanged int                 `json:"unchanged"`
+	Rejected  int                 `json:"rejected"`
+	Rows      []TKImportRowResult `json:"rows"`
+}
+
+// Import row status values
+const (
+	ImportRowInserted  = "inserted"
+	ImportRowUpdated   = "updated"
+	ImportRowUnchanged = "unchanged"
+	ImportRowRejected  = "rejected"
+)
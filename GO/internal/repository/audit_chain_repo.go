@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/username/go-gin-backend/internal/models"
+)
+
+// StreamChain passes the chained audit logs (all partners when partnerID is empty) to fn,
+// ordered by partner and chain position. fromSeq and toSeq bound the chain positions (0 = unbounded).
+func (r *AuditRepository) StreamChain(ctx context.Context, partnerID string, fromSeq, toSeq int64, fn func(*models.AuditChainRow) error) error {
+	query := `SELECT id, partner_id, user_id, nik, scopes_used, request_payload, response_payload, result_code, purpose, consent_ref, api_key_id,
+	                 created_at, chain_seq, prev_hash, row_hash
+	          FROM audit_logs
+	          WHERE chain_seq IS NOT NULL AND ($1 = '' OR partner_id::text = $1)
+	            AND ($2 = 0 OR chain_seq >= $2) AND ($3 = 0 OR chain_seq <= $3)
+	          ORDER BY partner_id, chain_seq`
+
+	rows, err := r.DB.QueryContext(ctx, query, partnerID, fromSeq, toSeq)
+	if err != nil {
+		return fmt.Errorf("failed to get audit chain: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row models.AuditChainRow
+		if err := rows.Scan(
+			&row.ID, &row.PartnerID, &row.UserID, &row.NIK, &row.ScopesUsed, &row.RequestPayload, &row.ResponsePayload,
+			&row.ResultCode, &row.Purpose, &row.ConsentRef, &row.APIKeyID, &row.CreatedAt, &row.ChainSeq, &row.PrevHash, &row.RowHash,
+		); err != nil {
+			return fmt.Errorf("failed to scan audit chain row: %w", err)
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// CountUnchained counts the audit logs written before the hash chain (V17)
+func (r *AuditRepository) CountUnchained(ctx context.Context, partnerID string) (int64, error) {
+	var count int64
+	err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_logs
+	          WHERE chain_seq IS NULL AND ($1 = '' OR partner_id::text = $1)`, partnerID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unchained audit logs: %w", err)
+	}
+	return count, nil
+}
+
+// GetChainHeads retrieves the chain heads (all partners when partnerID is empty)
+func (r *AuditRepository) GetChainHeads(ctx context.Context, partnerID string) ([]*models.AuditChainHead, error) {
+	return r.queryChainHeads(ctx, `SELECT partner_id, last_seq, last_hash, purged_seq, purged_hash, updated_at FROM audit_chain_heads
+	          WHERE $1 = '' OR partner_id::text = $1
+	          ORDER BY partner_id`, partnerID)
+}
+
+// GetUncheckpointedHeads retrieves the chain heads that moved since their latest checkpoint
+func (r *AuditRepository) GetUncheckpointedHeads(ctx context.Context) ([]*models.AuditChainHead, error) {
+	return r.queryChainHeads(ctx, `SELECT h.partner_id, h.last_seq, h.last_hash, h.purged_seq, h.purged_hash, h.updated_at
+	          FROM audit_chain_heads h
+	          WHERE h.last_seq > COALESCE((SELECT MAX(c.chain_seq) FROM audit_chain_checkpoints c
+	                                       WHERE c.partner_id = h.partner_id), 0)
+	          ORDER BY h.partner_id`)
+}
+
+// queryChainHeads runs a chain head SELECT (partner_id, last_seq, last_hash, purged_seq, purged_hash, updated_at)
+func (r *AuditRepository) queryChainHeads(ctx context.Context, query string, args ...interface{}) ([]*models.AuditChainHead, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit chain heads: %w", err)
+	}
+	defer rows.Close()
+
+	heads := []*models.AuditChainHead{}
+	for rows.Next() {
+		var head models.AuditChainHead
+		if err := rows.Scan(&head.PartnerID, &head.LastSeq, &head.LastHash, &head.PurgedSeq, &head.PurgedHash, &head.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit chain head: %w", err)
+		}
+		heads = append(heads, &head)
+	}
+
+	return heads, rows.Err()
+}
+
+// CreateCheckpoint stores a signed checkpoint; an existing checkpoint at the same position is kept
+func (r *AuditRepository) CreateCheckpoint(ctx context.Context, cp *models.AuditChainCheckpoint) error {
+	_, err := r.DB.ExecContext(ctx, `INSERT INTO audit_chain_checkpoints (partner_id, chain_seq, row_hash, key_id, signature)
+	          VALUES ($1, $2, $3, $4, $5)
+	          ON CONFLICT (partner_id, chain_seq) DO NOTHING`,
+		cp.PartnerID, cp.ChainSeq, cp.RowHash, cp.KeyID, cp.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to create audit checkpoint: %w", err)
+	}
+	return nil
+}
+
+// GetCheckpoints retrieves the checkpoints (all partners when partnerID is empty)
+func (r *AuditRepository) GetCheckpoints(ctx context.Context, partnerID string) ([]*models.AuditChainCheckpoint, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT id, partner_id, chain_seq, row_hash, key_id, signature, created_at
+	          FROM audit_chain_checkpoints
+	          WHERE $1 = '' OR partner_id::text = $1
+	          ORDER BY partner_id, chain_seq`, partnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	checkpoints := []*models.AuditChainCheckpoint{}
+	for rows.Next() {
+		var cp models.AuditChainCheckpoint
+		if err := rows.Scan(&cp.ID, &cp.PartnerID, &cp.ChainSeq, &cp.RowHash, &cp.KeyID, &cp.Signature, &cp.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit checkpoint: %w", err)
+		}
+		checkpoints = append(checkpoints, &cp)
+	}
+
+	return checkpoints, rows.Err()
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/username/go-gin-backend/internal/models"
+)
+
+// auditPartitionLayout is the month suffix of the audit_logs partition names
+const auditPartitionLayout = "200601"
+
+// auditMaintenanceLockKey is the advisory lock held during audit partition maintenance
+const auditMaintenanceLockKey = 7_317_001
+
+// ErrAuditMaintenanceRunning is returned when another process holds the audit maintenance lock
+var ErrAuditMaintenanceRunning = errors.New("audit partition maintenance is already running")
+
+// AuditPartitionName returns the name of the audit_logs partition holding the month of t (UTC)
+func AuditPartitionName(t time.Time) string {
+	return "audit_logs_p" + t.UTC().Format(auditPartitionLayout)
+}
+
+// auditPartitionMonth returns the first instant (UTC) of the month of a partition name
+func auditPartitionMonth(name string) (time.Time, error) {
+	return time.Parse(auditPartitionLayout, name[len("audit_logs_p"):])
+}
+
+// LockAuditMaintenance takes the audit maintenance advisory lock on a dedicated connection so that
+// a single process (server or pksctl) maintains the partitions; call unlock when done
+func (r *AuditRepository) LockAuditMaintenance(ctx context.Context) (func(), error) {
+	conn, err := r.DB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, auditMaintenanceLockKey).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to take audit maintenance lock: %w", err)
+	}
+	if !locked {
+		conn.Close()
+		return nil, ErrAuditMaintenanceRunning
+	}
+
+	return func() {
+		conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, auditMaintenanceLockKey)
+		conn.Close()
+	}, nil
+}
+
+// CreatePartition creates the partition of the month of t unless it exists and reports whether it
+// was created and how many rows of that month it took over from audit_logs_default. Postgres refuses
+// a partition whose rows are already in the default partition, so in that case the partition is
+// created as a plain table, the rows are moved into it and it is attached, all in one transaction.
+func (r *AuditRepository) CreatePartition(ctx context.Context, t time.Time) (bool, int64, error) {
+	from := time.Date(t.UTC().Year(), t.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	name := AuditPartitionName(from)
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists); err != nil {
+		return false, 0, fmt.Errorf("failed to check audit partition %s: %w", name, err)
+	}
+	if exists {
+		return false, 0, nil
+	}
+
+	// Writes to the default partition wait until the month is attached, so none of its rows is missed
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE audit_logs_default IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return false, 0, fmt.Errorf("failed to lock default audit partition: %w", err)
+	}
+	var inDefault bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM audit_logs_default WHERE created_at >= $1 AND created_at < $2)`,
+		from, to).Scan(&inDefault); err != nil {
+		return false, 0, fmt.Errorf("failed to check default audit partition: %w", err)
+	}
+
+	// Bounds are UTC timestamps: both are generated here, never taken from input
+	bounds := fmt.Sprintf(`FROM (%s) TO (%s)`, pq.QuoteLiteral(from.Format(time.RFC3339)), pq.QuoteLiteral(to.Format(time.RFC3339)))
+
+	if !inDefault {
+		if _, err := tx.ExecContext(ctx, `CREATE TABLE `+pq.QuoteIdentifier(name)+` PARTITION OF audit_logs FOR VALUES `+bounds); err != nil {
+			return false, 0, fmt.Errorf("failed to create audit partition %s: %w", name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return false, 0, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return true, 0, nil
+	}
+
+	// Same columns as audit_logs; ATTACH adds the indexes and checks the bounds
+	if _, err := tx.ExecContext(ctx, `CREATE TABLE `+pq.QuoteIdentifier(name)+` (LIKE audit_logs INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`); err != nil {
+		return false, 0, fmt.Errorf("failed to create audit partition %s: %w", name, err)
+	}
+	moveQuery := `WITH moved AS (
+	                  DELETE FROM audit_logs_default WHERE created_at >= $1 AND created_at < $2 RETURNING *
+	              )
+	              INSERT INTO ` + pq.QuoteIdentifier(name) + ` SELECT * FROM moved`
+	result, err := tx.ExecContext(ctx, moveQuery, from, to)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to move audit logs into partition %s: %w", name, err)
+	}
+	moved, _ := result.RowsAffected()
+	if _, err := tx.ExecContext(ctx, `ALTER TABLE audit_logs ATTACH PARTITION `+pq.QuoteIdentifier(name)+` FOR VALUES `+bounds); err != nil {
+		return false, 0, fmt.Errorf("failed to attach audit partition %s: %w", name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, moved, nil
+}
+
+// ListPartitions retrieves the monthly audit partitions, attached or detached (being archived)
+func (r *AuditRepository) ListPartitions(ctx context.Context) ([]*models.AuditPartition, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT c.relname,
+	                 EXISTS (SELECT 1 FROM pg_inherits i WHERE i.inhrelid = c.oid AND i.inhparent = 'audit_logs'::regclass),
+	                 c.reltuples::bigint
+	          FROM pg_class c
+	          JOIN pg_namespace n ON n.oid = c.relnamespace
+	          WHERE c.relkind = 'r' AND n.nspname = current_schema() AND c.relname ~ '^audit_logs_p[0-9]{6}$'
+	          ORDER BY c.relname`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit partitions: %w", err)
+	}
+	defer rows.Close()
+
+	partitions := []*models.AuditPartition{}
+	for rows.Next() {
+		var p models.AuditPartition
+		if err := rows.Scan(&p.Name, &p.Attached, &p.RowsEstimate); err != nil {
+			return nil, fmt.Errorf("failed to scan audit partition: %w", err)
+		}
+		if p.From, err = auditPartitionMonth(p.Name); err != nil {
+			continue // not one of ours (e.g. audit_logs_p999999)
+		}
+		p.To = p.From.AddDate(0, 1, 0)
+		partitions = append(partitions, &p)
+	}
+
+	return partitions, rows.Err()
+}
+
+// CountDefaultPartition counts the audit logs stored outside every monthly partition
+func (r *AuditRepository) CountDefaultPartition(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_logs_default`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count default audit partition: %w", err)
+	}
+	return count, nil
+}
+
+// DetachPartition detaches a partition from audit_logs; its rows leave every reader query
+func (r *AuditRepository) DetachPartition(ctx context.Context, name string) error {
+	if _, err := r.DB.ExecContext(ctx, `ALTER TABLE audit_logs DETACH PARTITION `+pq.QuoteIdentifier(name)); err != nil {
+		return fmt.Errorf("failed to detach audit partition %s: %w", name, err)
+	}
+	return nil
+}
+
+// StreamPartition passes the rows of a (detached) partition to fn in chain order.
+// Rows written before the hash chain (V17) have a zero chain_seq and empty hashes.
+func (r *AuditRepository) StreamPartition(ctx context.Context, name string, fn func(*models.AuditChainRow) error) error {
+	query := `SELECT id, partner_id, user_id, nik, scopes_used, request_payload, response_payload, result_code, purpose, consent_ref, api_key_id,
+	                 created_at, COALESCE(chain_seq, 0), COALESCE(prev_hash, ''), COALESCE(row_hash, '')
+	          FROM ` + pq.QuoteIdentifier(name) + `
+	          ORDER BY partner_id, chain_seq NULLS FIRST, created_at, id`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to read audit partition %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row models.AuditChainRow
+		if err := rows.Scan(
+			&row.ID, &row.PartnerID, &row.UserID, &row.NIK, &row.ScopesUsed, &row.RequestPayload, &row.ResponsePayload,
+			&row.ResultCode, &row.Purpose, &row.ConsentRef, &row.APIKeyID, &row.CreatedAt, &row.ChainSeq, &row.PrevHash, &row.RowHash,
+		); err != nil {
+			return fmt.Errorf("failed to scan audit partition row: %w", err)
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// DropPartition drops a detached partition after moving, per partner, the purged position of the
+// chain in audit_chain_heads so that the verifier starts the chain after it. created_at does not
+// follow chain_seq (replayed spill files, several server instances), so attached partitions may
+// still hold lower positions than the dropped one: only the prefix with no row left in audit_logs
+// is purged, up to the position before the lowest remaining row (whose prev_hash is the hash of
+// that position), or the last position of the dropped partition when no row is left.
+func (r *AuditRepository) DropPartition(ctx context.Context, name string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `WITH dropped AS (
+	              SELECT DISTINCT ON (partner_id) partner_id, chain_seq, row_hash
+	              FROM `+pq.QuoteIdentifier(name)+`
+	              WHERE chain_seq IS NOT NULL
+	              ORDER BY partner_id, chain_seq DESC
+	          ), remaining AS (
+	              SELECT DISTINCT ON (a.partner_id) a.partner_id, a.chain_seq, a.prev_hash
+	              FROM audit_logs a
+	              WHERE a.chain_seq IS NOT NULL AND a.partner_id IN (SELECT partner_id FROM dropped)
+	              ORDER BY a.partner_id, a.chain_seq, a.id
+	          ), purged AS (
+	              SELECT d.partner_id,
+	                     CASE WHEN r.partner_id IS NULL THEN d.chain_seq ELSE r.chain_seq - 1 END AS chain_seq,
+	                     CASE WHEN r.partner_id IS NULL THEN d.row_hash ELSE r.prev_hash END AS row_hash
+	              FROM dropped d
+	              LEFT JOIN remaining r ON r.partner_id = d.partner_id
+	          )
+	          UPDATE audit_chain_heads h SET purged_seq = p.chain_seq, purged_hash = p.row_hash
+	          FROM purged p
+	          WHERE h.partner_id = p.partner_id AND p.chain_seq > h.purged_seq`)
+	if err != nil {
+		return fmt.Errorf("failed to record purged audit chain positions: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DROP TABLE `+pq.QuoteIdentifier(name)); err != nil {
+		return fmt.Errorf("failed to drop audit partition %s: %w", name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
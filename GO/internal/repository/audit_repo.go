@@ -4,9 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/pkg/utils"
 )
 
 // AuditRepository handles database operations for audit logs
@@ -19,37 +25,263 @@ func NewAuditRepository(db *sql.DB) *AuditRepository {
 	return &AuditRepository{DB: db}
 }
 
+// auditInsertQuery inserts audit logs with their hash chain link; each row has auditInsertParams parameters
+const auditInsertQuery = `INSERT INTO audit_logs (id, partner_id, user_id, nik, scopes_used, request_payload, response_payload,
+	                                    result_code, purpose, consent_ref, api_key_id, created_at, chain_seq, prev_hash, row_hash)
+	          VALUES %s`
+
+// auditInsertParams is the number of query parameters per inserted audit log
+const auditInsertParams = 15
+
+// MaxAuditBatchSize is the largest CreateBatch that fits the 65535 parameters of a Postgres query
+const MaxAuditBatchSize = 65535 / auditInsertParams
+
+// ErrAuditRowRejected marks a CreateBatch failure caused by the content of an audit log (a value
+// the database refuses or a payload that cannot be encoded) rather than by the database itself
+var ErrAuditRowRejected = errors.New("audit log rejected")
+
+// auditChainRecord returns the hashed content of an audit log as it will be stored: payloads as
+// JSON, empty codes as NULL and created_at truncated to the microsecond precision of Postgres
+func auditChainRecord(entry *models.CreateAuditLogRequest) (utils.AuditChainRecord, error) {
+	rec := utils.AuditChainRecord{
+		ID:         entry.ID,
+		PartnerID:  entry.PartnerID,
+		UserID:     entry.UserID,
+		NIK:        entry.NIK,
+		ResultCode: nullIfEmpty(entry.ResultCode),
+		Purpose:    nullIfEmpty(entry.Purpose),
+		ConsentRef: nullIfEmpty(entry.ConsentRef),
+		APIKeyID:   nullIfEmpty(entry.APIKeyID),
+		CreatedAt:  entry.CreatedAt,
+	}
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now()
+	}
+	rec.CreatedAt = rec.CreatedAt.Truncate(time.Microsecond)
+
+	var err error
+	if rec.ScopesUsed, err = json.Marshal(entry.ScopesUsed); err != nil {
+		return rec, fmt.Errorf("failed to marshal scopes: %w", err)
+	}
+	if rec.RequestPayload, err = json.Marshal(entry.RequestPayload); err != nil {
+		return rec, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if rec.ResponsePayload, err = json.Marshal(entry.ResponsePayload); err != nil {
+		return rec, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return rec, nil
+}
+
+// nullIfEmpty returns nil for an empty string
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
 // Create creates a new audit log entry
-func (r *AuditRepository) Create(ctx context.Context, partnerID string, userID *string, nik string, scopesUsed, request, response interface{}) error {
-	query := `INSERT INTO audit_logs (partner_id, user_id, nik, scopes_used, request_payload, response_payload) 
-	          VALUES ($1, $2, $3, $4, $5, $6)`
+func (r *AuditRepository) Create(ctx context.Context, entry *models.CreateAuditLogRequest) error {
+	if err := r.CreateBatch(ctx, []*models.CreateAuditLogRequest{entry}); err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	return nil
+}
 
-	scopesJSON, err := json.Marshal(scopesUsed)
-	if err != nil {
-		return fmt.Errorf("failed to marshal scopes: %w", err)
+// CreateBatch inserts audit logs in one transaction (all or nothing), appending each one to its
+// partner's hash chain. The partners' chain heads are locked (FOR UPDATE) for the transaction, so
+// concurrent writers for the same partner are serialized and the chain has no forks. Entries whose
+// id is already stored (a spill file replayed twice, a check job chunk checked again) are skipped
+// without taking a chain position; the ids are looked up once the chain heads are locked, so an
+// entry committed by a concurrent writer is seen as well.
+// Failures caused by an entry's content wrap ErrAuditRowRejected.
+func (r *AuditRepository) CreateBatch(ctx context.Context, entries []*models.CreateAuditLogRequest) error {
+	if len(entries) == 0 {
+		return nil
 	}
 
-	requestJSON, err := json.Marshal(request)
+	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	responseJSON, err := json.Marshal(response)
+	heads, err := lockChainHeads(ctx, tx, entries)
 	if err != nil {
-		return fmt.Errorf("failed to marshal response: %w", err)
+		return err
 	}
 
-	_, err = r.DB.ExecContext(ctx, query, partnerID, userID, nik, scopesJSON, requestJSON, responseJSON)
+	pending, err := newAuditEntries(ctx, tx, entries)
 	if err != nil {
-		return fmt.Errorf("failed to create audit log: %w", err)
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	rows := make([]string, 0, len(pending))
+	args := make([]interface{}, 0, len(pending)*auditInsertParams)
+	advanced := make(map[string]bool, len(heads))
+	for _, entry := range pending {
+		rec, err := auditChainRecord(entry)
+		if err != nil {
+			return fmt.Errorf("%w: audit log %s: %v", ErrAuditRowRejected, entry.ID, err)
+		}
+
+		head := heads[entry.PartnerID]
+		if head == nil {
+			return fmt.Errorf("audit log %s: no chain head for partner %s", entry.ID, entry.PartnerID)
+		}
+		rec.ChainSeq = head.LastSeq + 1
+		rec.PrevHash = head.LastHash
+		hash, err := utils.AuditRowHash(rec)
+		if err != nil {
+			return fmt.Errorf("%w: audit log %s: %v", ErrAuditRowRejected, entry.ID, err)
+		}
+		head.LastSeq, head.LastHash = rec.ChainSeq, hash
+		advanced[entry.PartnerID] = true
+
+		p := make([]interface{}, auditInsertParams)
+		for i := range p {
+			p[i] = len(args) + i + 1
+		}
+		rows = append(rows, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", p...))
+		args = append(args, rec.ID, rec.PartnerID, rec.UserID, rec.NIK, []byte(rec.ScopesUsed), []byte(rec.RequestPayload),
+			[]byte(rec.ResponsePayload), rec.ResultCode, rec.Purpose, rec.ConsentRef, rec.APIKeyID, rec.CreatedAt, rec.ChainSeq, rec.PrevHash, hash)
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(auditInsertQuery, strings.Join(rows, ",\n\t                 ")), args...); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && (pqErr.Code.Class() == "22" || pqErr.Code.Class() == "23") { // data exception, integrity violation
+			return fmt.Errorf("%w: %s", ErrAuditRowRejected, pqErr.Message)
+		}
+		return fmt.Errorf("failed to insert audit logs: %w", err)
+	}
+
+	for _, head := range heads {
+		if !advanced[head.PartnerID] {
+			continue // every entry of the partner was already stored
+		}
+		_, err := tx.ExecContext(ctx, `UPDATE audit_chain_heads SET last_seq = $2, last_hash = $3, updated_at = NOW()
+		          WHERE partner_id = $1`, head.PartnerID, head.LastSeq, head.LastHash)
+		if err != nil {
+			return fmt.Errorf("failed to update audit chain head: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit logs: %w", err)
 	}
 
 	return nil
 }
 
+// newAuditEntries drops the entries whose id is already in audit_logs
+func newAuditEntries(ctx context.Context, tx *sql.Tx, entries []*models.CreateAuditLogRequest) ([]*models.CreateAuditLogRequest, error) {
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM audit_logs WHERE id = ANY($1::uuid[])`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan audit log id: %w", err)
+		}
+		existing[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(existing) == 0 {
+		return entries, nil
+	}
+
+	pending := make([]*models.CreateAuditLogRequest, 0, len(entries)-len(existing))
+	for _, entry := range entries {
+		if !existing[entry.ID] {
+			pending = append(pending, entry)
+		}
+	}
+	return pending, nil
+}
+
+// lockChainHeads creates the missing chain heads of the entries' partners and locks them for the
+// transaction, always in partner_id order so concurrent batches cannot deadlock
+func lockChainHeads(ctx context.Context, tx *sql.Tx, entries []*models.CreateAuditLogRequest) (map[string]*models.AuditChainHead, error) {
+	seen := make(map[string]bool)
+	var partnerIDs []string
+	for _, entry := range entries {
+		if !seen[entry.PartnerID] {
+			seen[entry.PartnerID] = true
+			partnerIDs = append(partnerIDs, entry.PartnerID)
+		}
+	}
+	sort.Strings(partnerIDs)
+
+	_, err := tx.ExecContext(ctx, `INSERT INTO audit_chain_heads (partner_id)
+	          SELECT unnest($1::uuid[]) ON CONFLICT (partner_id) DO NOTHING`, pq.Array(partnerIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit chain heads: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT partner_id, last_seq, last_hash, updated_at FROM audit_chain_heads
+	          WHERE partner_id = ANY($1::uuid[]) ORDER BY partner_id FOR UPDATE`, pq.Array(partnerIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock audit chain heads: %w", err)
+	}
+	defer rows.Close()
+
+	heads := make(map[string]*models.AuditChainHead, len(partnerIDs))
+	for rows.Next() {
+		var head models.AuditChainHead
+		if err := rows.Scan(&head.PartnerID, &head.LastSeq, &head.LastHash, &head.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit chain head: %w", err)
+		}
+		heads[head.PartnerID] = &head
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(heads) != len(partnerIDs) {
+		return nil, fmt.Errorf("failed to lock audit chain heads: %d of %d found", len(heads), len(partnerIDs))
+	}
+
+	return heads, nil
+}
+
+// GetByID retrieves a single audit log (nil when not found)
+func (r *AuditRepository) GetByID(ctx context.Context, id string) (*models.AuditLog, error) {
+	query := `SELECT id, partner_id, user_id, nik, scopes_used, request_payload, response_payload, result_code, purpose, consent_ref, api_key_id, created_at
+	          FROM audit_logs
+	          WHERE id = $1`
+
+	var log models.AuditLog
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(
+		&log.ID, &log.PartnerID, &log.UserID, &log.NIK,
+		&log.ScopesUsed, &log.RequestPayload, &log.ResponsePayload, &log.ResultCode, &log.Purpose, &log.ConsentRef, &log.APIKeyID, &log.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+
+	return &log, nil
+}
+
 // GetByPartnerID retrieves audit logs for a partner
 func (r *AuditRepository) GetByPartnerID(ctx context.Context, partnerID string, limit, offset int) ([]*models.AuditLog, error) {
-	query := `SELECT id, partner_id, user_id, nik, scopes_used, request_payload, response_payload, created_at
+	query := `SELECT id, partner_id, user_id, nik, scopes_used, request_payload, response_payload, result_code, purpose, consent_ref, api_key_id, created_at
 	          FROM audit_logs
 	          WHERE partner_id = $1
 	          ORDER BY created_at DESC
@@ -66,7 +298,7 @@ func (r *AuditRepository) GetByPartnerID(ctx context.Context, partnerID string,
 		var log models.AuditLog
 		if err := rows.Scan(
 			&log.ID, &log.PartnerID, &log.UserID, &log.NIK,
-			&log.ScopesUsed, &log.RequestPayload, &log.ResponsePayload, &log.CreatedAt,
+			&log.ScopesUsed, &log.RequestPayload, &log.ResponsePayload, &log.ResultCode, &log.Purpose, &log.ConsentRef, &log.APIKeyID, &log.CreatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan audit log: %w", err)
 		}
@@ -76,15 +308,15 @@ func (r *AuditRepository) GetByPartnerID(ctx context.Context, partnerID string,
 	return logs, nil
 }
 
-// GetByNIK retrieves audit logs for a specific NIK
-func (r *AuditRepository) GetByNIK(ctx context.Context, nik string, limit, offset int) ([]*models.AuditLog, error) {
-	query := `SELECT id, partner_id, user_id, nik, scopes_used, request_payload, response_payload, created_at
+// GetByNIK retrieves audit logs for a specific NIK, stored in plaintext or as nikToken
+func (r *AuditRepository) GetByNIK(ctx context.Context, nik, nikToken string, limit, offset int) ([]*models.AuditLog, error) {
+	query := `SELECT id, partner_id, user_id, nik, scopes_used, request_payload, response_payload, result_code, purpose, consent_ref, api_key_id, created_at
 	          FROM audit_logs
-	          WHERE nik = $1
+	          WHERE nik IN ($1, $2)
 	          ORDER BY created_at DESC
-	          LIMIT $2 OFFSET $3`
+	          LIMIT $3 OFFSET $4`
 
-	rows, err := r.DB.QueryContext(ctx, query, nik, limit, offset)
+	rows, err := r.DB.QueryContext(ctx, query, nik, nikToken, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get audit logs: %w", err)
 	}
@@ -95,7 +327,7 @@ func (r *AuditRepository) GetByNIK(ctx context.Context, nik string, limit, offse
 		var log models.AuditLog
 		if err := rows.Scan(
 			&log.ID, &log.PartnerID, &log.UserID, &log.NIK,
-			&log.ScopesUsed, &log.RequestPayload, &log.ResponsePayload, &log.CreatedAt,
+			&log.ScopesUsed, &log.RequestPayload, &log.ResponsePayload, &log.ResultCode, &log.Purpose, &log.ConsentRef, &log.APIKeyID, &log.CreatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan audit log: %w", err)
 		}
@@ -104,3 +336,150 @@ func (r *AuditRepository) GetByNIK(ctx context.Context, nik string, limit, offse
 
 	return logs, nil
 }
+
+// GetAccessRecordsByNIK retrieves every audit log of a NIK (stored in plaintext or as nikToken) with the
+// partner that made the check, oldest first
+func (r *AuditRepository) GetAccessRecordsByNIK(ctx context.Context, nik, nikToken string) ([]*models.AccessRecord, error) {
+	query := `SELECT a.id, a.partner_id, COALESCE(p.company_name, ''), COALESCE(p.company_id, ''),
+	                 a.scopes_used, a.response_payload, a.purpose, a.consent_ref, a.result_code, a.created_at
+	          FROM audit_logs a
+	          LEFT JOIN partners p ON p.id = a.partner_id
+	          WHERE a.nik IN ($1, $2)
+	          ORDER BY a.created_at, a.id`
+
+	rows, err := r.DB.QueryContext(ctx, query, nik, nikToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access records: %w", err)
+	}
+	defer rows.Close()
+
+	records := []*models.AccessRecord{}
+	for rows.Next() {
+		var rec models.AccessRecord
+		if err := rows.Scan(
+			&rec.AuditID, &rec.PartnerID, &rec.PartnerName, &rec.CompanyID,
+			&rec.ScopesUsed, &rec.ResponsePayload, &rec.Purpose, &rec.ConsentRef, &rec.ResultCode, &rec.AccessedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan access record: %w", err)
+		}
+		records = append(records, &rec)
+	}
+
+	return records, rows.Err()
+}
+
+// auditFilterConditions builds the WHERE conditions and arguments of an audit log filter (cursor included)
+func auditFilterConditions(filter models.AuditLogFilter) ([]string, []interface{}, error) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.PartnerID != "" {
+		add("partner_id = $%d", filter.PartnerID)
+	}
+	if filter.NIK != "" {
+		args = append(args, filter.NIK, filter.NIKToken)
+		conditions = append(conditions, fmt.Sprintf("nik IN ($%d, $%d)", len(args)-1, len(args)))
+	}
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at < $%d", *filter.To)
+	}
+	if len(filter.ResultCodes) > 0 {
+		add("result_code = ANY($%d)", pq.Array(filter.ResultCodes))
+	}
+	if filter.Scope != "" {
+		scopeJSON, err := json.Marshal([]map[string]interface{}{{"scope_name": filter.Scope, "enabled": true}})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal scope filter: %w", err)
+		}
+		add("scopes_used @> $%d::jsonb", string(scopeJSON))
+	}
+	if filter.AfterCreatedAt != nil {
+		args = append(args, *filter.AfterCreatedAt, filter.AfterID)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	return conditions, args, nil
+}
+
+// auditSearchQuery returns the audit log SELECT for a filter with the given ORDER BY direction
+func auditSearchQuery(filter models.AuditLogFilter, direction string) (string, []interface{}, error) {
+	conditions, args, err := auditFilterConditions(filter)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := `SELECT id, partner_id, user_id, nik, scopes_used, request_payload, response_payload, result_code, purpose, consent_ref, api_key_id, created_at
+	          FROM audit_logs`
+	if len(conditions) > 0 {
+		query += `
+	          WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(`
+	          ORDER BY created_at %[1]s, id %[1]s`, direction)
+
+	return query, args, nil
+}
+
+// Search retrieves audit logs matching the filter, newest first (created_at DESC, id DESC).
+// Up to filter.Limit+1 rows are returned so the caller can tell whether another page exists.
+func (r *AuditRepository) Search(ctx context.Context, filter models.AuditLogFilter) ([]*models.AuditLog, error) {
+	query, args, err := auditSearchQuery(filter, "DESC")
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, filter.Limit+1)
+	query += fmt.Sprintf(`
+	          LIMIT $%d`, len(args))
+
+	logs := []*models.AuditLog{}
+	err = r.queryAuditLogs(ctx, query, args, func(log *models.AuditLog) error {
+		logs = append(logs, log)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search audit logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// Stream passes every audit log matching the filter to fn, oldest first, without loading them all in memory
+func (r *AuditRepository) Stream(ctx context.Context, filter models.AuditLogFilter, fn func(*models.AuditLog) error) error {
+	query, args, err := auditSearchQuery(filter, "ASC")
+	if err != nil {
+		return err
+	}
+
+	return r.queryAuditLogs(ctx, query, args, fn)
+}
+
+// queryAuditLogs runs an audit log SELECT and scans each row into fn
+func (r *AuditRepository) queryAuditLogs(ctx context.Context, query string, args []interface{}, fn func(*models.AuditLog) error) error {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to get audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var log models.AuditLog
+		if err := rows.Scan(
+			&log.ID, &log.PartnerID, &log.UserID, &log.NIK,
+			&log.ScopesUsed, &log.RequestPayload, &log.ResponsePayload, &log.ResultCode, &log.Purpose, &log.ConsentRef, &log.APIKeyID, &log.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		if err := fn(&log); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
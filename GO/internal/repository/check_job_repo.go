@@ -0,0 +1,354 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/username/go-gin-backend/internal/models"
+)
+
+// CheckJobRepository handles database operations for bulk check jobs
+type CheckJobRepository struct {
+	DB *sql.DB
+}
+
+// NewCheckJobRepository creates a new check job repository
+func NewCheckJobRepository(db *sql.DB) *CheckJobRepository {
+	return &CheckJobRepository{DB: db}
+}
+
+const checkJobColumns = `id, partner_id, status, file_name, purpose, consent_ref, api_key_id, total_rows, processed_rows, found_rows,
+	          not_found_rows, invalid_rows, result_columns, attempts, last_error,
+	          created_at, started_at, finished_at, updated_at`
+
+// scanCheckJob scans a check job row selected with checkJobColumns
+func scanCheckJob(row interface{ Scan(...interface{}) error }) (*models.CheckJob, error) {
+	var job models.CheckJob
+	var startedAt, finishedAt sql.NullTime
+	err := row.Scan(
+		&job.ID, &job.PartnerID, &job.Status, &job.FileName, &job.Purpose, &job.ConsentRef, &job.APIKeyID, &job.TotalRows, &job.ProcessedRows, &job.FoundRows,
+		&job.NotFoundRows, &job.InvalidRows, pq.Array(&job.ResultColumns), &job.Attempts, &job.LastError,
+		&job.CreatedAt, &startedAt, &finishedAt, &job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return &job, nil
+}
+
+// CreateWithItems creates a job and copies all of its rows in a single transaction.
+// next is called until it returns io.EOF; the job only becomes visible to workers after commit.
+func (r *CheckJobRepository) CreateWithItems(
+	ctx context.Context,
+	partnerID, apiKeyID, fileName, purpose, consentRef string,
+	next func() (nik, tanggalLahir string, err error),
+) (*models.CheckJob, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var jobID string
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO check_jobs (partner_id, file_name, status, purpose, consent_ref, api_key_id)
+		 VALUES ($1, NULLIF($2, ''), $3, $4, NULLIF($5, ''), NULLIF($6, '')::uuid) RETURNING id`,
+		partnerID, fileName, models.CheckJobQueued, purpose, consentRef, apiKeyID,
+	).Scan(&jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create check job: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("check_job_items", "job_id", "row_number", "nik", "tanggal_lahir"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare copy: %w", err)
+	}
+
+	rowNumber := 0
+	for {
+		nik, tanggalLahir, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			stmt.Close()
+			return nil, err
+		}
+		rowNumber++
+		if _, err := stmt.ExecContext(ctx, jobID, rowNumber, nik, tanggalLahir); err != nil {
+			stmt.Close()
+			return nil, fmt.Errorf("failed to copy row %d: %w", rowNumber, err)
+		}
+	}
+
+	// Flush buffered COPY data
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return nil, fmt.Errorf("failed to copy job rows: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close copy: %w", err)
+	}
+
+	query := `UPDATE check_jobs SET total_rows = $1 WHERE id = $2 RETURNING ` + checkJobColumns
+	job, err := scanCheckJob(tx.QueryRowContext(ctx, query, rowNumber, jobID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update check job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return job, nil
+}
+
+// GetByIDForPartner retrieves a job owned by the given partner (nil if not found)
+func (r *CheckJobRepository) GetByIDForPartner(ctx context.Context, id, partnerID string) (*models.CheckJob, error) {
+	query := `SELECT ` + checkJobColumns + `
+	          FROM check_jobs WHERE id = $1 AND partner_id = $2`
+
+	job, err := scanCheckJob(r.DB.QueryRowContext(ctx, query, id, partnerID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get check job: %w", err)
+	}
+
+	return job, nil
+}
+
+// ListByPartner retrieves jobs of a partner, newest first
+func (r *CheckJobRepository) ListByPartner(ctx context.Context, partnerID string, limit, offset int) ([]*models.CheckJob, error) {
+	query := `SELECT ` + checkJobColumns + `
+	          FROM check_jobs
+	          WHERE partner_id = $1
+	          ORDER BY created_at DESC
+	          LIMIT $2 OFFSET $3`
+
+	rows, err := r.DB.QueryContext(ctx, query, partnerID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*models.CheckJob
+	for rows.Next() {
+		job, err := scanCheckJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan check job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// ClaimNext leases the oldest queued job, or a running job whose lease expired
+// (e.g. the previous worker died during a restart). Returns nil if there is nothing to do.
+func (r *CheckJobRepository) ClaimNext(ctx context.Context, lease time.Duration) (*models.CheckJob, error) {
+	query := `UPDATE check_jobs
+	          SET status = $1,
+	              started_at = COALESCE(started_at, NOW()),
+	              locked_until = NOW() + make_interval(secs => $2)
+	          WHERE id = (
+	              SELECT id FROM check_jobs
+	              WHERE status = $3
+	                 OR (status = $1 AND (locked_until IS NULL OR locked_until < NOW()))
+	              ORDER BY created_at
+	              LIMIT 1
+	              FOR UPDATE SKIP LOCKED
+	          )
+	          RETURNING ` + checkJobColumns
+
+	job, err := scanCheckJob(r.DB.QueryRowContext(ctx, query,
+		models.CheckJobRunning, int(lease.Seconds()), models.CheckJobQueued,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim check job: %w", err)
+	}
+
+	return job, nil
+}
+
+// GetPendingItems retrieves the next unprocessed rows of a job in file order
+func (r *CheckJobRepository) GetPendingItems(ctx context.Context, jobID string, limit int) ([]models.CheckJobItem, error) {
+	query := `SELECT job_id, row_number, nik, tanggal_lahir, status
+	          FROM check_job_items
+	          WHERE job_id = $1 AND status = $2
+	          ORDER BY row_number
+	          LIMIT $3`
+
+	rows, err := r.DB.QueryContext(ctx, query, jobID, models.CheckJobItemPending, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.CheckJobItem
+	for rows.Next() {
+		var item models.CheckJobItem
+		if err := rows.Scan(&item.JobID, &item.RowNumber, &item.NIK, &item.TanggalLahir, &item.Status); err != nil {
+			return nil, fmt.Errorf("failed to scan job item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// SaveChunkResults stores processed rows and advances job counters atomically,
+// so a restart never double-counts or loses a chunk. The worker lease is renewed.
+func (r *CheckJobRepository) SaveChunkResults(
+	ctx context.Context,
+	jobID string,
+	items []models.CheckJobItem,
+	found, notFound, invalid int,
+	columns []string,
+	lease time.Duration,
+) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rowNumbers := make([]int64, len(items))
+	statuses := make([]string, len(items))
+	errs := make([]string, len(items))
+	results := make([]string, len(items))
+	for i, item := range items {
+		rowNumbers[i] = int64(item.RowNumber)
+		statuses[i] = item.Status
+		if item.Error != nil {
+			errs[i] = *item.Error
+		}
+		results[i] = string(item.Result)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE check_job_items AS i
+		SET status = v.status,
+		    error = NULLIF(v.error, ''),
+		    result = NULLIF(v.result, '')::jsonb,
+		    processed_at = NOW()
+		FROM (
+		    SELECT unnest($2::int[]) AS row_number,
+		           unnest($3::text[]) AS status,
+		           unnest($4::text[]) AS error,
+		           unnest($5::text[]) AS result
+		) AS v
+		WHERE i.job_id = $1 AND i.row_number = v.row_number`,
+		jobID, pq.Array(rowNumbers), pq.Array(statuses), pq.Array(errs), pq.Array(results),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save job items: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE check_jobs
+		SET processed_rows = processed_rows + $2,
+		    found_rows = found_rows + $3,
+		    not_found_rows = not_found_rows + $4,
+		    invalid_rows = invalid_rows + $5,
+		    result_columns = ARRAY(SELECT DISTINCT c FROM unnest(result_columns || $6::text[]) AS c ORDER BY c),
+		    locked_until = NOW() + make_interval(secs => $7),
+		    last_error = NULL
+		WHERE id = $1`,
+		jobID, len(items), found, notFound, invalid, pq.Array(columns), int(lease.Seconds()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update job progress: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Complete marks a job as completed and releases its lease
+func (r *CheckJobRepository) Complete(ctx context.Context, jobID string) error {
+	query := `UPDATE check_jobs SET status = $1, finished_at = NOW(), locked_until = NULL WHERE id = $2`
+
+	if _, err := r.DB.ExecContext(ctx, query, models.CheckJobCompleted, jobID); err != nil {
+		return fmt.Errorf("failed to complete check job: %w", err)
+	}
+
+	return nil
+}
+
+// RecordFailure stores a processing error. The job is retried after backoff,
+// and marked as failed once maxAttempts is reached.
+func (r *CheckJobRepository) RecordFailure(ctx context.Context, jobID, message string, maxAttempts int, backoff time.Duration) error {
+	query := `UPDATE check_jobs
+	          SET attempts = attempts + 1,
+	              last_error = $2,
+	              status = CASE WHEN attempts + 1 >= $3 THEN $4 ELSE status END,
+	              finished_at = CASE WHEN attempts + 1 >= $3 THEN NOW() ELSE finished_at END,
+	              locked_until = NOW() + make_interval(secs => $5)
+	          WHERE id = $1`
+
+	_, err := r.DB.ExecContext(ctx, query, jobID, message, maxAttempts, models.CheckJobFailed, int(backoff.Seconds()))
+	if err != nil {
+		return fmt.Errorf("failed to record job failure: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteFinishedBefore deletes completed and failed jobs finished before the cutoff; their rows
+// are removed by ON DELETE CASCADE. Returns the number of jobs deleted.
+func (r *CheckJobRepository) DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := `DELETE FROM check_jobs WHERE status IN ($1, $2) AND finished_at < $3`
+
+	result, err := r.DB.ExecContext(ctx, query, models.CheckJobCompleted, models.CheckJobFailed, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished check jobs: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// StreamItems iterates over all rows of a job in file order without loading them into memory
+func (r *CheckJobRepository) StreamItems(ctx context.Context, jobID string, fn func(*models.CheckJobItem) error) error {
+	query := `SELECT job_id, row_number, nik, tanggal_lahir, status, error, result
+	          FROM check_job_items
+	          WHERE job_id = $1
+	          ORDER BY row_number`
+
+	rows, err := r.DB.QueryContext(ctx, query, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to get job items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.CheckJobItem
+		var result []byte
+		if err := rows.Scan(&item.JobID, &item.RowNumber, &item.NIK, &item.TanggalLahir, &item.Status, &item.Error, &result); err != nil {
+			return fmt.Errorf("failed to scan job item: %w", err)
+		}
+		item.Result = result
+		if err := fn(&item); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/username/go-gin-backend/internal/models"
+)
+
+// Errors returned by ConsentRepository
+var (
+	ErrConsentNotFound       = errors.New("consent not found")
+	ErrConsentAlreadyExists  = errors.New("consent with this consent_ref already exists for the partner")
+	ErrConsentAlreadyRevoked = errors.New("consent is already revoked")
+)
+
+// ConsentRepository handles database operations for worker consents
+type ConsentRepository struct {
+	DB *sql.DB
+}
+
+// NewConsentRepository creates a new consent repository
+func NewConsentRepository(db *sql.DB) *ConsentRepository {
+	return &ConsentRepository{DB: db}
+}
+
+// activeConsent is the SQL condition for a worker_consents row (alias c) usable today
+const activeConsent = `c.revoked_at IS NULL AND c.valid_from <= CURRENT_DATE
+	                 AND (c.valid_until IS NULL OR c.valid_until >= CURRENT_DATE)`
+
+// consentColumns is the column list read by scanConsent (table alias c)
+const consentColumns = `c.id, c.nik, c.partner_id, c.scopes, c.valid_from, c.valid_until, c.consent_ref, c.source,
+	          c.created_by, c.created_at, c.revoked_at, c.revoked_by, c.revoke_reason,
+	          CASE WHEN c.revoked_at IS NOT NULL THEN 'revoked'
+	               WHEN c.valid_from > CURRENT_DATE THEN 'upcoming'
+	               WHEN c.valid_until < CURRENT_DATE THEN 'expired'
+	               ELSE 'active' END`
+
+// scanConsent scans a consent row selected with consentColumns
+func scanConsent(row interface{ Scan(...interface{}) error }) (*models.WorkerConsent, error) {
+	var c models.WorkerConsent
+	var validUntil sql.NullTime
+	err := row.Scan(
+		&c.ID, &c.NIK, &c.PartnerID, pq.Array(&c.Scopes), &c.ValidFrom.Time, &validUntil, &c.ConsentRef, &c.Source,
+		&c.CreatedBy, &c.CreatedAt, &c.RevokedAt, &c.RevokedBy, &c.RevokeReason, &c.State,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if validUntil.Valid {
+		c.ValidUntil = &models.Date{Time: validUntil.Time}
+	}
+	return &c, nil
+}
+
+// insertConsentQuery inserts a consent; a consent_ref already recorded for the partner returns no row
+const insertConsentQuery = `INSERT INTO worker_consents AS c (nik, partner_id, scopes, valid_from, valid_until, consent_ref, source, created_by)
+	          VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8)
+	          ON CONFLICT (partner_id, consent_ref) WHERE consent_ref IS NOT NULL DO NOTHING
+	          RETURNING ` + consentColumns
+
+// consentArgs returns the insertConsentQuery arguments of a consent
+func consentArgs(c *models.WorkerConsent) []interface{} {
+	var validUntil interface{}
+	if c.ValidUntil != nil {
+		validUntil = c.ValidUntil.Time
+	}
+	var consentRef string
+	if c.ConsentRef != nil {
+		consentRef = *c.ConsentRef
+	}
+	return []interface{}{c.NIK, c.PartnerID, pq.Array(c.Scopes), c.ValidFrom.Time, validUntil, consentRef, c.Source, c.CreatedBy}
+}
+
+// Create records a consent and returns the stored row
+func (r *ConsentRepository) Create(ctx context.Context, consent *models.WorkerConsent) (*models.WorkerConsent, error) {
+	created, err := scanConsent(r.DB.QueryRowContext(ctx, insertConsentQuery, consentArgs(consent)...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrConsentAlreadyExists
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" { // foreign_key_violation
+			return nil, ErrPartnerNotFound
+		}
+		return nil, fmt.Errorf("failed to create consent: %w", err)
+	}
+
+	return created, nil
+}
+
+// InsertBatch records imported consents in one transaction and returns a status per row
+// (inserted, or unchanged when the consent_ref is already recorded). With dryRun the
+// transaction is rolled back.
+func (r *ConsentRepository) InsertBatch(ctx context.Context, consents []*models.WorkerConsent, dryRun bool) ([]string, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, insertConsentQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	statuses := make([]string, len(consents))
+	for i, consent := range consents {
+		_, err := scanConsent(stmt.QueryRowContext(ctx, consentArgs(consent)...))
+		switch {
+		case err == sql.ErrNoRows:
+			statuses[i] = models.ImportRowUnchanged
+		case err != nil:
+			return nil, fmt.Errorf("failed to insert consent (nik %s): %w", consent.NIK, err)
+		default:
+			statuses[i] = models.ImportRowInserted
+		}
+	}
+
+	if dryRun {
+		return statuses, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return statuses, nil
+}
+
+// GetByID retrieves a consent by ID (nil if not found)
+func (r *ConsentRepository) GetByID(ctx context.Context, id string) (*models.WorkerConsent, error) {
+	query := `SELECT ` + consentColumns + ` FROM worker_consents c WHERE c.id = $1`
+
+	consent, err := scanConsent(r.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get consent: %w", err)
+	}
+
+	return consent, nil
+}
+
+// List retrieves consents matching the filter, newest first
+func (r *ConsentRepository) List(ctx context.Context, filter models.ConsentListFilter, limit, offset int) ([]*models.WorkerConsent, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.NIK != "" {
+		args = append(args, filter.NIK)
+		conditions = append(conditions, fmt.Sprintf("c.nik = $%d", len(args)))
+	}
+	if filter.PartnerID != "" {
+		args = append(args, filter.PartnerID)
+		conditions = append(conditions, fmt.Sprintf("c.partner_id = $%d", len(args)))
+	}
+
+	query := `SELECT ` + consentColumns + ` FROM worker_consents c`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit, offset)
+	query += fmt.Sprintf(` ORDER BY c.created_at DESC, c.id LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consents: %w", err)
+	}
+	defer rows.Close()
+
+	consents := []*models.WorkerConsent{}
+	for rows.Next() {
+		consent, err := scanConsent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan consent: %w", err)
+		}
+		consents = append(consents, consent)
+	}
+
+	return consents, rows.Err()
+}
+
+// Revoke revokes a consent and returns the updated row
+func (r *ConsentRepository) Revoke(ctx context.Context, id string, adminID *string, reason string) (*models.WorkerConsent, error) {
+	query := `UPDATE worker_consents c
+	          SET revoked_at = NOW(), revoked_by = $2, revoke_reason = NULLIF($3, '')
+	          WHERE c.id = $1 AND c.revoked_at IS NULL
+	          RETURNING ` + consentColumns
+
+	consent, err := scanConsent(r.DB.QueryRowContext(ctx, query, id, adminID, reason))
+	if err == sql.ErrNoRows {
+		existing, err := r.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, ErrConsentNotFound
+		}
+		return nil, ErrConsentAlreadyRevoked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke consent: %w", err)
+	}
+
+	return consent, nil
+}
+
+// GetConsentedScopes retrieves, per NIK, the scopes covered by the partner's consents usable today
+func (r *ConsentRepository) GetConsentedScopes(ctx context.Context, partnerID string, niks []string) (map[string]map[string]bool, error) {
+	consented := make(map[string]map[string]bool)
+	if len(niks) == 0 {
+		return consented, nil
+	}
+
+	query := `SELECT DISTINCT c.nik, s.scope
+	          FROM worker_consents c, unnest(c.scopes) AS s(scope)
+	          WHERE c.partner_id = $1 AND c.nik = ANY($2) AND ` + activeConsent
+
+	rows, err := r.DB.QueryContext(ctx, query, partnerID, pq.Array(niks))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consents: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var nik, scope string
+		if err := rows.Scan(&nik, &scope); err != nil {
+			return nil, fmt.Errorf("failed to scan consent: %w", err)
+		}
+		if consented[nik] == nil {
+			consented[nik] = make(map[string]bool)
+		}
+		consented[nik][scope] = true
+	}
+
+	return consented, rows.Err()
+}
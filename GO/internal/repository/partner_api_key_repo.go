@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/username/go-gin-backend/internal/models"
+)
+
+// Errors returned by PartnerAPIKeyRepository
+var (
+	ErrAPIKeyNotFound  = errors.New("API key not found")
+	ErrAPIKeyNotActive = errors.New("API key is already revoked or expired")
+)
+
+// PartnerAPIKeyRepository handles database operations for partner API keys
+type PartnerAPIKeyRepository struct {
+	DB *sql.DB
+}
+
+// NewPartnerAPIKeyRepository creates a new partner API key repository
+func NewPartnerAPIKeyRepository(db *sql.DB) *PartnerAPIKeyRepository {
+	return &PartnerAPIKeyRepository{DB: db}
+}
+
+// activeAPIKey is the SQL condition for a partner_api_keys row (alias k) accepted for authentication now
+const activeAPIKey = `k.revoked_at IS NULL AND (k.expires_at IS NULL OR k.expires_at > NOW())`
+
+// partnerAPIKeyColumns is the column list read by scanPartnerAPIKey (table alias k)
+const partnerAPIKeyColumns = `k.id, k.partner_id, k.label, k.key_id, k.key_hash, k.created_by, k.created_at,
+	          k.expires_at, k.last_used_at, k.revoked_at, k.revoked_by, k.rotated_to,
+	          CASE WHEN k.revoked_at IS NOT NULL THEN 'revoked'
+	               WHEN k.expires_at <= NOW() THEN 'expired'
+	               ELSE 'active' END`
+
+// insertAPIKeyQuery inserts a partner API key and returns it
+const insertAPIKeyQuery = `INSERT INTO partner_api_keys AS k (partner_id, label, key_id, key_hash, created_by, expires_at)
+	          VALUES ($1, $2, $3, $4, $5, $6)
+	          RETURNING ` + partnerAPIKeyColumns
+
+// scanPartnerAPIKey scans a partner API key row selected with partnerAPIKeyColumns
+func scanPartnerAPIKey(row interface{ Scan(...interface{}) error }) (*models.PartnerAPIKey, error) {
+	var k models.PartnerAPIKey
+	err := row.Scan(
+		&k.ID, &k.PartnerID, &k.Label, &k.KeyID, &k.KeyHash, &k.CreatedBy, &k.CreatedAt,
+		&k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt, &k.RevokedBy, &k.RotatedTo, &k.State,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// getOne retrieves a single partner API key by a condition (nil when not found)
+func (r *PartnerAPIKeyRepository) getOne(ctx context.Context, condition string, args ...interface{}) (*models.PartnerAPIKey, error) {
+	query := `SELECT ` + partnerAPIKeyColumns + ` FROM partner_api_keys k WHERE ` + condition
+
+	key, err := scanPartnerAPIKey(r.DB.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return key, nil
+}
+
+// GetByKeyID retrieves an API key by the key ID of a pks_live_ key (for authentication)
+func (r *PartnerAPIKeyRepository) GetByKeyID(ctx context.Context, keyID string) (*models.PartnerAPIKey, error) {
+	return r.getOne(ctx, `k.key_id = $1`, keyID)
+}
+
+// GetLegacyByHash retrieves a legacy (UUID) API key by its SHA-256 (for authentication)
+func (r *PartnerAPIKeyRepository) GetLegacyByHash(ctx context.Context, keyHash string) (*models.PartnerAPIKey, error) {
+	return r.getOne(ctx, `k.key_id IS NULL AND k.key_hash = $1`, keyHash)
+}
+
+// GetByID retrieves an API key of a partner (nil when not found)
+func (r *PartnerAPIKeyRepository) GetByID(ctx context.Context, partnerID, id string) (*models.PartnerAPIKey, error) {
+	return r.getOne(ctx, `k.id = $1 AND k.partner_id = $2`, id, partnerID)
+}
+
+// ListByPartnerID retrieves all API keys of a partner, newest first
+func (r *PartnerAPIKeyRepository) ListByPartnerID(ctx context.Context, partnerID string) ([]*models.PartnerAPIKey, error) {
+	query := `SELECT ` + partnerAPIKeyColumns + ` FROM partner_api_keys k
+	          WHERE k.partner_id = $1
+	          ORDER BY k.created_at DESC, k.id`
+
+	rows, err := r.DB.QueryContext(ctx, query, partnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*models.PartnerAPIKey{}
+	for rows.Next() {
+		key, err := scanPartnerAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// CountActive counts the API keys of a partner accepted for authentication now
+func (r *PartnerAPIKeyRepository) CountActive(ctx context.Context, partnerID string) (int, error) {
+	var count int
+	err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM partner_api_keys k WHERE k.partner_id = $1 AND `+activeAPIKey, partnerID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count API keys: %w", err)
+	}
+	return count, nil
+}
+
+// Create inserts a new API key (ID, created_at and state are set from the stored row)
+func (r *PartnerAPIKeyRepository) Create(ctx context.Context, key *models.PartnerAPIKey) (*models.PartnerAPIKey, error) {
+	created, err := scanPartnerAPIKey(r.DB.QueryRowContext(ctx, insertAPIKeyQuery,
+		key.PartnerID, key.Label, key.KeyID, key.KeyHash, key.CreatedBy, key.ExpiresAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+	return created, nil
+}
+
+// Rotate inserts newKey with the label and expiry of an active key, then lets the old key expire
+// after grace (revoked at once when grace is zero). Both changes are made in one transaction.
+func (r *PartnerAPIKeyRepository) Rotate(ctx context.Context, partnerID, id string, newKey *models.PartnerAPIKey, grace time.Duration, adminID *string) (*models.PartnerAPIKey, *models.PartnerAPIKey, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	old, err := scanPartnerAPIKey(tx.QueryRowContext(ctx, `SELECT `+partnerAPIKeyColumns+` FROM partner_api_keys k
+	          WHERE k.id = $1 AND k.partner_id = $2
+	          FOR UPDATE`, id, partnerID))
+	if err == sql.ErrNoRows {
+		return nil, nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	if old.State != models.APIKeyStateActive {
+		return nil, nil, ErrAPIKeyNotActive
+	}
+
+	created, err := scanPartnerAPIKey(tx.QueryRowContext(ctx, insertAPIKeyQuery,
+		partnerID, old.Label, newKey.KeyID, newKey.KeyHash, newKey.CreatedBy, old.ExpiresAt))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	var query string
+	var args []interface{}
+	if grace <= 0 {
+		query = `UPDATE partner_api_keys k SET rotated_to = $2, revoked_at = NOW(), revoked_by = $3
+		          WHERE k.id = $1
+		          RETURNING ` + partnerAPIKeyColumns
+		args = []interface{}{id, created.ID, adminID}
+	} else {
+		query = `UPDATE partner_api_keys k SET rotated_to = $2, expires_at = LEAST(COALESCE(k.expires_at, $3), $3)
+		          WHERE k.id = $1
+		          RETURNING ` + partnerAPIKeyColumns
+		args = []interface{}{id, created.ID, time.Now().Add(grace)}
+	}
+	if old, err = scanPartnerAPIKey(tx.QueryRowContext(ctx, query, args...)); err != nil {
+		return nil, nil, fmt.Errorf("failed to update rotated API key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit API key rotation: %w", err)
+	}
+
+	return created, old, nil
+}
+
+// Revoke revokes an API key of a partner immediately
+func (r *PartnerAPIKeyRepository) Revoke(ctx context.Context, partnerID, id string, adminID *string) (*models.PartnerAPIKey, error) {
+	query := `UPDATE partner_api_keys k
+	          SET revoked_at = NOW(), revoked_by = $3
+	          WHERE k.id = $1 AND k.partner_id = $2 AND k.revoked_at IS NULL
+	          RETURNING ` + partnerAPIKeyColumns
+
+	key, err := scanPartnerAPIKey(r.DB.QueryRowContext(ctx, query, id, partnerID, adminID))
+	if err == sql.ErrNoRows {
+		existing, err := r.GetByID(ctx, partnerID, id)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, ErrAPIKeyNotActive
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// ReplaceAll revokes every API key of a partner that is not yet revoked and inserts key, in one transaction
+func (r *PartnerAPIKeyRepository) ReplaceAll(ctx context.Context, key *models.PartnerAPIKey, adminID *string) (*models.PartnerAPIKey, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `UPDATE partner_api_keys SET revoked_at = NOW(), revoked_by = $2
+	          WHERE partner_id = $1 AND revoked_at IS NULL`, key.PartnerID, adminID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke API keys: %w", err)
+	}
+
+	created, err := scanPartnerAPIKey(tx.QueryRowContext(ctx, insertAPIKeyQuery,
+		key.PartnerID, key.Label, key.KeyID, key.KeyHash, key.CreatedBy, key.ExpiresAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit API key reset: %w", err)
+	}
+
+	return created, nil
+}
+
+// TouchLastUsed records that an API key was used now
+func (r *PartnerAPIKeyRepository) TouchLastUsed(ctx context.Context, id string) error {
+	if _, err := r.DB.ExecContext(ctx, `UPDATE partner_api_keys SET last_used_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to update API key last use: %w", err)
+	}
+	return nil
+}
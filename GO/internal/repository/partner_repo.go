@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -11,6 +12,9 @@ import (
 	"github.com/username/go-gin-backend/internal/models"
 )
 
+// ErrPartnerNotFound is returned when a partner ID does not exist
+var ErrPartnerNotFound = errors.New("partner not found")
+
 // PartnerRepository handles database operations for partners
 type PartnerRepository struct {
 	DB *sql.DB
@@ -21,52 +25,9 @@ func NewPartnerRepository(db *sql.DB) *PartnerRepository {
 	return &PartnerRepository{DB: db}
 }
 
-// GetByAPIKey retrieves a partner by API key (for authentication)
-func (r *PartnerRepository) GetByAPIKey(ctx context.Context, apiKey string) (*models.Partner, error) {
-	query := `SELECT id, company_name, company_id, api_key, COALESCE(company_secret, '') as company_secret, 
-	                 nomor_pks, pic_name, pic_email, pic_phone, status, contract_start, contract_end, 
-	                 notes, created_at, updated_at 
-	          FROM partners WHERE api_key = $1`
-
-	var partner models.Partner
-	var contractStart, contractEnd sql.NullTime
-	err := r.DB.QueryRowContext(ctx, query, apiKey).Scan(
-		&partner.ID,
-		&partner.CompanyName,
-		&partner.CompanyID,
-		&partner.APIKey,
-		&partner.CompanySecret,
-		&partner.NomorPKS,
-		&partner.PICName,
-		&partner.PICEmail,
-		&partner.PICPhone,
-		&partner.Status,
-		&contractStart,
-		&contractEnd,
-		&partner.Notes,
-		&partner.CreatedAt,
-		&partner.UpdatedAt,
-	)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to get partner: %w", err)
-	}
-
-	if contractStart.Valid {
-		partner.ContractStart = &contractStart.Time
-	}
-	if contractEnd.Valid {
-		partner.ContractEnd = &contractEnd.Time
-	}
-
-	return r.markExpiredIfNeeded(ctx, &partner)
-}
-
 // GetByCompanyID retrieves a partner by company id
 func (r *PartnerRepository) GetByCompanyID(ctx context.Context, companyID string) (*models.Partner, error) {
-	query := `SELECT id, company_name, company_id, api_key, COALESCE(company_secret, '') as company_secret, 
+	query := `SELECT id, company_name, company_id, COALESCE(company_secret, '') as company_secret, 
 	                 nomor_pks, pic_name, pic_email, pic_phone, status, contract_start, contract_end, 
 	                 notes, created_at, updated_at 
 	          FROM partners WHERE company_id = $1`
@@ -77,7 +38,6 @@ func (r *PartnerRepository) GetByCompanyID(ctx context.Context, companyID string
 		&partner.ID,
 		&partner.CompanyName,
 		&partner.CompanyID,
-		&partner.APIKey,
 		&partner.CompanySecret,
 		&partner.NomorPKS,
 		&partner.PICName,
@@ -107,9 +67,9 @@ func (r *PartnerRepository) GetByCompanyID(ctx context.Context, companyID string
 	return r.markExpiredIfNeeded(ctx, &partner)
 }
 
-// GetByID retrieves a partner by ID
+// GetByID retrieves a partner by ID, marking it inactive once its contract has ended
 func (r *PartnerRepository) GetByID(ctx context.Context, id string) (*models.Partner, error) {
-	query := `SELECT id, company_name, company_id, api_key, COALESCE(company_secret, '') as company_secret, 
+	query := `SELECT id, company_name, company_id, COALESCE(company_secret, '') as company_secret, 
 	                 nomor_pks, pic_name, pic_email, pic_phone, status, contract_start, contract_end, 
 	                 notes, created_at, updated_at 
 	          FROM partners WHERE id = $1`
@@ -120,7 +80,6 @@ func (r *PartnerRepository) GetByID(ctx context.Context, id string) (*models.Par
 		&partner.ID,
 		&partner.CompanyName,
 		&partner.CompanyID,
-		&partner.APIKey,
 		&partner.CompanySecret,
 		&partner.NomorPKS,
 		&partner.PICName,
@@ -135,7 +94,7 @@ func (r *PartnerRepository) GetByID(ctx context.Context, id string) (*models.Par
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("partner not found")
+			return nil, ErrPartnerNotFound
 		}
 		return nil, fmt.Errorf("failed to get partner: %w", err)
 	}
@@ -147,7 +106,7 @@ func (r *PartnerRepository) GetByID(ctx context.Context, id string) (*models.Par
 		partner.ContractEnd = &contractEnd.Time
 	}
 
-	return &partner, nil
+	return r.markExpiredIfNeeded(ctx, &partner)
 }
 
 // GetAll retrieves all partners
@@ -155,7 +114,7 @@ func (r *PartnerRepository) GetAll(ctx context.Context) ([]*models.Partner, erro
 	// Check which columns exist
 	checkQuery := `SELECT column_name FROM information_schema.columns 
 	               WHERE table_name = 'partners' 
-	               AND column_name IN ('company_id', 'company_code', 'api_key', 'contract_start', 'contract_end', 'company_secret')`
+	               AND column_name IN ('company_id', 'company_code', 'contract_start', 'contract_end', 'company_secret')`
 	rows, err := r.DB.QueryContext(ctx, checkQuery)
 	if err != nil {
 		log.Printf("GetAll column check error: %v", err)
@@ -165,7 +124,6 @@ func (r *PartnerRepository) GetAll(ctx context.Context) ([]*models.Partner, erro
 
 	hasCompanyID := false
 	hasCompanyCode := false
-	hasAPIKey := false
 	hasCompanySecret := false
 	hasContractStart := false
 	hasContractEnd := false
@@ -180,8 +138,6 @@ func (r *PartnerRepository) GetAll(ctx context.Context) ([]*models.Partner, erro
 			hasCompanyID = true
 		case "company_code":
 			hasCompanyCode = true
-		case "api_key":
-			hasAPIKey = true
 		case "company_secret":
 			hasCompanySecret = true
 		case "contract_start":
@@ -201,14 +157,6 @@ func (r *PartnerRepository) GetAll(ctx context.Context) ([]*models.Partner, erro
 		return nil, fmt.Errorf("neither company_id nor company_code column exists")
 	}
 
-	// Build API key column
-	var apiKeyCol string
-	if hasAPIKey {
-		apiKeyCol = "api_key,"
-	} else {
-		apiKeyCol = "NULL::VARCHAR as api_key,"
-	}
-
 	// Build company secret column
 	var companySecretCol string
 	if hasCompanySecret {
@@ -226,7 +174,6 @@ func (r *PartnerRepository) GetAll(ctx context.Context) ([]*models.Partner, erro
 	}
 
 	query := fmt.Sprintf(`SELECT id, company_name, %s, %s 
-	                            %s 
 	                            nomor_pks, pic_name, pic_email, pic_phone, 
 	                            CASE 
 	                                WHEN status::text = 'active' THEN 'Y'
@@ -236,7 +183,7 @@ func (r *PartnerRepository) GetAll(ctx context.Context) ([]*models.Partner, erro
 	                            %s
 	                            notes, created_at, updated_at 
 	                     FROM partners ORDER BY created_at DESC`, 
-	                     companyCol, apiKeyCol, companySecretCol, contractCols)
+	                     companyCol, companySecretCol, contractCols)
 
 	rows, err = r.DB.QueryContext(ctx, query)
 	if err != nil {
@@ -250,7 +197,7 @@ func (r *PartnerRepository) GetAll(ctx context.Context) ([]*models.Partner, erro
 		var p models.Partner
 		var contractStart, contractEnd sql.NullTime
 		if err := rows.Scan(
-			&p.ID, &p.CompanyName, &p.CompanyID, &p.APIKey, &p.CompanySecret, &p.NomorPKS,
+			&p.ID, &p.CompanyName, &p.CompanyID, &p.CompanySecret, &p.NomorPKS,
 			&p.PICName, &p.PICEmail, &p.PICPhone,
 			&p.Status, &contractStart, &contractEnd,
 			&p.Notes, &p.CreatedAt, &p.UpdatedAt,
@@ -276,18 +223,13 @@ func (r *PartnerRepository) GetAll(ctx context.Context) ([]*models.Partner, erro
 	return partners, nil
 }
 
-// Create creates a new partner with API key and contract dates
+// Create creates a new partner with contract dates (its API keys are in partner_api_keys)
 func (r *PartnerRepository) Create(ctx context.Context, p *models.Partner) error {
-	query := `INSERT INTO partners (company_name, company_id, api_key, company_secret, nomor_pks, pic_name, pic_email, 
+	query := `INSERT INTO partners (company_name, company_id, company_secret, nomor_pks, pic_name, pic_email, 
 	                                pic_phone, status, contract_start, contract_end, notes) 
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) 
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) 
 	          RETURNING id, created_at, updated_at`
 
-	var apiKey interface{}
-	if p.APIKey != nil {
-		apiKey = *p.APIKey
-	}
-
 	// Contract dates should always be set (service layer ensures this)
 	// PostgreSQL will automatically convert time.Time to DATE (truncates time part)
 	var contractStart, contractEnd interface{}
@@ -313,7 +255,7 @@ func (r *PartnerRepository) Create(ctx context.Context, p *models.Partner) error
 		p.CompanyName, p.CompanyID, p.NomorPKS, p.Status)
 
 	err := r.DB.QueryRowContext(ctx, query,
-		p.CompanyName, p.CompanyID, apiKey, p.CompanySecret, p.NomorPKS, p.PICName,
+		p.CompanyName, p.CompanyID, p.CompanySecret, p.NomorPKS, p.PICName,
 		p.PICEmail, p.PICPhone, p.Status, contractStart, contractEnd, p.Notes,
 	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
 	
@@ -321,9 +263,6 @@ func (r *PartnerRepository) Create(ctx context.Context, p *models.Partner) error
 		// Check for common constraint violations and provide clearer error messages
 		errStr := err.Error()
 		if strings.Contains(errStr, "does not exist") {
-			if strings.Contains(errStr, "api_key") {
-				return fmt.Errorf("database migration required: api_key column does not exist. Please run fix_add_api_key_column.sql")
-			}
 			if strings.Contains(errStr, "contract_start") || strings.Contains(errStr, "contract_end") {
 				return fmt.Errorf("database migration required: contract date columns do not exist. Please run fix_add_api_key_column.sql")
 			}
@@ -336,9 +275,6 @@ func (r *PartnerRepository) Create(ctx context.Context, p *models.Partner) error
 			if strings.Contains(errStr, "nomor_pks") {
 				return fmt.Errorf("nomor_pks '%s' already exists", p.NomorPKS)
 			}
-			if strings.Contains(errStr, "api_key") {
-				return fmt.Errorf("api_key already exists (this should not happen, please retry)")
-			}
 			return fmt.Errorf("duplicate entry: %w", err)
 		}
 		if strings.Contains(errStr, "check constraint") {
@@ -457,16 +393,6 @@ func (r *PartnerRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-// UpdateAPIKey resets the API key for a partner (for security)
-func (r *PartnerRepository) UpdateAPIKey(ctx context.Context, id, apiKey string) error {
-	query := `UPDATE partners SET api_key = $1, updated_at = NOW() WHERE id = $2`
-	_, err := r.DB.ExecContext(ctx, query, apiKey, id)
-	if err != nil {
-		return fmt.Errorf("failed to update partner API key: %w", err)
-	}
-	return nil
-}
-
 // UpdateSecret rotates the company secret (hashed) - DEPRECATED, kept for backward compatibility
 func (r *PartnerRepository) UpdateSecret(ctx context.Context, id, hashedSecret string) error {
 	query := `UPDATE partners SET company_secret = $1, updated_at = NOW() WHERE id = $2`
@@ -496,3 +422,36 @@ func (r *PartnerRepository) markExpiredIfNeeded(ctx context.Context, p *models.P
 
 	return p, nil
 }
+
+// SetAuditDebugUntil stores full audit payloads for a partner until the given time (nil stops debugging)
+func (r *PartnerRepository) SetAuditDebugUntil(ctx context.Context, id string, until *time.Time) error {
+	result, err := r.DB.ExecContext(ctx, `UPDATE partners SET audit_debug_until = $2, updated_at = NOW() WHERE id = $1`, id, until)
+	if err != nil {
+		return fmt.Errorf("failed to update partner audit debug: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrPartnerNotFound
+	}
+	return nil
+}
+
+// GetAuditDebugPartners retrieves the partners whose full audit payloads are stored, with the end of debugging
+func (r *PartnerRepository) GetAuditDebugPartners(ctx context.Context) (map[string]time.Time, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT id, audit_debug_until FROM partners WHERE audit_debug_until > NOW()`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit debug partners: %w", err)
+	}
+	defer rows.Close()
+
+	partners := make(map[string]time.Time)
+	for rows.Next() {
+		var id string
+		var until time.Time
+		if err := rows.Scan(&id, &until); err != nil {
+			return nil, fmt.Errorf("failed to scan audit debug partner: %w", err)
+		}
+		partners[id] = until
+	}
+
+	return partners, rows.Err()
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/username/go-gin-backend/internal/models"
+)
+
+// Errors returned by PurposeRepository write operations
+var (
+	ErrPurposeNotFound      = errors.New("purpose not found")
+	ErrPurposeAlreadyExists = errors.New("purpose with this code already exists")
+)
+
+// PurposeRepository handles database operations for purposes of use and partner contracts
+type PurposeRepository struct {
+	DB *sql.DB
+}
+
+// NewPurposeRepository creates a new purpose repository
+func NewPurposeRepository(db *sql.DB) *PurposeRepository {
+	return &PurposeRepository{DB: db}
+}
+
+// GetAll retrieves all purposes ordered by code
+func (r *PurposeRepository) GetAll(ctx context.Context) ([]*models.Purpose, error) {
+	query := `SELECT code, description, active, created_at, updated_at
+	          FROM purposes
+	          ORDER BY code`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get purposes: %w", err)
+	}
+	defer rows.Close()
+
+	purposes := []*models.Purpose{}
+	for rows.Next() {
+		var p models.Purpose
+		if err := rows.Scan(&p.Code, &p.Description, &p.Active, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan purpose: %w", err)
+		}
+		purposes = append(purposes, &p)
+	}
+
+	return purposes, rows.Err()
+}
+
+// Create adds a new purpose
+func (r *PurposeRepository) Create(ctx context.Context, p *models.Purpose) error {
+	query := `INSERT INTO purposes (code, description, active)
+	          VALUES ($1, $2, $3)
+	          RETURNING created_at, updated_at`
+
+	err := r.DB.QueryRowContext(ctx, query, p.Code, p.Description, p.Active).Scan(&p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			return ErrPurposeAlreadyExists
+		}
+		return fmt.Errorf("failed to create purpose: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates a purpose (only provided fields are changed) and returns the result
+func (r *PurposeRepository) Update(ctx context.Context, code string, req *models.UpdatePurposeRequest) (*models.Purpose, error) {
+	query := `UPDATE purposes
+	          SET description = COALESCE($1, description),
+	              active = COALESCE($2, active)
+	          WHERE code = $3
+	          RETURNING code, description, active, created_at, updated_at`
+
+	var p models.Purpose
+	err := r.DB.QueryRowContext(ctx, query, req.Description, req.Active, code).
+		Scan(&p.Code, &p.Description, &p.Active, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPurposeNotFound
+		}
+		return nil, fmt.Errorf("failed to update purpose: %w", err)
+	}
+
+	return &p, nil
+}
+
+// GetByPartnerID retrieves the purposes in a partner's contract (inactive ones included)
+func (r *PurposeRepository) GetByPartnerID(ctx context.Context, partnerID string) ([]*models.Purpose, error) {
+	query := `SELECT p.code, p.description, p.active, p.created_at, p.updated_at
+	          FROM partner_purposes pp
+	          JOIN purposes p ON p.code = pp.purpose_code
+	          WHERE pp.partner_id = $1
+	          ORDER BY p.code`
+
+	rows, err := r.DB.QueryContext(ctx, query, partnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partner purposes: %w", err)
+	}
+	defer rows.Close()
+
+	purposes := []*models.Purpose{}
+	for rows.Next() {
+		var p models.Purpose
+		if err := rows.Scan(&p.Code, &p.Description, &p.Active, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan purpose: %w", err)
+		}
+		purposes = append(purposes, &p)
+	}
+
+	return purposes, rows.Err()
+}
+
+// GetAllowedCodes retrieves the active purpose codes a partner may use, as a set
+func (r *PurposeRepository) GetAllowedCodes(ctx context.Context, partnerID string) (map[string]bool, error) {
+	query := `SELECT pp.purpose_code
+	          FROM partner_purposes pp
+	          JOIN purposes p ON p.code = pp.purpose_code AND p.active
+	          WHERE pp.partner_id = $1`
+
+	rows, err := r.DB.QueryContext(ctx, query, partnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partner purposes: %w", err)
+	}
+	defer rows.Close()
+
+	allowed := make(map[string]bool)
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, fmt.Errorf("failed to scan purpose: %w", err)
+		}
+		allowed[code] = true
+	}
+
+	return allowed, rows.Err()
+}
+
+// SetForPartner replaces the purposes in a partner's contract
+func (r *PurposeRepository) SetForPartner(ctx context.Context, partnerID string, codes []string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM partner_purposes WHERE partner_id = $1`, partnerID); err != nil {
+		return fmt.Errorf("failed to clear partner purposes: %w", err)
+	}
+
+	query := `INSERT INTO partner_purposes (partner_id, purpose_code)
+	          SELECT $1, unnest($2::text[])`
+	if _, err := tx.ExecContext(ctx, query, partnerID, pq.Array(codes)); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" { // foreign_key_violation
+			if pqErr.Constraint == "partner_purposes_partner_id_fkey" {
+				return ErrPartnerNotFound
+			}
+			return ErrPurposeNotFound
+		}
+		return fmt.Errorf("failed to set partner purposes: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/username/go-gin-backend/internal/models"
+)
+
+// Errors returned by ScopeDefinitionRepository write operations
+var (
+	ErrScopeDefinitionNotFound      = errors.New("scope definition not found")
+	ErrScopeDefinitionAlreadyExists = errors.New("scope definition with this name already exists")
+	ErrScopeDefinitionInUse         = errors.New("scope is assigned to partners or packages, deactivate it instead")
+)
+
+// ScopeDefinitionRepository handles database operations for the scope registry
+type ScopeDefinitionRepository struct {
+	DB *sql.DB
+}
+
+// NewScopeDefinitionRepository creates a new scope definition repository
+func NewScopeDefinitionRepository(db *sql.DB) *ScopeDefinitionRepository {
+	return &ScopeDefinitionRepository{DB: db}
+}
+
+// GetAll retrieves all scope definitions ordered by name
+func (r *ScopeDefinitionRepository) GetAll(ctx context.Context) ([]*models.ScopeDefinition, error) {
+	query := `SELECT name, description, tk_field, mode, mask_visible, mask_tokens, sensitive, active, created_at, updated_at
+	          FROM scope_definitions
+	          ORDER BY name`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scope definitions: %w", err)
+	}
+	defer rows.Close()
+
+	defs := []*models.ScopeDefinition{}
+	for rows.Next() {
+		var d models.ScopeDefinition
+		if err := rows.Scan(&d.Name, &d.Description, &d.TKField, &d.Mode, &d.MaskVisible, &d.MaskTokens, &d.Sensitive, &d.Active, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan scope definition: %w", err)
+		}
+		defs = append(defs, &d)
+	}
+
+	return defs, rows.Err()
+}
+
+// Create registers a new scope
+func (r *ScopeDefinitionRepository) Create(ctx context.Context, def *models.ScopeDefinition) error {
+	query := `INSERT INTO scope_definitions (name, description, tk_field, mode, mask_visible, mask_tokens, sensitive, active)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	          RETURNING created_at, updated_at`
+
+	err := r.DB.QueryRowContext(ctx, query, def.Name, def.Description, def.TKField, def.Mode, def.MaskVisible, def.MaskTokens, def.Sensitive, def.Active).
+		Scan(&def.CreatedAt, &def.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			return ErrScopeDefinitionAlreadyExists
+		}
+		return fmt.Errorf("failed to create scope definition: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates a scope definition (only provided fields are changed)
+func (r *ScopeDefinitionRepository) Update(ctx context.Context, name string, req *models.UpdateScopeDefinitionRequest) error {
+	query := `UPDATE scope_definitions
+	          SET description = COALESCE($1, description),
+	              tk_field = CASE WHEN $2::text IS NULL THEN tk_field ELSE NULLIF($2::text, '') END,
+	              sensitive = COALESCE($3, sensitive),
+	              active = COALESCE($4, active),
+	              mode = COALESCE($5, mode),
+	              mask_visible = COALESCE($6, mask_visible),
+	              mask_tokens = COALESCE($7, mask_tokens)
+	          WHERE name = $8`
+
+	result, err := r.DB.ExecContext(ctx, query, req.Description, req.TKField, req.Sensitive, req.Active, req.Mode,
+		req.MaskVisible, req.MaskTokens, name)
+	if err != nil {
+		return fmt.Errorf("failed to update scope definition: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrScopeDefinitionNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a scope definition that is not assigned to any partner or package
+func (r *ScopeDefinitionRepository) Delete(ctx context.Context, name string) error {
+	query := `DELETE FROM scope_definitions
+	          WHERE name = $1
+	            AND NOT EXISTS (SELECT 1 FROM partner_access_scopes WHERE scope_name = $1)
+	            AND NOT EXISTS (SELECT 1 FROM scope_package_items WHERE scope_name = $1)`
+
+	result, err := r.DB.ExecContext(ctx, query, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete scope definition: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected > 0 {
+		return nil
+	}
+
+	// Nothing deleted: either unknown or still assigned
+	var exists bool
+	if err := r.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM scope_definitions WHERE name = $1)`, name).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check scope definition: %w", err)
+	}
+	if exists {
+		return ErrScopeDefinitionInUse
+	}
+	return ErrScopeDefinitionNotFound
+}
+
+// TKFieldExists reports whether tk_data has a column with the given name
+func (r *ScopeDefinitionRepository) TKFieldExists(ctx context.Context, field string) (bool, error) {
+	query := `SELECT EXISTS (
+	              SELECT 1 FROM information_schema.columns
+	              WHERE table_schema = current_schema() AND table_name = 'tk_data' AND column_name = $1
+	          )`
+
+	var exists bool
+	if err := r.DB.QueryRowContext(ctx, query, field).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check tk_data column: %w", err)
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/username/go-gin-backend/internal/models"
+)
+
+// Errors returned by ScopePackageRepository write operations
+var (
+	ErrScopePackageNotFound      = errors.New("scope package not found")
+	ErrScopePackageAlreadyExists = errors.New("scope package with this name already exists")
+	ErrScopePackageInUse         = errors.New("scope package is assigned to partners")
+)
+
+// scopePackageQuery selects packages together with their scope names
+const scopePackageQuery = `SELECT p.id, p.name, p.description,
+	       COALESCE(array_agg(i.scope_name ORDER BY i.scope_name) FILTER (WHERE i.scope_name IS NOT NULL), '{}'),
+	       p.created_at, p.updated_at
+	FROM scope_packages p
+	LEFT JOIN scope_package_items i ON i.package_id = p.id`
+
+// ScopePackageRepository handles database operations for scope packages
+type ScopePackageRepository struct {
+	DB *sql.DB
+}
+
+// NewScopePackageRepository creates a new scope package repository
+func NewScopePackageRepository(db *sql.DB) *ScopePackageRepository {
+	return &ScopePackageRepository{DB: db}
+}
+
+// scanScopePackage scans a row selected with scopePackageQuery
+func scanScopePackage(row interface{ Scan(...interface{}) error }) (*models.ScopePackage, error) {
+	var pkg models.ScopePackage
+	var scopes pq.StringArray
+	if err := row.Scan(&pkg.ID, &pkg.Name, &pkg.Description, &scopes, &pkg.CreatedAt, &pkg.UpdatedAt); err != nil {
+		return nil, err
+	}
+	pkg.Scopes = scopes
+	return &pkg, nil
+}
+
+// GetAll retrieves all scope packages ordered by name
+func (r *ScopePackageRepository) GetAll(ctx context.Context) ([]*models.ScopePackage, error) {
+	rows, err := r.DB.QueryContext(ctx, scopePackageQuery+` GROUP BY p.id ORDER BY p.name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scope packages: %w", err)
+	}
+	defer rows.Close()
+
+	packages := []*models.ScopePackage{}
+	for rows.Next() {
+		pkg, err := scanScopePackage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scope package: %w", err)
+		}
+		packages = append(packages, pkg)
+	}
+
+	return packages, rows.Err()
+}
+
+// GetByID retrieves a scope package by ID (nil if not found)
+func (r *ScopePackageRepository) GetByID(ctx context.Context, id string) (*models.ScopePackage, error) {
+	pkg, err := scanScopePackage(r.DB.QueryRowContext(ctx, scopePackageQuery+` WHERE p.id = $1 GROUP BY p.id`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get scope package: %w", err)
+	}
+	return pkg, nil
+}
+
+// GetByPartnerID retrieves the package assigned to a partner (nil if none)
+func (r *ScopePackageRepository) GetByPartnerID(ctx context.Context, partnerID string) (*models.ScopePackage, error) {
+	query := scopePackageQuery + ` WHERE p.id = (SELECT scope_package_id FROM partners WHERE id = $1) GROUP BY p.id`
+
+	pkg, err := scanScopePackage(r.DB.QueryRowContext(ctx, query, partnerID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get partner scope package: %w", err)
+	}
+	return pkg, nil
+}
+
+// Create creates a scope package with its scopes in one transaction
+func (r *ScopePackageRepository) Create(ctx context.Context, pkg *models.ScopePackage) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO scope_packages (name, description)
+	          VALUES ($1, $2)
+	          RETURNING id, created_at, updated_at`
+
+	if err := tx.QueryRowContext(ctx, query, pkg.Name, pkg.Description).Scan(&pkg.ID, &pkg.CreatedAt, &pkg.UpdatedAt); err != nil {
+		return packageWriteError("create", err)
+	}
+	if err := replacePackageItems(ctx, tx, pkg.ID, pkg.Scopes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates a scope package; scopes are replaced when req.Scopes is not nil, which records
+// a scope version for every partner on the package
+func (r *ScopePackageRepository) Update(ctx context.Context, id string, req *models.UpdateScopePackageRequest, changedBy *string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Always touch the row so updated_at reflects scope changes too
+	query := `UPDATE scope_packages
+	          SET name = COALESCE($1, name),
+	              description = COALESCE($2, description)
+	          WHERE id = $3`
+
+	result, err := tx.ExecContext(ctx, query, req.Name, req.Description, id)
+	if err != nil {
+		return packageWriteError("update", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrScopePackageNotFound
+	}
+
+	if req.Scopes != nil {
+		if err := replacePackageItems(ctx, tx, id, req.Scopes); err != nil {
+			return err
+		}
+		if err := recordPackageVersions(ctx, tx, id, changedBy); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a scope package that is not assigned to any partner
+func (r *ScopePackageRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM scope_packages WHERE id = $1`
+
+	result, err := r.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" { // foreign_key_violation (partners.scope_package_id)
+			return ErrScopePackageInUse
+		}
+		return fmt.Errorf("failed to delete scope package: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrScopePackageNotFound
+	}
+
+	return nil
+}
+
+// AssignToPartner sets (or clears with nil) the scope package of a partner and records a scope version
+func (r *ScopePackageRepository) AssignToPartner(ctx context.Context, partnerID string, packageID *string, changedBy *string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockPartner(ctx, tx, partnerID); err != nil {
+		return err
+	}
+
+	query := `UPDATE partners SET scope_package_id = $1, updated_at = NOW() WHERE id = $2`
+
+	if _, err := tx.ExecContext(ctx, query, packageID, partnerID); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" { // foreign_key_violation
+			return ErrScopePackageNotFound
+		}
+		return fmt.Errorf("failed to assign scope package: %w", err)
+	}
+
+	if _, err := recordScopeVersion(ctx, tx, partnerID, changedBy, models.ScopeChangePackage, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// recordPackageVersions locks the partners on a package and records a scope version for each
+func recordPackageVersions(ctx context.Context, tx *sql.Tx, packageID string, changedBy *string) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM partners WHERE scope_package_id = $1 ORDER BY id FOR UPDATE`, packageID)
+	if err != nil {
+		return fmt.Errorf("failed to lock package partners: %w", err)
+	}
+	var partnerIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan package partner: %w", err)
+		}
+		partnerIDs = append(partnerIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to lock package partners: %w", err)
+	}
+
+	for _, partnerID := range partnerIDs {
+		if _, err := recordScopeVersion(ctx, tx, partnerID, changedBy, models.ScopeChangePackage, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replacePackageItems replaces all scopes of a package
+func replacePackageItems(ctx context.Context, tx *sql.Tx, packageID string, scopes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM scope_package_items WHERE package_id = $1`, packageID); err != nil {
+		return fmt.Errorf("failed to clear package scopes: %w", err)
+	}
+
+	query := `INSERT INTO scope_package_items (package_id, scope_name)
+	          SELECT $1, unnest($2::text[])
+	          ON CONFLICT DO NOTHING`
+
+	if _, err := tx.ExecContext(ctx, query, packageID, pq.Array(scopes)); err != nil {
+		return fmt.Errorf("failed to insert package scopes: %w", err)
+	}
+
+	return nil
+}
+
+// packageWriteError maps unique violations on the package name
+func packageWriteError(action string, err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+		return ErrScopePackageAlreadyExists
+	}
+	return fmt.Errorf("failed to %s scope package: %w", action, err)
+}
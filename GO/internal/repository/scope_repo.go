@@ -3,11 +3,17 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 
+	"github.com/lib/pq"
 	"github.com/username/go-gin-backend/internal/models"
 )
 
+// ErrScopeVersionNotFound is returned when a scope version does not exist for the partner
+var ErrScopeVersionNotFound = errors.New("scope version not found")
+
 // ScopeRepository handles database operations for partner scopes
 type ScopeRepository struct {
 	DB *sql.DB
@@ -18,13 +24,59 @@ func NewScopeRepository(db *sql.DB) *ScopeRepository {
 	return &ScopeRepository{DB: db}
 }
 
-// GetByPartnerID retrieves all scopes for a partner
+// activeGrant is the SQL condition for a partner_access_scopes row (alias o) valid today
+const activeGrant = `(o.valid_from IS NULL OR o.valid_from <= CURRENT_DATE)
+	                 AND (o.valid_until IS NULL OR o.valid_until >= CURRENT_DATE)`
+
+// GetByPartnerID retrieves all scope grants of a partner for administration: every
+// partner_access_scopes row (including upcoming and expired grants) plus the package
+// scopes that are not overridden by an active partner grant
 func (r *ScopeRepository) GetByPartnerID(ctx context.Context, partnerID string) ([]models.PartnerScope, error) {
-	query := `SELECT id, partner_id, scope_name, enabled
-	          FROM partner_access_scopes
-	          WHERE partner_id = $1
-	          ORDER BY scope_name`
+	query := `SELECT o.id::text, o.scope_name, o.enabled, 'partner', o.valid_from, o.valid_until,
+	                 CASE WHEN o.valid_from > CURRENT_DATE THEN 'upcoming'
+	                      WHEN o.valid_until < CURRENT_DATE THEN 'expired'
+	                      ELSE 'active' END
+	          FROM partner_access_scopes o
+	          WHERE o.partner_id = $1
+	          UNION ALL
+	          SELECT '', i.scope_name, true, 'package', NULL, NULL, 'active'
+	          FROM partners p
+	          JOIN scope_package_items i ON i.package_id = p.scope_package_id
+	          WHERE p.id = $1
+	            AND NOT EXISTS (
+	                SELECT 1 FROM partner_access_scopes o
+	                WHERE o.partner_id = $1 AND o.scope_name = i.scope_name AND ` + activeGrant + `
+	            )
+	          ORDER BY 2, 4`
+
+	return r.queryScopes(ctx, query, partnerID)
+}
+
+// GetActiveByPartnerID retrieves the effective scopes of a partner right now: the scopes
+// of its package overlaid by its active partner_access_scopes rows (a partner row wins;
+// once it expires the package scope applies again)
+func (r *ScopeRepository) GetActiveByPartnerID(ctx context.Context, partnerID string) ([]models.PartnerScope, error) {
+	query := `SELECT COALESCE(o.id::text, ''), COALESCE(o.scope_name, pk.scope_name), COALESCE(o.enabled, true),
+	                 CASE WHEN o.id IS NULL THEN 'package' ELSE 'partner' END,
+	                 o.valid_from, o.valid_until, 'active'
+	          FROM (
+	              SELECT i.scope_name
+	              FROM partners p
+	              JOIN scope_package_items i ON i.package_id = p.scope_package_id
+	              WHERE p.id = $1
+	          ) pk
+	          FULL OUTER JOIN (
+	              SELECT o.id, o.scope_name, o.enabled, o.valid_from, o.valid_until
+	              FROM partner_access_scopes o
+	              WHERE o.partner_id = $1 AND ` + activeGrant + `
+	          ) o ON o.scope_name = pk.scope_name
+	          ORDER BY 2`
 
+	return r.queryScopes(ctx, query, partnerID)
+}
+
+// queryScopes runs a scope query selecting id, scope_name, enabled, source, valid_from, valid_until, state
+func (r *ScopeRepository) queryScopes(ctx context.Context, query, partnerID string) ([]models.PartnerScope, error) {
 	rows, err := r.DB.QueryContext(ctx, query, partnerID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get scopes: %w", err)
@@ -33,14 +85,21 @@ func (r *ScopeRepository) GetByPartnerID(ctx context.Context, partnerID string)
 
 	var scopes []models.PartnerScope
 	for rows.Next() {
-		var s models.PartnerScope
-		if err := rows.Scan(&s.ID, &s.PartnerID, &s.ScopeName, &s.Enabled); err != nil {
+		s := models.PartnerScope{PartnerID: partnerID}
+		var validFrom, validUntil sql.NullTime
+		if err := rows.Scan(&s.ID, &s.ScopeName, &s.Enabled, &s.Source, &validFrom, &validUntil, &s.State); err != nil {
 			return nil, fmt.Errorf("failed to scan scope: %w", err)
 		}
+		if validFrom.Valid {
+			s.ValidFrom = &models.Date{Time: validFrom.Time}
+		}
+		if validUntil.Valid {
+			s.ValidUntil = &models.Date{Time: validUntil.Time}
+		}
 		scopes = append(scopes, s)
 	}
 
-	return scopes, nil
+	return scopes, rows.Err()
 }
 
 // Create creates a new scope for a partner
@@ -56,14 +115,18 @@ func (r *ScopeRepository) Create(ctx context.Context, partnerID, scopeName strin
 	return nil
 }
 
-// BulkCreate creates multiple scopes for a partner
-func (r *ScopeRepository) BulkCreate(ctx context.Context, partnerID string, scopes []string) error {
+// BulkCreate creates multiple scopes for a partner and records the first scope version
+func (r *ScopeRepository) BulkCreate(ctx context.Context, partnerID string, scopes []string, changedBy *string) error {
 	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	if err := lockPartner(ctx, tx, partnerID); err != nil {
+		return err
+	}
+
 	stmt, err := tx.PrepareContext(ctx, `INSERT INTO partner_access_scopes (partner_id, scope_name, enabled) VALUES ($1, $2, $3)`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
@@ -76,6 +139,10 @@ func (r *ScopeRepository) BulkCreate(ctx context.Context, partnerID string, scop
 		}
 	}
 
+	if _, err := recordScopeVersion(ctx, tx, partnerID, changedBy, models.ScopeChangeCreate, nil); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -97,19 +164,25 @@ func (r *ScopeRepository) Update(ctx context.Context, partnerID, scopeName strin
 	return nil
 }
 
-// BulkUpdate updates multiple scopes for a partner
-func (r *ScopeRepository) BulkUpdate(ctx context.Context, partnerID string, scopes []models.ScopeItem) error {
+// BulkUpdate updates multiple scopes for a partner and records a scope version when anything changed
+func (r *ScopeRepository) BulkUpdate(ctx context.Context, partnerID string, scopes []models.ScopeItem, changedBy *string) error {
 	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	if err := lockPartner(ctx, tx, partnerID); err != nil {
+		return err
+	}
+
 	stmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO partner_access_scopes (partner_id, scope_name, enabled) 
-		VALUES ($1, $2, $3)
+		INSERT INTO partner_access_scopes (partner_id, scope_name, enabled, valid_from, valid_until) 
+		VALUES ($1, $2, $3, $4, $5)
 		ON CONFLICT (partner_id, scope_name) 
-		DO UPDATE SET enabled = EXCLUDED.enabled
+		DO UPDATE SET enabled = EXCLUDED.enabled,
+		              valid_from = EXCLUDED.valid_from,
+		              valid_until = EXCLUDED.valid_until
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
@@ -117,11 +190,15 @@ func (r *ScopeRepository) BulkUpdate(ctx context.Context, partnerID string, scop
 	defer stmt.Close()
 
 	for _, scope := range scopes {
-		if _, err := stmt.ExecContext(ctx, partnerID, scope.ScopeName, scope.Enabled); err != nil {
+		if _, err := stmt.ExecContext(ctx, partnerID, scope.ScopeName, scope.Enabled, dateValue(scope.ValidFrom), dateValue(scope.ValidUntil)); err != nil {
 			return fmt.Errorf("failed to upsert scope %s: %w", scope.ScopeName, err)
 		}
 	}
 
+	if _, err := recordScopeVersion(ctx, tx, partnerID, changedBy, models.ScopeChangeUpdate, nil); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -140,3 +217,208 @@ func (r *ScopeRepository) DeleteByPartnerID(ctx context.Context, partnerID strin
 
 	return nil
 }
+
+// GetVersions retrieves the scope versions of a partner, newest first
+func (r *ScopeRepository) GetVersions(ctx context.Context, partnerID string) ([]*models.PartnerScopeVersion, error) {
+	query := scopeVersionQuery + ` WHERE v.partner_id = $1 ORDER BY v.version DESC`
+
+	rows, err := r.DB.QueryContext(ctx, query, partnerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scope versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []*models.PartnerScopeVersion{}
+	for rows.Next() {
+		v, err := scanScopeVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+
+	return versions, rows.Err()
+}
+
+// GetVersion retrieves a single scope version of a partner
+func (r *ScopeRepository) GetVersion(ctx context.Context, partnerID string, version int) (*models.PartnerScopeVersion, error) {
+	query := scopeVersionQuery + ` WHERE v.partner_id = $1 AND v.version = $2`
+
+	v, err := scanScopeVersion(r.DB.QueryRowContext(ctx, query, partnerID, version))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrScopeVersionNotFound
+	}
+	return v, err
+}
+
+// RestoreVersion replaces all scope rows of a partner with the snapshot of an earlier version,
+// assigns the package of that version again (versions without a recorded package keep the
+// current one) and records the result as a new rollback version. Returns the new version
+// number, or 0 when the current scopes already match the snapshot. ErrScopePackageNotFound is
+// returned when the package of the version has been deleted since.
+func (r *ScopeRepository) RestoreVersion(ctx context.Context, partnerID string, version int, changedBy *string) (int, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockPartner(ctx, tx, partnerID); err != nil {
+		return 0, err
+	}
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM partner_scope_versions WHERE partner_id = $1 AND version = $2)`,
+		partnerID, version).Scan(&exists)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check scope version: %w", err)
+	}
+	if !exists {
+		return 0, ErrScopeVersionNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM partner_access_scopes WHERE partner_id = $1`, partnerID); err != nil {
+		return 0, fmt.Errorf("failed to clear scopes: %w", err)
+	}
+
+	query := `INSERT INTO partner_access_scopes (partner_id, scope_name, enabled, valid_from, valid_until)
+	          SELECT v.partner_id, x.scope_name, x.enabled, x.valid_from, x.valid_until
+	          FROM partner_scope_versions v,
+	               jsonb_to_recordset(v.scopes) AS x(scope_name TEXT, enabled BOOLEAN, valid_from DATE, valid_until DATE)
+	          WHERE v.partner_id = $1 AND v.version = $2`
+
+	if _, err := tx.ExecContext(ctx, query, partnerID, version); err != nil {
+		return 0, fmt.Errorf("failed to restore scopes: %w", err)
+	}
+
+	query = `UPDATE partners p
+	         SET scope_package_id = v.scope_package_id, updated_at = NOW()
+	         FROM partner_scope_versions v
+	         WHERE p.id = v.partner_id AND v.partner_id = $1 AND v.version = $2
+	           AND v.package_scopes IS NOT NULL
+	           AND p.scope_package_id IS DISTINCT FROM v.scope_package_id`
+
+	if _, err := tx.ExecContext(ctx, query, partnerID, version); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" { // foreign_key_violation (package deleted)
+			return 0, ErrScopePackageNotFound
+		}
+		return 0, fmt.Errorf("failed to restore scope package: %w", err)
+	}
+
+	newVersion, err := recordScopeVersion(ctx, tx, partnerID, changedBy, models.ScopeChangeRollback, &version)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return newVersion, nil
+}
+
+// scopeVersionQuery selects scope versions with the username of the acting admin
+const scopeVersionQuery = `SELECT v.id, v.partner_id, v.version, v.scopes, v.scope_package_id, v.package_scopes,
+	       v.changed_by, a.username,
+	       v.change_type, v.rollback_of, v.created_at
+	FROM partner_scope_versions v
+	LEFT JOIN admins a ON a.id = v.changed_by`
+
+// scanScopeVersion scans a row selected with scopeVersionQuery
+func scanScopeVersion(row interface{ Scan(...interface{}) error }) (*models.PartnerScopeVersion, error) {
+	var v models.PartnerScopeVersion
+	var scopes, packageScopes []byte
+	if err := row.Scan(&v.ID, &v.PartnerID, &v.Version, &scopes, &v.ScopePackageID, &packageScopes,
+		&v.ChangedBy, &v.ChangedByUsername,
+		&v.ChangeType, &v.RollbackOf, &v.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan scope version: %w", err)
+	}
+	if err := json.Unmarshal(scopes, &v.Scopes); err != nil {
+		return nil, fmt.Errorf("failed to decode scope version: %w", err)
+	}
+	if packageScopes != nil {
+		if err := json.Unmarshal(packageScopes, &v.PackageScopes); err != nil {
+			return nil, fmt.Errorf("failed to decode scope version package: %w", err)
+		}
+	}
+	return &v, nil
+}
+
+// lockPartner locks the partner row so scope writes and their version numbers are serialized
+func lockPartner(ctx context.Context, tx *sql.Tx, partnerID string) error {
+	var id string
+	err := tx.QueryRowContext(ctx, `SELECT id FROM partners WHERE id = $1 FOR UPDATE`, partnerID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrPartnerNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock partner: %w", err)
+	}
+	return nil
+}
+
+// recordScopeVersion stores a snapshot of the partner's current scope rows, its scope package
+// and the package's scopes as the next version. Nothing is stored (and 0 is returned) when the
+// snapshot equals the latest version.
+func recordScopeVersion(ctx context.Context, tx *sql.Tx, partnerID string, changedBy *string, changeType string, rollbackOf *int) (int, error) {
+	query := `WITH snap AS (
+	              SELECT COALESCE((
+	                         SELECT jsonb_agg(jsonb_build_object(
+	                                    'scope_name', s.scope_name,
+	                                    'enabled', s.enabled,
+	                                    'valid_from', s.valid_from,
+	                                    'valid_until', s.valid_until
+	                                ) ORDER BY s.scope_name)
+	                         FROM partner_access_scopes s
+	                         WHERE s.partner_id = p.id
+	                     ), '[]'::jsonb) AS scopes,
+	                     p.scope_package_id,
+	                     COALESCE((
+	                         SELECT jsonb_agg(i.scope_name ORDER BY i.scope_name)
+	                         FROM scope_package_items i
+	                         WHERE i.package_id = p.scope_package_id
+	                     ), '[]'::jsonb) AS package_scopes
+	              FROM partners p
+	              WHERE p.id = $1
+	          ), last AS (
+	              SELECT version, scopes, scope_package_id, package_scopes
+	              FROM partner_scope_versions
+	              WHERE partner_id = $1
+	              ORDER BY version DESC
+	              LIMIT 1
+	          )
+	          INSERT INTO partner_scope_versions (partner_id, version, scopes, scope_package_id, package_scopes,
+	                                              changed_by, change_type, rollback_of)
+	          SELECT $1, COALESCE((SELECT version FROM last), 0) + 1, snap.scopes, snap.scope_package_id,
+	                 snap.package_scopes, $2::uuid, $3, $4::int
+	          FROM snap
+	          WHERE NOT EXISTS (
+	              SELECT 1 FROM last
+	              WHERE last.scopes = snap.scopes
+	                AND last.scope_package_id IS NOT DISTINCT FROM snap.scope_package_id
+	                AND last.package_scopes = snap.package_scopes
+	          )
+	          RETURNING version`
+
+	var version int
+	err := tx.QueryRowContext(ctx, query, partnerID, changedBy, changeType, rollbackOf).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to record scope version: %w", err)
+	}
+	return version, nil
+}
+
+// dateValue converts an optional date to a query argument (NULL when empty)
+func dateValue(d *models.Date) interface{} {
+	if d == nil || d.Time.IsZero() {
+		return nil
+	}
+	return d.Time.Format("2006-01-02")
+}
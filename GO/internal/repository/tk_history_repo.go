@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/username/go-gin-backend/internal/models"
+)
+
+// TKHistoryRepository handles database operations for TK status history
+type TKHistoryRepository struct {
+	DB *sql.DB
+}
+
+// NewTKHistoryRepository creates a new TK history repository
+func NewTKHistoryRepository(db *sql.DB) *TKHistoryRepository {
+	return &TKHistoryRepository{DB: db}
+}
+
+// GetByNIK retrieves the status history of a worker, oldest first
+func (r *TKHistoryRepository) GetByNIK(ctx context.Context, nik string) ([]models.TKStatusHistory, error) {
+	query := `SELECT id, nik, old_status, new_status, changed_at
+	          FROM tk_status_history
+	          WHERE nik = $1
+	          ORDER BY changed_at, id`
+
+	rows, err := r.DB.QueryContext(ctx, query, nik)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []models.TKStatusHistory{}
+	for rows.Next() {
+		var h models.TKStatusHistory
+		if err := rows.Scan(&h.ID, &h.NIK, &h.OldStatus, &h.NewStatus, &h.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan status history: %w", err)
+		}
+		history = append(history, h)
+	}
+
+	return history, rows.Err()
+}
+
+// GetStatusesAsOf resolves the participation status at the end of each requested date in a
+// single query. The result is keyed by StatusAsOfQuery.Key(); a nil value means no status
+// was known yet on that date.
+func (r *TKHistoryRepository) GetStatusesAsOf(ctx context.Context, queries []models.StatusAsOfQuery) (map[string]*string, error) {
+	result := make(map[string]*string, len(queries))
+	if len(queries) == 0 {
+		return result, nil
+	}
+
+	niks := make([]string, len(queries))
+	dates := make([]string, len(queries))
+	for i, q := range queries {
+		niks[i] = q.NIK
+		dates[i] = q.AsOf.Format("2006-01-02")
+	}
+
+	query := `SELECT q.nik, q.as_of, h.new_status
+	          FROM unnest($1::text[], $2::date[]) AS q(nik, as_of)
+	          LEFT JOIN LATERAL (
+	              SELECT new_status
+	              FROM tk_status_history
+	              WHERE nik = q.nik AND changed_at < q.as_of + 1
+	              ORDER BY changed_at DESC, id DESC
+	              LIMIT 1
+	          ) h ON true`
+
+	rows, err := r.DB.QueryContext(ctx, query, pq.Array(niks), pq.Array(dates))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status as of date: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var nik string
+		var asOf time.Time
+		var status *string
+		if err := rows.Scan(&nik, &asOf, &status); err != nil {
+			return nil, fmt.Errorf("failed to scan status as of date: %w", err)
+		}
+		result[models.StatusAsOfQuery{NIK: nik, AsOf: asOf}.Key()] = status
+	}
+
+	return result, rows.Err()
+}
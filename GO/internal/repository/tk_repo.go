@@ -3,12 +3,35 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/username/go-gin-backend/internal/models"
 )
 
+// Errors returned by TKRepository write operations
+var (
+	ErrTKNotFound      = errors.New("TK data not found")
+	ErrTKAlreadyExists = errors.New("TK data with this NIK already exists")
+	ErrTKValueRejected = errors.New("value rejected by database")
+)
+
+// TKImportRowError reports the imported row that made UpsertBatch fail
+type TKImportRowError struct {
+	Row int
+	Err error // ErrTKValueRejected when the row itself is invalid for the table
+}
+
+func (e *TKImportRowError) Error() string {
+	return fmt.Sprintf("failed to upsert row %d: %v", e.Row, e.Err)
+}
+
+func (e *TKImportRowError) Unwrap() error { return e.Err }
+
 // TKRepository handles database operations for TK data
 type TKRepository struct {
 	DB *sql.DB
@@ -42,23 +65,28 @@ func (r *TKRepository) GetByNIK(ctx context.Context, nik string) (*models.TKData
 	return &tk, nil
 }
 
-// CheckByNIKAndDOB checks TK data by NIK and date of birth
-func (r *TKRepository) CheckByNIKAndDOB(ctx context.Context, nik string, dob time.Time) (*models.TKData, error) {
-	query := `SELECT nik, nama, tanggal_lahir, alamat, status_kepesertaan, updated_at
+// GetForCheck retrieves TK data by NIK with all columns in Fields (nil when the NIK does not exist).
+// The date of birth is compared by the caller so a mismatch can be told apart from a missing NIK.
+func (r *TKRepository) GetForCheck(ctx context.Context, nik string) (*models.TKData, error) {
+	query := `SELECT nik, nama, tanggal_lahir, alamat, status_kepesertaan, updated_at, to_jsonb(tk_data)
 	          FROM tk_data 
-	          WHERE nik = $1 AND tanggal_lahir = $2`
+	          WHERE nik = $1`
 
 	var tk models.TKData
-	err := r.DB.QueryRowContext(ctx, query, nik, dob).Scan(
+	var fields []byte
+	err := r.DB.QueryRowContext(ctx, query, nik).Scan(
 		&tk.NIK, &tk.Nama, &tk.TanggalLahir,
-		&tk.Alamat, &tk.StatusKepesertaan, &tk.UpdatedAt,
+		&tk.Alamat, &tk.StatusKepesertaan, &tk.UpdatedAt, &fields,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("TK data not found or date of birth mismatch")
+			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to check TK data: %w", err)
 	}
+	if err := json.Unmarshal(fields, &tk.Fields); err != nil {
+		return nil, fmt.Errorf("failed to decode TK data: %w", err)
+	}
 
 	// Format date for JSON
 	tk.TanggalLahirStr = tk.TanggalLahir.Format("2006-01-02")
@@ -66,6 +94,44 @@ func (r *TKRepository) CheckByNIKAndDOB(ctx context.Context, nik string, dob tim
 	return &tk, nil
 }
 
+// GetByNIKs retrieves TK data for multiple NIKs in a single query, keyed by NIK
+func (r *TKRepository) GetByNIKs(ctx context.Context, niks []string) (map[string]*models.TKData, error) {
+	result := make(map[string]*models.TKData, len(niks))
+	if len(niks) == 0 {
+		return result, nil
+	}
+
+	query := `SELECT nik, nama, tanggal_lahir, alamat, status_kepesertaan, updated_at, to_jsonb(tk_data)
+	          FROM tk_data WHERE nik = ANY($1)`
+
+	rows, err := r.DB.QueryContext(ctx, query, pq.Array(niks))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TK data: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tk models.TKData
+		var fields []byte
+		if err := rows.Scan(
+			&tk.NIK, &tk.Nama, &tk.TanggalLahir,
+			&tk.Alamat, &tk.StatusKepesertaan, &tk.UpdatedAt, &fields,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan TK data: %w", err)
+		}
+		if err := json.Unmarshal(fields, &tk.Fields); err != nil {
+			return nil, fmt.Errorf("failed to decode TK data: %w", err)
+		}
+		tk.TanggalLahirStr = tk.TanggalLahir.Format("2006-01-02")
+		result[tk.NIK] = &tk
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate TK data: %w", err)
+	}
+
+	return result, nil
+}
+
 // GetAll retrieves all TK data with pagination
 func (r *TKRepository) GetAll(ctx context.Context, limit, offset int) ([]*models.TKData, error) {
 	query := `SELECT nik, nama, tanggal_lahir, alamat, status_kepesertaan, updated_at
@@ -95,6 +161,59 @@ func (r *TKRepository) GetAll(ctx context.Context, limit, offset int) ([]*models
 	return tkList, nil
 }
 
+// likeEscaper escapes the LIKE metacharacters of a search term (used with ESCAPE '\')
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike returns a search term matched literally inside a LIKE pattern
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// Search retrieves TK data filtered by NIK prefix or name (case-insensitive) with pagination.
+// It also returns the total number of matching rows.
+func (r *TKRepository) Search(ctx context.Context, search string, limit, offset int) ([]*models.TKData, int, error) {
+	where := ""
+	args := []interface{}{}
+	if search != "" {
+		where = `WHERE nik LIKE ($1 || '%') ESCAPE '\' OR nama ILIKE ('%' || $1 || '%') ESCAPE '\'`
+		args = append(args, escapeLike(search))
+	}
+
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM tk_data %s`, where)
+	if err := r.DB.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count TK data: %w", err)
+	}
+
+	query := fmt.Sprintf(`SELECT nik, nama, tanggal_lahir, alamat, status_kepesertaan, updated_at
+	          FROM tk_data
+	          %s
+	          ORDER BY updated_at DESC, nik
+	          LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get TK data: %w", err)
+	}
+	defer rows.Close()
+
+	tkList := []*models.TKData{}
+	for rows.Next() {
+		var tk models.TKData
+		if err := rows.Scan(
+			&tk.NIK, &tk.Nama, &tk.TanggalLahir,
+			&tk.Alamat, &tk.StatusKepesertaan, &tk.UpdatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan TK data: %w", err)
+		}
+		tk.TanggalLahirStr = tk.TanggalLahir.Format("2006-01-02")
+		tkList = append(tkList, &tk)
+	}
+
+	return tkList, total, rows.Err()
+}
+
 // Create creates new TK data
 func (r *TKRepository) Create(ctx context.Context, req *models.CreateTKDataRequest) error {
 	query := `INSERT INTO tk_data (nik, nama, tanggal_lahir, alamat, status_kepesertaan) 
@@ -107,6 +226,10 @@ func (r *TKRepository) Create(ctx context.Context, req *models.CreateTKDataReque
 
 	_, err = r.DB.ExecContext(ctx, query, req.NIK, req.Nama, dob, req.Alamat, req.StatusKepesertaan)
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			return ErrTKAlreadyExists
+		}
 		return fmt.Errorf("failed to create TK data: %w", err)
 	}
 
@@ -132,10 +255,13 @@ func (r *TKRepository) Update(ctx context.Context, nik string, req *models.Updat
 		dob = &parsedDOB
 	}
 
-	_, err := r.DB.ExecContext(ctx, query, req.Nama, dob, req.Alamat, req.StatusKepesertaan, nik)
+	result, err := r.DB.ExecContext(ctx, query, req.Nama, dob, req.Alamat, req.StatusKepesertaan, nik)
 	if err != nil {
 		return fmt.Errorf("failed to update TK data: %w", err)
 	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrTKNotFound
+	}
 
 	return nil
 }
@@ -144,10 +270,73 @@ func (r *TKRepository) Update(ctx context.Context, nik string, req *models.Updat
 func (r *TKRepository) Delete(ctx context.Context, nik string) error {
 	query := `DELETE FROM tk_data WHERE nik = $1`
 
-	_, err := r.DB.ExecContext(ctx, query, nik)
+	result, err := r.DB.ExecContext(ctx, query, nik)
 	if err != nil {
 		return fmt.Errorf("failed to delete TK data: %w", err)
 	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrTKNotFound
+	}
 
 	return nil
 }
+
+// UpsertBatch inserts or updates a batch of imported rows inside one transaction and
+// returns the status of each row (inserted, updated or unchanged). With dryRun the
+// transaction is rolled back, so the statuses are accurate but nothing is committed.
+func (r *TKRepository) UpsertBatch(ctx context.Context, rows []models.TKImportRow, dryRun bool) ([]string, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Rows identical to the stored data are skipped by the WHERE clause (no row returned)
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO tk_data (nik, nama, tanggal_lahir, alamat, status_kepesertaan)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		ON CONFLICT (nik) DO UPDATE
+		SET nama = EXCLUDED.nama,
+		    tanggal_lahir = EXCLUDED.tanggal_lahir,
+		    alamat = EXCLUDED.alamat,
+		    status_kepesertaan = EXCLUDED.status_kepesertaan,
+		    updated_at = NOW()
+		WHERE (tk_data.nama, tk_data.tanggal_lahir, tk_data.alamat, tk_data.status_kepesertaan)
+		      IS DISTINCT FROM (EXCLUDED.nama, EXCLUDED.tanggal_lahir, EXCLUDED.alamat, EXCLUDED.status_kepesertaan)
+		RETURNING (xmax = 0) AS inserted
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	statuses := make([]string, len(rows))
+	for i, row := range rows {
+		var inserted bool
+		err := stmt.QueryRowContext(ctx, row.NIK, row.Nama, row.TanggalLahir, row.Alamat, row.StatusKepesertaan).Scan(&inserted)
+		switch {
+		case err == sql.ErrNoRows:
+			statuses[i] = models.ImportRowUnchanged
+		case err != nil:
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && (pqErr.Code.Class() == "22" || pqErr.Code.Class() == "23") { // data exception, integrity violation
+				err = fmt.Errorf("%w: %s", ErrTKValueRejected, pqErr.Message)
+			}
+			return nil, &TKImportRowError{Row: row.Row, Err: err}
+		case inserted:
+			statuses[i] = models.ImportRowInserted
+		default:
+			statuses[i] = models.ImportRowUpdated
+		}
+	}
+
+	if dryRun {
+		return statuses, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return statuses, nil
+}
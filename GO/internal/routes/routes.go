@@ -2,6 +2,7 @@ package routes
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/username/go-gin-backend/internal/config"
@@ -12,8 +13,10 @@ import (
 )
 
 // SetupRoutes configures all application routes
-func SetupRoutes(db *sql.DB, cfg *config.Config) *fiber.App {
-	app := fiber.New()
+func SetupRoutes(db *sql.DB, cfg *config.Config, checkJobService *service.CheckJobService, receiptService *service.ReceiptService, auditWriter *service.AuditWriter, auditPrivacy *service.AuditPrivacy, auditChainService *service.AuditChainService, apiKeyPepper []byte) *fiber.App {
+	app := fiber.New(fiber.Config{
+		BodyLimit: cfg.MaxUploadSizeMB * 1024 * 1024, // CSV uploads for bulk check jobs
+	})
 
 	// Add custom middleware
 	app.Use(middleware.Logger())
@@ -25,16 +28,40 @@ func SetupRoutes(db *sql.DB, cfg *config.Config) *fiber.App {
 	adminRepo := repository.NewAdminRepository(db)
 	tkRepo := repository.NewTKRepository(db)
 	auditRepo := repository.NewAuditRepository(db)
+	tkHistoryRepo := repository.NewTKHistoryRepository(db)
+	scopeDefRepo := repository.NewScopeDefinitionRepository(db)
+	scopePackageRepo := repository.NewScopePackageRepository(db)
+	purposeRepo := repository.NewPurposeRepository(db)
+	consentRepo := repository.NewConsentRepository(db)
+	apiKeyRepo := repository.NewPartnerAPIKeyRepository(db)
 
 	// Initialize services
 	authService := service.NewAuthService(adminRepo, cfg.JWTSecret)
-	checkingService := service.NewCheckingService(tkRepo, auditRepo)
-	partnerService := service.NewPartnerService(partnerRepo, scopeRepo)
+	scopeRegistry := service.NewScopeRegistry(scopeDefRepo)
+	checkingService := service.NewCheckingService(tkRepo, auditWriter, tkHistoryRepo, scopeRegistry, purposeRepo, consentRepo, receiptService)
+	apiKeyService := service.NewPartnerAPIKeyService(apiKeyRepo, partnerRepo, apiKeyPepper, time.Duration(cfg.APIKeyGraceHours)*time.Hour)
+	purposeService := service.NewPurposeService(purposeRepo, partnerRepo)
+	partnerService := service.NewPartnerService(partnerRepo, scopeRepo, scopePackageRepo, scopeRegistry, apiKeyService, purposeService, cfg.PartnerPurposes)
+	scopePackageService := service.NewScopePackageService(scopePackageRepo, scopeRegistry)
+	tkService := service.NewTKService(tkRepo, tkHistoryRepo)
+	consentService := service.NewConsentService(consentRepo, partnerRepo, scopeRegistry)
+	accessReportService := service.NewAccessReportService(auditRepo, auditPrivacy)
+	auditService := service.NewAuditService(auditRepo, auditPrivacy)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
-	checkingHandler := handlers.NewCheckingHandler(checkingService)
+	checkingHandler := handlers.NewCheckingHandler(checkingService, cfg.BatchCheckMaxItems)
 	adminPartnerHandler := handlers.NewAdminPartnerHandler(partnerService)
+	adminAPIKeyHandler := handlers.NewAdminPartnerAPIKeyHandler(apiKeyService)
+	checkJobHandler := handlers.NewCheckJobHandler(checkJobService)
+	adminTKHandler := handlers.NewAdminTKHandler(tkService)
+	adminScopeHandler := handlers.NewAdminScopeHandler(scopeRegistry)
+	adminScopePackageHandler := handlers.NewAdminScopePackageHandler(scopePackageService)
+	receiptHandler := handlers.NewReceiptHandler(receiptService)
+	adminPurposeHandler := handlers.NewAdminPurposeHandler(purposeService)
+	adminConsentHandler := handlers.NewAdminConsentHandler(consentService)
+	adminAccessReportHandler := handlers.NewAdminAccessReportHandler(accessReportService)
+	adminAuditHandler := handlers.NewAdminAuditHandler(auditService, auditWriter, auditChainService)
 
 	// Root endpoint
 	app.Get("/", func(c *fiber.Ctx) error {
@@ -45,11 +72,18 @@ func SetupRoutes(db *sql.DB, cfg *config.Config) *fiber.App {
 				"health":      "/api/health",
 				"admin_login": "/api/v1/auth/admin/login",
 				"check_tk":   "/api/checking (Requires X-API-KEY header)",
+				"check_tk_batch": "/api/checking/batch (Requires X-API-KEY header)",
+				"verify_tk":      "/api/checking/verify (Requires X-API-KEY header)",
+				"check_jobs":     "/api/checking/jobs (Requires X-API-KEY header)",
+				"receipts":       "/api/receipts/verify, /.well-known/jwks.json (public)",
 				"admin_panel": "/admin/* (Requires JWT)",
 			},
 		})
 	})
 
+	// Public key set for verifying receipts offline
+	app.Get("/.well-known/jwks.json", receiptHandler.JWKS)
+
 	// API routes
 	api := app.Group("/api")
 	{
@@ -66,11 +100,23 @@ func SetupRoutes(db *sql.DB, cfg *config.Config) *fiber.App {
 			}
 		}
 
-		// Partner checking endpoint (API Key authentication)
-		api.Post("/checking",
-			middleware.PartnerAPIKeyAuth(partnerRepo, scopeRepo),
-			checkingHandler.CheckTK,
-		)
+		// Receipt verification (public, for regulators)
+		api.Post("/receipts/verify", receiptHandler.Verify)
+
+		// Partner checking endpoints (API Key authentication)
+		partnerAuth := middleware.PartnerAPIKeyAuth(apiKeyService, scopeRepo)
+		api.Post("/checking", partnerAuth, checkingHandler.CheckTK)
+		api.Post("/checking/batch", partnerAuth, checkingHandler.CheckTKBatch)
+		api.Post("/checking/verify", partnerAuth, checkingHandler.VerifyTK) // Match partner-held attributes, no data returned
+
+		// Asynchronous bulk check jobs (CSV upload, poll, download)
+		jobs := api.Group("/checking/jobs", partnerAuth)
+		{
+			jobs.Post("", checkJobHandler.Create)               // Upload CSV and queue job
+			jobs.Get("", checkJobHandler.List)                  // List partner jobs
+			jobs.Get("/:id/results", checkJobHandler.Results)   // Download results (csv/ndjson)
+			jobs.Get("/:id", checkJobHandler.Get)               // Job status and progress
+		}
 	}
 
 	// Admin routes (requires JWT admin authentication)
@@ -88,15 +134,94 @@ func SetupRoutes(db *sql.DB, cfg *config.Config) *fiber.App {
 			partners.Get("/:id/scopes", adminPartnerHandler.GetScopes)    // Get partner scopes
 			partners.Put("/:id/scopes", adminPartnerHandler.UpdateScopes) // Update partner scopes
 
+			// Scope change history (every scope write is stored as a version)
+			partners.Get("/:id/scope-versions", adminPartnerHandler.ScopeVersions)          // List scope versions
+			partners.Get("/:id/scope-versions/diff", adminPartnerHandler.DiffScopeVersions) // Diff two versions (?from, ?to)
+			partners.Post("/:id/scopes/rollback", adminPartnerHandler.RollbackScopes)       // Restore an earlier version
+
+			// Scope package assignment (package scopes + per-partner overrides above)
+			partners.Get("/:id/scope-package", adminScopePackageHandler.GetPartnerPackage) // Get assigned scope package
+			partners.Put("/:id/scope-package", adminScopePackageHandler.AssignToPartner)   // Assign/unassign scope package
+
+			// Purposes of use allowed by the partner's contract
+			partners.Get("/:id/purposes", adminPurposeHandler.GetPartnerPurposes) // Get contracted purposes
+			partners.Put("/:id/purposes", adminPurposeHandler.SetPartnerPurposes) // Replace contracted purposes
+
+			// Full audit payloads for a limited time (debugging a partner integration)
+			partners.Put("/:id/audit-debug", adminAuditHandler.SetPartnerDebug) // Set/clear audit debugging ({"hours"})
+
 			// API key management (must be before :id route)
-			partners.Get("/:id/reveal-api-key", adminPartnerHandler.RevealAPIKey)  // Reveal current API key (no reset)
-			partners.Post("/:id/reset-api-key", adminPartnerHandler.ResetAPIKey)    // Reset API key and return plaintext once
+			partners.Get("/:id/api-keys", adminAPIKeyHandler.List)                      // Keys with label, expiry, last use, state
+			partners.Post("/:id/api-keys", adminAPIKeyHandler.Create)                   // Add a named key ({"label", "expires_at"?})
+			partners.Post("/:id/api-keys/:keyId/rotate", adminAPIKeyHandler.Rotate)     // New key, old one valid for the grace period
+			partners.Delete("/:id/api-keys/:keyId", adminAPIKeyHandler.Revoke)          // Revoke a key immediately
+			partners.Post("/:id/reset-api-key", adminPartnerHandler.ResetAPIKey)    // Revoke all keys, return one new key in plaintext once
 
 			// Generic partner routes (must be last)
 			partners.Get("/:id", adminPartnerHandler.Get)        // Get partner details
 			partners.Put("/:id", adminPartnerHandler.Update)     // Update partner
 			partners.Delete("/:id", adminPartnerHandler.Delete)  // Delete partner
 		}
+
+		// Scope registry management
+		scopes := admin.Group("/scopes")
+		{
+			scopes.Get("", adminScopeHandler.List)            // List scope definitions
+			scopes.Post("", adminScopeHandler.Create)         // Register a new scope
+			scopes.Put("/:name", adminScopeHandler.Update)    // Update description/field/sensitive/active
+			scopes.Delete("/:name", adminScopeHandler.Delete) // Delete unused scope
+		}
+
+		// Scope packages (templates assignable to partners)
+		packages := admin.Group("/scope-packages")
+		{
+			packages.Get("", adminScopePackageHandler.List)          // List packages
+			packages.Post("", adminScopePackageHandler.Create)       // Create package
+			packages.Get("/:id", adminScopePackageHandler.Get)       // Get package
+			packages.Put("/:id", adminScopePackageHandler.Update)    // Update package (applies to all partners on it)
+			packages.Delete("/:id", adminScopePackageHandler.Delete) // Delete unassigned package
+		}
+
+		// Purposes of use (stated on every check)
+		purposes := admin.Group("/purposes")
+		{
+			purposes.Get("", adminPurposeHandler.List)         // List purposes
+			purposes.Post("", adminPurposeHandler.Create)      // Register a new purpose
+			purposes.Put("/:code", adminPurposeHandler.Update) // Update description/active
+		}
+
+		// Worker consents for sensitive scopes
+		consents := admin.Group("/consents")
+		{
+			consents.Get("", adminConsentHandler.List)               // List consents (?nik, ?partner_id)
+			consents.Post("", adminConsentHandler.Create)            // Record a consent
+			consents.Post("/import", adminConsentHandler.Import)     // Import CSV/XLSX (?partner_id, ?dry_run=true)
+			consents.Get("/:id", adminConsentHandler.Get)            // Get consent
+			consents.Post("/:id/revoke", adminConsentHandler.Revoke) // Revoke consent
+		}
+
+		// Audit log search (cursor pagination, newest first) and export
+		auditLogs := admin.Group("/audit-logs")
+		{
+			auditLogs.Get("", adminAuditHandler.List)                 // Search (?partner_id, ?nik, ?from, ?to, ?outcome, ?scope, ?limit, ?cursor)
+			auditLogs.Get("/writer", adminAuditHandler.WriterMetrics) // Audit writer queue backlog and counters
+			auditLogs.Get("/verify", adminAuditHandler.Verify)        // Verify a hash chain range (?partner_id&from_seq&to_seq)
+			auditLogs.Get("/export", adminAuditHandler.Export)        // Export as zip (?format=csv|ndjson, ?redact=none|hash|redact, same filters)
+			auditLogs.Get("/:id", adminAuditHandler.Get)              // Get audit log
+		}
+
+		// TK master data management
+		tk := admin.Group("/tk")
+		{
+			tk.Get("", adminTKHandler.List)                             // List TK data (?page, ?limit, ?q)
+			tk.Post("", adminTKHandler.Create)                          // Create TK data
+			tk.Post("/import", adminTKHandler.Import)                   // Import CSV/XLSX (?dry_run=true)
+			tk.Get("/:nik/history", adminTKHandler.History)             // Status kepesertaan timeline
+			tk.Get("/:nik/access-report", adminAccessReportHandler.Get) // Partners that looked up the NIK (?format=json|html)
+			tk.Get("/:nik", adminTKHandler.Get)                         // Get TK data by NIK
+			tk.Put("/:nik", adminTKHandler.Update)                      // Update TK data
+			tk.Delete("/:nik", adminTKHandler.Delete)                   // Delete TK data
+		}
 	}
 
 	return app
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// responseMetaKeys are check response keys that are not data fields of the worker
+var responseMetaKeys = map[string]bool{
+	"nik":         true,
+	"found":       true,
+	"last_update": true,
+	"result_code": true,
+	"receipt":     true,
+}
+
+// AccessReportService builds data-subject access reports (which partners looked up a NIK)
+type AccessReportService struct {
+	AuditRepo *repository.AuditRepository
+	Privacy   *AuditPrivacy // finds the rows stored with a NIK token
+}
+
+// NewAccessReportService creates a new access report service
+func NewAccessReportService(auditRepo *repository.AuditRepository, privacy *AuditPrivacy) *AccessReportService {
+	return &AccessReportService{
+		AuditRepo: auditRepo,
+		Privacy:   privacy,
+	}
+}
+
+// Generate builds the access report of a NIK from audit_logs. Only partner names, timestamps,
+// purposes, scopes and the names of returned fields are included, never payload values.
+func (s *AccessReportService) Generate(ctx context.Context, nik string) (*models.AccessReport, error) {
+	if _, err := utils.ParseNIK(nik); err != nil {
+		return nil, err
+	}
+
+	records, err := s.AuditRepo.GetAccessRecordsByNIK(ctx, nik, s.Privacy.NIKToken(nik))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.AccessReport{
+		NIK:          nik,
+		GeneratedAt:  time.Now(),
+		TotalLookups: len(records),
+		Entries:      make([]models.AccessReportEntry, 0, len(records)),
+	}
+	partners := make(map[string]bool)
+	for _, rec := range records {
+		partners[rec.PartnerID] = true
+		found, fields := disclosedFields(rec.ResponsePayload)
+		report.Entries = append(report.Entries, models.AccessReportEntry{
+			AuditID:     rec.AuditID,
+			PartnerID:   rec.PartnerID,
+			PartnerName: rec.PartnerName,
+			CompanyID:   rec.CompanyID,
+			AccessedAt:  rec.AccessedAt,
+			Purpose:     rec.Purpose,
+			ConsentRef:  rec.ConsentRef,
+			Found:       found,
+			Scopes:      enabledScopeNames(rec.ScopesUsed),
+			Fields:      fields,
+		})
+	}
+	report.Partners = len(partners)
+
+	return report, nil
+}
+
+// WriteHTML renders a report as a standalone, print-ready (PDF) HTML document
+func (s *AccessReportService) WriteHTML(w io.Writer, report *models.AccessReport) error {
+	return accessReportTemplate.Execute(w, report)
+}
+
+// enabledScopeNames returns the enabled scope names of an audit log's scopes_used
+func enabledScopeNames(raw json.RawMessage) []string {
+	names := []string{}
+	var scopes []models.PartnerScope
+	if err := json.Unmarshal(raw, &scopes); err != nil {
+		return names
+	}
+	for _, scope := range scopes {
+		if scope.Enabled {
+			names = append(names, scope.ScopeName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// disclosedFields returns whether a check found the worker and the data field names in its response
+// (the keys of a full response, or the "fields" list of a minimal one, whose older rows may hold
+// dotted paths reduced here to their top-level field)
+func disclosedFields(raw json.RawMessage) (bool, []string) {
+	fields := []string{}
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &response); err != nil {
+		return false, fields
+	}
+	var found bool
+	_ = json.Unmarshal(response["found"], &found) // absent on malformed rows
+
+	var names []string
+	if err := json.Unmarshal(response["fields"], &names); err != nil {
+		for key := range response {
+			names = append(names, key)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, key := range names {
+		key, _, _ = strings.Cut(key, ".")
+		if !responseMetaKeys[key] && !seen[key] {
+			seen[key] = true
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return found, fields
+}
+
+var accessReportTemplate = template.Must(template.New("access_report").Funcs(template.FuncMap{
+	"datetime": func(t time.Time) string { return t.Format("2006-01-02 15:04:05 MST") },
+	"deref": func(s *string) string {
+		if s == nil {
+			return "-"
+		}
+		return *s
+	},
+	"list": func(items []string) string {
+		if len(items) == 0 {
+			return "-"
+		}
+		return strings.Join(items, ", ")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+<meta charset="utf-8">
+<title>Laporan Akses Data Pribadi - {{.NIK}}</title>
+<style>
+  @page { size: A4 landscape; margin: 15mm; }
+  body { font-family: Arial, Helvetica, sans-serif; font-size: 11px; color: #222; }
+  h1 { font-size: 18px; margin-bottom: 4px; }
+  .meta { margin-bottom: 12px; }
+  table { width: 100%; border-collapse: collapse; }
+  th, td { border: 1px solid #999; padding: 4px 6px; text-align: left; vertical-align: top; }
+  th { background: #eee; }
+  tr { page-break-inside: avoid; }
+  .empty { font-style: italic; }
+</style>
+</head>
+<body>
+<h1>Laporan Akses Data Pribadi</h1>
+<div class="meta">
+  NIK: <strong>{{.NIK}}</strong><br>
+  Dibuat: {{datetime .GeneratedAt}}<br>
+  Jumlah pengecekan: {{.TotalLookups}} oleh {{.Partners}} mitra
+</div>
+{{if .Entries}}
+<table>
+  <thead>
+    <tr><th>Waktu</th><th>Mitra</th><th>Tujuan</th><th>Ref. Persetujuan</th><th>Ditemukan</th><th>Scope</th><th>Data yang Dibuka</th></tr>
+  </thead>
+  <tbody>
+  {{range .Entries}}
+    <tr>
+      <td>{{datetime .AccessedAt}}</td>
+      <td>{{.PartnerName}}{{if .CompanyID}} ({{.CompanyID}}){{end}}</td>
+      <td>{{deref .Purpose}}</td>
+      <td>{{deref .ConsentRef}}</td>
+      <td>{{if .Found}}Ya{{else}}Tidak{{end}}</td>
+      <td>{{list .Scopes}}</td>
+      <td>{{list .Fields}}</td>
+    </tr>
+  {{end}}
+  </tbody>
+</table>
+{{else}}
+<p class="empty">Tidak ada mitra yang melakukan pengecekan atas NIK ini.</p>
+{{end}}
+</body>
+</html>
+`))
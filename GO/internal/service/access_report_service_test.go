@@ -0,0 +1,34 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDisclosedFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		raw        string
+		wantFound  bool
+		wantFields []string
+	}{
+		{"full response", `{"found":true,"nik":"3201011501900001","nama":"Budi","alamat":"Bandung","result_code":"MATCHED"}`,
+			true, []string{"alamat", "nama"}},
+		{"minimal response", `{"fields":["nama","status_kepesertaan"],"found":true}`,
+			true, []string{"nama", "status_kepesertaan"}},
+		{"minimal response with dotted paths of older rows", `{"fields":["alamat.jalan","alamat.kota","nama"],"found":true}`,
+			true, []string{"alamat", "nama"}},
+		{"not found", `{"found":false}`, false, []string{}},
+		{"malformed", `not json`, false, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, fields := disclosedFields(json.RawMessage(tt.raw))
+			if found != tt.wantFound || !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("disclosedFields(%s) = %v %v, want %v %v", tt.raw, found, fields, tt.wantFound, tt.wantFields)
+			}
+		})
+	}
+}
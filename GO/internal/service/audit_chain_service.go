@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// maxAuditChainProblems bounds the problems listed in a verification report (all are counted in Valid)
+const maxAuditChainProblems = 1000
+
+// MaxAuditVerifyRange bounds the chain positions verified by one VerifyRange call (HTTP requests);
+// full verification runs offline with pksctl verify-audit
+const MaxAuditVerifyRange = 100000
+
+// AuditChainService signs checkpoints of the audit log hash chains and verifies the chains
+type AuditChainService struct {
+	AuditRepo *repository.AuditRepository
+
+	key   ed25519.PrivateKey // nil: no checkpoints are signed or verified
+	keyID string
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewAuditChainService creates a new audit chain service signing checkpoints with the receipt key.
+// Pass a nil key when the key is temporary: its signatures could not be verified after a restart.
+func NewAuditChainService(auditRepo *repository.AuditRepository, key ed25519.PrivateKey, keyID string) *AuditChainService {
+	return &AuditChainService{
+		AuditRepo: auditRepo,
+		key:       key,
+		keyID:     keyID,
+	}
+}
+
+// Start signs a checkpoint of every chain that moved, now and then at each interval
+func (s *AuditChainService) Start(interval time.Duration) {
+	if s.key == nil || interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if n, err := s.Checkpoint(ctx); err != nil {
+				log.Printf("AuditChainService - checkpoint failed: %v", err)
+			} else if n > 0 {
+				log.Printf("AuditChainService - signed %d audit chain checkpoints", n)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the checkpoint loop and waits for it
+func (s *AuditChainService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+// Checkpoint signs the current head of every chain that moved since its latest checkpoint
+// and returns the number of checkpoints written
+func (s *AuditChainService) Checkpoint(ctx context.Context) (int, error) {
+	if s.key == nil {
+		return 0, fmt.Errorf("no persistent signing key configured (RECEIPT_SIGNING_KEY)")
+	}
+
+	heads, err := s.AuditRepo.GetUncheckpointedHeads(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, head := range heads {
+		signature, err := utils.SignCheckpoint(&utils.CheckpointClaims{
+			PartnerID: head.PartnerID,
+			ChainSeq:  head.LastSeq,
+			RowHash:   head.LastHash,
+			RegisteredClaims: jwt.RegisteredClaims{
+				IssuedAt: jwt.NewNumericDate(time.Now()),
+			},
+		}, s.key, s.keyID)
+		if err != nil {
+			return i, fmt.Errorf("failed to sign checkpoint: %w", err)
+		}
+
+		err = s.AuditRepo.CreateCheckpoint(ctx, &models.AuditChainCheckpoint{
+			PartnerID: head.PartnerID,
+			ChainSeq:  head.LastSeq,
+			RowHash:   head.LastHash,
+			KeyID:     s.keyID,
+			Signature: signature,
+		})
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(heads), nil
+}
+
+// VerifyRange verifies the chain positions fromSeq to toSeq of one partner's chain, at most
+// MaxAuditVerifyRange of them, so a request does a bounded amount of work
+func (s *AuditChainService) VerifyRange(ctx context.Context, partnerID string, fromSeq, toSeq int64) (*models.AuditChainReport, error) {
+	if partnerID == "" {
+		return nil, &utils.ValidationError{Field: "partner_id", Message: "partner_id is required"}
+	}
+	if fromSeq < 1 || toSeq < 1 {
+		return nil, &utils.ValidationError{Field: "from_seq", Message: "from_seq and to_seq are required and must be at least 1"}
+	}
+	if toSeq-fromSeq >= MaxAuditVerifyRange {
+		return nil, &utils.ValidationError{Field: "to_seq",
+			Message: fmt.Sprintf("at most %d chain positions per request, use pksctl verify-audit for a full verification", MaxAuditVerifyRange)}
+	}
+	return s.Verify(ctx, partnerID, fromSeq, toSeq)
+}
+
+// Verify walks the hash chains (one partner, or all when partnerID is empty) and reports every
+// gap, duplicate, broken link, modified row, deleted tail and checkpoint that does not match.
+// Each chain starts after its purged_seq: the rows before it were dropped by the retention policy.
+// fromSeq and toSeq (0 = unbounded, partnerID required otherwise) limit the walk to part of the
+// chain; the row before fromSeq anchors the first link.
+func (s *AuditChainService) Verify(ctx context.Context, partnerID string, fromSeq, toSeq int64) (*models.AuditChainReport, error) {
+	if partnerID != "" {
+		if _, err := uuid.Parse(partnerID); err != nil {
+			return nil, &utils.ValidationError{Field: "partner_id", Message: "partner_id must be a valid UUID"}
+		}
+	}
+	if fromSeq < 0 || toSeq < 0 || (toSeq > 0 && toSeq < fromSeq) {
+		return nil, &utils.ValidationError{Field: "to_seq", Message: "to_seq must not be before from_seq"}
+	}
+	if partnerID == "" && (fromSeq > 0 || toSeq > 0) {
+		return nil, &utils.ValidationError{Field: "partner_id", Message: "a chain position range requires partner_id"}
+	}
+
+	report := &models.AuditChainReport{
+		CheckedAt:           time.Now(),
+		PartnerID:           partnerID,
+		FromSeq:             fromSeq,
+		ToSeq:               toSeq,
+		CheckpointsVerified: s.key != nil,
+		Problems:            []models.AuditChainProblem{},
+	}
+	addProblem := func(p models.AuditChainProblem) {
+		if len(report.Problems) < maxAuditChainProblems {
+			report.Problems = append(report.Problems, p)
+		} else {
+			report.ProblemsTruncated = true
+		}
+	}
+
+	unchained, err := s.AuditRepo.CountUnchained(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+	report.Unchained = unchained
+
+	heads, err := s.AuditRepo.GetChainHeads(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+	headByPartner := make(map[string]*models.AuditChainHead, len(heads))
+	for _, head := range heads {
+		headByPartner[head.PartnerID] = head
+	}
+	if head := headByPartner[partnerID]; head != nil && fromSeq > head.LastSeq {
+		return nil, &utils.ValidationError{Field: "from_seq", Message: fmt.Sprintf("from_seq is past the chain head (%d)", head.LastSeq)}
+	}
+	checkpoints, err := s.checkpointsBySeq(ctx, partnerID, report, addProblem)
+	if err != nil {
+		return nil, err
+	}
+
+	// A range starting after the purged rows is anchored on the row before it, walked first with
+	// an unknown link (its own hash is still checked)
+	streamFrom := fromSeq
+	if head := headByPartner[partnerID]; fromSeq > 1 && (head == nil || fromSeq-1 > head.PurgedSeq) {
+		streamFrom = fromSeq - 1
+	}
+	inRange := func(seq int64) bool {
+		return seq >= fromSeq && (toSeq == 0 || seq <= toSeq)
+	}
+
+	// Chain state of the partner being walked; an empty lastHash is an unknown link
+	var current string
+	var lastSeq int64
+	lastHash := utils.AuditChainGenesis
+	walked := make(map[string]bool)
+	start := func(partner string) {
+		current, lastSeq, lastHash = partner, 0, utils.AuditChainGenesis
+		if head := headByPartner[partner]; head != nil && head.PurgedSeq > 0 {
+			lastSeq, lastHash = head.PurgedSeq, head.PurgedHash
+		}
+		if streamFrom > 0 && streamFrom-1 > lastSeq {
+			lastSeq, lastHash = streamFrom-1, ""
+		}
+	}
+
+	finish := func(partner string) {
+		walked[partner] = true
+		report.Partners++
+		head := headByPartner[partner]
+		switch {
+		case head == nil:
+			addProblem(models.AuditChainProblem{PartnerID: partner, ChainSeq: lastSeq, Kind: models.AuditChainHeadMismatch,
+				Detail: "chain head is missing"})
+		case toSeq > 0 && toSeq < head.LastSeq:
+			// The range ends before the head: its last positions must still be there
+			if lastSeq < toSeq {
+				addProblem(models.AuditChainProblem{PartnerID: partner, ChainSeq: toSeq, Kind: models.AuditChainGap,
+					Detail: fmt.Sprintf("rows %d to %d are missing", lastSeq+1, toSeq)})
+			}
+		case head.LastSeq != lastSeq || head.LastHash != lastHash:
+			addProblem(models.AuditChainProblem{PartnerID: partner, ChainSeq: head.LastSeq, Kind: models.AuditChainHeadMismatch,
+				Detail: fmt.Sprintf("chain head is at %d but the last row is at %d", head.LastSeq, lastSeq)})
+		}
+		for seq := range checkpoints[partner] {
+			if seq > lastSeq && inRange(seq) {
+				addProblem(models.AuditChainProblem{PartnerID: partner, ChainSeq: seq, Kind: models.AuditChainCheckpointMissing,
+					Detail: "signed checkpoint points past the last row"})
+			}
+		}
+	}
+
+	err = s.AuditRepo.StreamChain(ctx, partnerID, streamFrom, toSeq, func(row *models.AuditChainRow) error {
+		if row.PartnerID != current {
+			if current != "" {
+				finish(current)
+			}
+			start(row.PartnerID)
+		}
+		report.Rows++
+
+		problem := models.AuditChainProblem{PartnerID: row.PartnerID, ChainSeq: row.ChainSeq, AuditID: row.ID}
+		if row.ChainSeq <= lastSeq {
+			problem.Kind = models.AuditChainDuplicate
+			problem.Detail = fmt.Sprintf("chain position is at or before %d, already walked or purged", lastSeq)
+			addProblem(problem)
+		} else if row.ChainSeq != lastSeq+1 {
+			problem.Kind = models.AuditChainGap
+			problem.Detail = fmt.Sprintf("rows %d to %d are missing", lastSeq+1, row.ChainSeq-1)
+			addProblem(problem)
+		} else if lastHash != "" && row.PrevHash != lastHash {
+			problem.Kind = models.AuditChainBrokenLink
+			problem.Detail = "prev_hash does not match the previous row"
+			addProblem(problem)
+		}
+
+		hash, err := utils.AuditRowHash(auditChainRecordOf(row))
+		if err != nil || hash != row.RowHash {
+			problem.Kind = models.AuditChainModified
+			problem.Detail = "row content does not match row_hash"
+			addProblem(problem)
+		}
+		if signed, ok := checkpoints[row.PartnerID][row.ChainSeq]; ok && inRange(row.ChainSeq) && signed != row.RowHash {
+			problem.Kind = models.AuditChainCheckpointMismatch
+			problem.Detail = "row_hash differs from the signed checkpoint"
+			addProblem(problem)
+		}
+
+		if row.ChainSeq > lastSeq {
+			lastSeq, lastHash = row.ChainSeq, row.RowHash
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if current != "" {
+		finish(current)
+	}
+
+	// Chains whose remaining rows were all deleted (fully purged chains end at their head)
+	for _, head := range heads {
+		if !walked[head.PartnerID] && head.LastSeq > head.PurgedSeq {
+			start(head.PartnerID)
+			finish(head.PartnerID)
+		}
+	}
+
+	report.Valid = len(report.Problems) == 0 && !report.ProblemsTruncated
+	return report, nil
+}
+
+// checkpointsBySeq loads the checkpoints, reports those with an invalid signature and returns the
+// signed row_hash per partner and chain position. Without a key the stored values are used as-is.
+func (s *AuditChainService) checkpointsBySeq(
+	ctx context.Context,
+	partnerID string,
+	report *models.AuditChainReport,
+	addProblem func(models.AuditChainProblem),
+) (map[string]map[int64]string, error) {
+	checkpoints, err := s.AuditRepo.GetCheckpoints(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+	report.Checkpoints = len(checkpoints)
+
+	signed := make(map[string]map[int64]string)
+	for _, cp := range checkpoints {
+		if s.key != nil {
+			claims, err := utils.ParseCheckpoint(cp.Signature, s.key.Public().(ed25519.PublicKey), s.keyID)
+			if err != nil || claims.PartnerID != cp.PartnerID || claims.ChainSeq != cp.ChainSeq || claims.RowHash != cp.RowHash {
+				addProblem(models.AuditChainProblem{PartnerID: cp.PartnerID, ChainSeq: cp.ChainSeq, Kind: models.AuditChainCheckpointInvalid,
+					Detail: "checkpoint signature is invalid or does not match the stored checkpoint"})
+				continue
+			}
+		}
+		if signed[cp.PartnerID] == nil {
+			signed[cp.PartnerID] = make(map[int64]string)
+		}
+		signed[cp.PartnerID][cp.ChainSeq] = cp.RowHash
+	}
+
+	return signed, nil
+}
+
+// auditChainRecordOf returns the hashed content of a stored audit chain row
+func auditChainRecordOf(row *models.AuditChainRow) utils.AuditChainRecord {
+	return utils.AuditChainRecord{
+		ID:              row.ID,
+		PartnerID:       row.PartnerID,
+		UserID:          row.UserID,
+		NIK:             row.NIK,
+		ScopesUsed:      row.ScopesUsed,
+		RequestPayload:  row.RequestPayload,
+		ResponsePayload: row.ResponsePayload,
+		ResultCode:      row.ResultCode,
+		Purpose:         row.Purpose,
+		ConsentRef:      row.ConsentRef,
+		APIKeyID:        row.APIKeyID,
+		CreatedAt:       row.CreatedAt,
+		ChainSeq:        row.ChainSeq,
+		PrevHash:        row.PrevHash,
+	}
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+)
+
+// AuditPartitionConfig configures the audit partition maintenance
+type AuditPartitionConfig struct {
+	AheadMonths     int    // monthly partitions kept created after the current month
+	RetentionMonths int    // full months kept before the current one; 0 keeps every partition
+	ArchiveDir      string // directory receiving archived partitions (.ndjson.gz + .sha256)
+}
+
+// AuditPartitionService creates the monthly audit_logs partitions ahead of time and applies the
+// retention policy: expired partitions are detached, archived to a gzip NDJSON file, then dropped.
+// Each step can be interrupted: the next run resumes detached partitions where they were left.
+type AuditPartitionService struct {
+	AuditRepo *repository.AuditRepository
+	Config    AuditPartitionConfig
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewAuditPartitionService creates a new audit partition service
+func NewAuditPartitionService(auditRepo *repository.AuditRepository, cfg AuditPartitionConfig) *AuditPartitionService {
+	if cfg.AheadMonths < 1 {
+		cfg.AheadMonths = 3
+	}
+	if cfg.RetentionMonths < 0 {
+		cfg.RetentionMonths = 0
+	}
+	if cfg.ArchiveDir == "" {
+		cfg.ArchiveDir = "audit-archive"
+	}
+
+	return &AuditPartitionService{
+		AuditRepo: auditRepo,
+		Config:    cfg,
+	}
+}
+
+// Start runs the maintenance now and then at each interval
+func (s *AuditPartitionService) Start(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			report, err := s.Maintain(ctx)
+			switch {
+			case errors.Is(err, repository.ErrAuditMaintenanceRunning):
+			case err != nil:
+				log.Printf("AuditPartitionService - maintenance failed: %v", err)
+			default:
+				if len(report.Created) > 0 || len(report.Dropped) > 0 {
+					log.Printf("AuditPartitionService - created %v (%d rows moved from the default partition), archived and dropped %v",
+						report.Created, report.MovedRows, report.Dropped)
+				}
+				if report.DefaultRows > 0 {
+					log.Printf("WARNING: %d audit logs are in audit_logs_default (outside every monthly partition)", report.DefaultRows)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the maintenance loop and waits for it (a running archive is interrupted and resumed next time)
+func (s *AuditPartitionService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+// Maintain creates the partitions of the current month and the AheadMonths following ones, then
+// archives and drops the partitions older than RetentionMonths. A partition that cannot be created
+// is reported in Failed and does not hold up retention. Only one process maintains the partitions
+// at a time; the others get repository.ErrAuditMaintenanceRunning.
+func (s *AuditPartitionService) Maintain(ctx context.Context) (*models.AuditPartitionReport, error) {
+	unlock, err := s.AuditRepo.LockAuditMaintenance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	report := &models.AuditPartitionReport{
+		RanAt:    now,
+		Created:  []string{},
+		Failed:   []string{},
+		Archived: []models.AuditPartitionArchive{},
+		Dropped:  []string{},
+	}
+
+	for i := 0; i <= s.Config.AheadMonths; i++ {
+		name := repository.AuditPartitionName(month.AddDate(0, i, 0))
+		created, moved, err := s.AuditRepo.CreatePartition(ctx, month.AddDate(0, i, 0))
+		if err != nil {
+			log.Printf("AuditPartitionService - %v", err)
+			report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if created {
+			report.Created = append(report.Created, name)
+			report.MovedRows += moved
+		}
+	}
+
+	if s.Config.RetentionMonths > 0 {
+		cutoff := month.AddDate(0, -s.Config.RetentionMonths, 0)
+		partitions, err := s.AuditRepo.ListPartitions(ctx)
+		if err != nil {
+			return report, err
+		}
+		for _, p := range partitions {
+			if p.To.After(cutoff) {
+				continue
+			}
+			archive, err := s.purge(ctx, p)
+			if err != nil {
+				return report, err
+			}
+			report.Archived = append(report.Archived, *archive)
+			report.Dropped = append(report.Dropped, p.Name)
+		}
+	}
+
+	if report.DefaultRows, err = s.AuditRepo.CountDefaultPartition(ctx); err != nil {
+		return report, err
+	}
+	if report.Partitions, err = s.AuditRepo.ListPartitions(ctx); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// purge detaches (unless already detached), archives and drops an expired partition
+func (s *AuditPartitionService) purge(ctx context.Context, p *models.AuditPartition) (*models.AuditPartitionArchive, error) {
+	if p.Attached {
+		if err := s.AuditRepo.DetachPartition(ctx, p.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	archive, err := s.archive(ctx, p.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive audit partition %s: %w", p.Name, err)
+	}
+
+	if err := s.AuditRepo.DropPartition(ctx, p.Name); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// archive writes the rows of a detached partition to <ArchiveDir>/<partition>.ndjson.gz with a
+// sha256sum-compatible checksum file. The file is written under a temporary name and synced
+// before it is renamed, so an existing archive is always complete.
+func (s *AuditPartitionService) archive(ctx context.Context, name string) (*models.AuditPartitionArchive, error) {
+	if err := os.MkdirAll(s.Config.ArchiveDir, 0o750); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(s.Config.ArchiveDir, name+".ndjson.gz")
+	tmp, err := os.CreateTemp(s.Config.ArchiveDir, name+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	archive := &models.AuditPartitionArchive{Partition: name, File: path}
+	hash := sha256.New()
+	buf := bufio.NewWriter(io.MultiWriter(tmp, hash))
+	gz := gzip.NewWriter(buf)
+	encoder := json.NewEncoder(gz)
+
+	err = s.AuditRepo.StreamPartition(ctx, name, func(row *models.AuditChainRow) error {
+		archive.Rows++
+		return encoder.Encode(row)
+	})
+	if err == nil {
+		err = gz.Close()
+	}
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	archive.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	checksum := fmt.Sprintf("%s  %s\n", archive.SHA256, filepath.Base(path))
+	if err := os.WriteFile(path+".sha256", []byte(checksum), 0o640); err != nil {
+		return nil, err
+	}
+
+	// Make the renames durable before the partition is dropped
+	dir, err := os.Open(s.Config.ArchiveDir)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	if err := dir.Sync(); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
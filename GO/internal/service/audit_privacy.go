@@ -0,0 +1,240 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// Payload policies applied to stored audit logs
+const (
+	AuditPayloadMinimal = "minimal" // field names and non-personal values only (see minimizePayload)
+	AuditPayloadFull    = "full"    // request and filtered response stored as-is
+)
+
+// auditDebugTTL bounds how long another process may use a stale list of debugging partners
+const auditDebugTTL = time.Minute
+
+// maxAuditDebugHours bounds how long a partner's full payloads can be stored
+const maxAuditDebugHours = 7 * 24
+
+// AuditPrivacy applies the audit redaction policy: the NIK is stored as a keyed token and the
+// payloads as field lists, except for partners flagged for debugging (full payloads until a deadline)
+type AuditPrivacy struct {
+	PartnerRepo *repository.PartnerRepository
+	Policy      string
+
+	nikKey []byte // nil: the NIK is stored in plaintext
+
+	mu       sync.RWMutex
+	debug    map[string]time.Time
+	loadedAt time.Time
+}
+
+// NewAuditPrivacy creates a new audit privacy policy. Without a NIK key the NIK stays in plaintext.
+func NewAuditPrivacy(partnerRepo *repository.PartnerRepository, policy string, nikKey []byte) *AuditPrivacy {
+	switch policy {
+	case AuditPayloadMinimal, AuditPayloadFull:
+	default:
+		log.Printf("WARNING: unknown audit payload policy %q, using %s", policy, AuditPayloadMinimal)
+		policy = AuditPayloadMinimal
+	}
+
+	return &AuditPrivacy{
+		PartnerRepo: partnerRepo,
+		Policy:      policy,
+		nikKey:      nikKey,
+	}
+}
+
+// NIKToken returns the stored token of a plaintext NIK ("" without a NIK key)
+func (p *AuditPrivacy) NIKToken(nik string) string {
+	if p == nil || p.nikKey == nil || nik == "" {
+		return ""
+	}
+	return utils.NIKToken(p.nikKey, nik)
+}
+
+// HasNIKKey reports whether NIKs are tokenized with a key
+func (p *AuditPrivacy) HasNIKKey() bool {
+	return p != nil && p.nikKey != nil
+}
+
+// ValueHash returns the keyed HMAC-SHA256 hex of a payload value for hashed exports ("" without a NIK key)
+func (p *AuditPrivacy) ValueHash(value string) string {
+	if !p.HasNIKKey() {
+		return ""
+	}
+	mac := hmac.New(sha256.New, p.nikKey)
+	mac.Write([]byte("value:" + value)) // domain-separated from NIK tokens
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NIKHash returns the keyed hash of a plaintext NIK put in receipts ("" without a NIK key)
+func (p *AuditPrivacy) NIKHash(nik string) string {
+	if p == nil || p.nikKey == nil || nik == "" {
+		return ""
+	}
+	return utils.NIKKeyedHash(p.nikKey, nik)
+}
+
+// MatchesNIKHash reports whether a stored NIK (plaintext or token) is the NIK of a receipt's
+// nik_hash. Receipts signed before nik_hash was keyed carry the plain SHA-256 of the NIK.
+func (p *AuditPrivacy) MatchesNIKHash(stored, nikHash string) bool {
+	if stored == "" || nikHash == "" {
+		return false
+	}
+	if !utils.IsNIKToken(stored) {
+		if keyed := p.NIKHash(stored); keyed != "" && hmac.Equal([]byte(keyed), []byte(nikHash)) {
+			return true
+		}
+		return utils.HashNIK(stored) == nikHash
+	}
+	if p == nil || p.nikKey == nil {
+		return false
+	}
+	return hmac.Equal([]byte(utils.NIKTokenPrefix+nikHash), []byte(stored)) ||
+		hmac.Equal([]byte(utils.NIKTokenOfHash(p.nikKey, nikHash)), []byte(stored))
+}
+
+// Apply redacts an audit log before it is queued: NIK token, then minimal payloads unless the
+// policy is full or the partner is debugging. A failed debug lookup falls back to minimal payloads.
+func (p *AuditPrivacy) Apply(ctx context.Context, entry *models.CreateAuditLogRequest) error {
+	if token := p.NIKToken(entry.NIK); token != "" && !utils.IsNIKToken(entry.NIK) {
+		entry.NIK = token
+	}
+	if p.Policy == AuditPayloadFull {
+		return nil
+	}
+
+	debug, err := p.isDebugging(ctx, entry.PartnerID)
+	if err != nil {
+		log.Printf("AuditPrivacy - debug lookup failed, storing minimal payloads: %v", err)
+	}
+	if debug {
+		return nil
+	}
+
+	if entry.RequestPayload, err = minimizePayload(entry.RequestPayload); err != nil {
+		return fmt.Errorf("invalid request payload: %w", err)
+	}
+	if entry.ResponsePayload, err = minimizePayload(entry.ResponsePayload); err != nil {
+		return fmt.Errorf("invalid response payload: %w", err)
+	}
+	return nil
+}
+
+// SetDebug stores a partner's full audit payloads for the given number of hours (0 stops debugging)
+func (p *AuditPrivacy) SetDebug(ctx context.Context, partnerID string, hours int) (*time.Time, error) {
+	if hours < 0 || hours > maxAuditDebugHours {
+		return nil, &utils.ValidationError{Field: "hours", Message: fmt.Sprintf("hours must be between 0 and %d", maxAuditDebugHours)}
+	}
+
+	var until *time.Time
+	if hours > 0 {
+		t := time.Now().Add(time.Duration(hours) * time.Hour)
+		until = &t
+	}
+	if err := p.PartnerRepo.SetAuditDebugUntil(ctx, partnerID, until); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.debug = nil
+	p.mu.Unlock()
+
+	return until, nil
+}
+
+// isDebugging reports whether the partner's full payloads are stored (cached list of debugging partners)
+func (p *AuditPrivacy) isDebugging(ctx context.Context, partnerID string) (bool, error) {
+	p.mu.RLock()
+	debug, loadedAt := p.debug, p.loadedAt
+	p.mu.RUnlock()
+
+	if debug == nil || time.Since(loadedAt) >= auditDebugTTL {
+		var err error
+		if debug, err = p.PartnerRepo.GetAuditDebugPartners(ctx); err != nil {
+			return false, err
+		}
+		p.mu.Lock()
+		p.debug, p.loadedAt = debug, time.Now()
+		p.mu.Unlock()
+	}
+
+	until, ok := debug[partnerID]
+	return ok && time.Now().Before(until), nil
+}
+
+// minimizePayload keeps the values under auditSafePayloadKeys and replaces every other value by
+// its top-level field name in a sorted "fields" list, e.g. {"fields": ["nama", "nik"], "found": true}
+// (the same names a full payload has as keys, see disclosedFields)
+func minimizePayload(payload interface{}) (json.RawMessage, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	minimal, ok := minimizeObject(decoded, "", seen).(map[string]interface{})
+	if !ok {
+		minimal = map[string]interface{}{}
+	}
+	fields := make([]string, 0, len(seen))
+	for field := range seen {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	minimal["fields"] = fields
+
+	return json.Marshal(minimal)
+}
+
+// minimizeObject returns the safe values of a decoded JSON object and records in fields the
+// top-level field (field, empty at the top) holding each of the others
+func minimizeObject(v interface{}, field string, fields map[string]bool) interface{} {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		if v != nil && field != "" {
+			fields[field] = true
+		}
+		return nil
+	}
+
+	kept := make(map[string]interface{})
+	for key, child := range obj {
+		childField := field
+		if field == "" {
+			childField = key
+		}
+		if auditSafePayloadKeys[key] {
+			kept[key] = child
+			continue
+		}
+		if minimal := minimizeObject(child, childField, fields); minimal != nil {
+			if m := minimal.(map[string]interface{}); len(m) > 0 {
+				kept[key] = m
+			}
+		}
+	}
+	return kept
+}
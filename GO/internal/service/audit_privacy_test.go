@@ -0,0 +1,104 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/username/go-gin-backend/internal/models"
+)
+
+func TestMinimizePayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload interface{}
+		want    string
+	}{
+		{
+			name: "check response keeps safe values and lists data fields",
+			payload: models.CheckTKResponse{
+				"found":              true,
+				"result_code":        "MATCHED",
+				"nama":               "Budi Santoso",
+				"nik":                "3201011501900001",
+				"status_kepesertaan": "AKTIF",
+			},
+			want: `{"fields":["nama","nik","status_kepesertaan"],"found":true,"result_code":"MATCHED"}`,
+		},
+		{
+			name: "check request",
+			payload: models.CheckTKRequest{
+				NIK:          "3201011501900001",
+				TanggalLahir: "1990-01-15",
+				Purpose:      "kyc_onboarding",
+			},
+			want: `{"fields":["nik","tanggal_lahir"],"purpose":"kyc_onboarding"}`,
+		},
+		{
+			name: "nested objects are listed by their top-level field",
+			payload: map[string]interface{}{
+				"found": true,
+				"alamat": map[string]interface{}{
+					"jalan": "Jl. Merdeka 1",
+					"kota":  "Bandung",
+				},
+				"matches": map[string]interface{}{
+					"nama":          map[string]interface{}{"result": "MATCH", "score": 0.97},
+					"tanggal_lahir": map[string]interface{}{"result": "NO_MATCH", "raw": "1990-01-15"},
+				},
+			},
+			want: `{"fields":["alamat","matches"],"found":true,"matches":{"nama":{"result":"MATCH","score":0.97},` +
+				`"tanggal_lahir":{"result":"NO_MATCH"}}}`,
+		},
+		{
+			name:    "numbers are kept as written",
+			payload: json.RawMessage(`{"found":true,"score":0.1000,"id":12345678901234567890}`),
+			want:    `{"fields":["id"],"found":true,"score":0.1000}`,
+		},
+		{
+			name:    "null values are not listed",
+			payload: map[string]interface{}{"found": false, "nama": nil},
+			want:    `{"fields":[],"found":false}`,
+		},
+		{
+			name:    "non-object payload",
+			payload: []string{"3201011501900001"},
+			want:    `{"fields":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := minimizePayload(tt.payload)
+			if err != nil {
+				t.Fatalf("minimizePayload() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("minimizePayload() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinimizePayloadMatchesDisclosedFields(t *testing.T) {
+	// The access report lists the same fields for a minimal row as for the full one
+	full := map[string]interface{}{
+		"found":  true,
+		"nama":   "Budi Santoso",
+		"alamat": map[string]interface{}{"kota": "Bandung"},
+	}
+	fullRaw, err := json.Marshal(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minimal, err := minimizePayload(full)
+	if err != nil {
+		t.Fatalf("minimizePayload() error = %v", err)
+	}
+
+	fullFound, fullFields := disclosedFields(fullRaw)
+	minFound, minFields := disclosedFields(minimal)
+	if fullFound != minFound || !reflect.DeepEqual(fullFields, minFields) {
+		t.Errorf("disclosedFields() minimal = %v %v, full = %v %v", minFound, minFields, fullFound, fullFields)
+	}
+}
@@ -0,0 +1,437 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// Audit log search page sizes
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 500
+)
+
+// auditExportColumns is the CSV header of an audit log export
+var auditExportColumns = []string{
+	"id", "created_at", "partner_id", "user_id", "nik", "result_code", "purpose", "consent_ref",
+	"scopes_used", "request_payload", "response_payload", "api_key_id",
+}
+
+// auditSafePayloadKeys are payload keys kept as-is by a redacted export: they describe
+// the check, not the worker
+var auditSafePayloadKeys = map[string]bool{
+	"found":       true,
+	"result_code": true,
+	"purpose":     true,
+	"consent_ref": true,
+	"as_of":       true,
+	"result":      true, // attribute match result (verify)
+	"score":       true, // attribute similarity (verify)
+	"fields":      true, // field names of a minimal payload (AuditPrivacy)
+}
+
+// redactedValue replaces payload values in a redact-mode export
+const redactedValue = "[REDACTED]"
+
+// AuditService handles the admin audit log search
+type AuditService struct {
+	AuditRepo *repository.AuditRepository
+	Privacy   *AuditPrivacy // NIK token of the nik filter and partner debugging
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(auditRepo *repository.AuditRepository, privacy *AuditPrivacy) *AuditService {
+	return &AuditService{
+		AuditRepo: auditRepo,
+		Privacy:   privacy,
+	}
+}
+
+// SetPartnerDebug stores the full audit payloads of a partner for the given number of hours (0 stops)
+func (s *AuditService) SetPartnerDebug(ctx context.Context, partnerID string, req *models.UpdateAuditDebugRequest) (*models.AuditDebugStatus, error) {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, repository.ErrPartnerNotFound
+	}
+	if req.Hours == nil {
+		return nil, &utils.ValidationError{Field: "hours", Message: "hours is required (0 stops debugging)"}
+	}
+
+	until, err := s.Privacy.SetDebug(ctx, partnerID, *req.Hours)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuditDebugStatus{PartnerID: partnerID, AuditDebugUntil: until}, nil
+}
+
+// Get retrieves a single audit log (nil if not found)
+func (s *AuditService) Get(ctx context.Context, id string) (*models.AuditLog, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	return s.AuditRepo.GetByID(ctx, id)
+}
+
+// Search validates the search request and returns one page of audit logs with the cursor of the next page
+func (s *AuditService) Search(ctx context.Context, req *models.AuditLogSearchRequest) (*models.AuditLogPage, error) {
+	filter, err := parseAuditSearch(req)
+	if err != nil {
+		return nil, err
+	}
+	filter.NIKToken = s.Privacy.NIKToken(filter.NIK)
+
+	logs, err := s.AuditRepo.Search(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.AuditLogPage{Items: logs}
+	if len(logs) > filter.Limit {
+		page.Items = logs[:filter.Limit]
+		last := page.Items[len(page.Items)-1]
+		cursor := encodeAuditCursor(last.CreatedAt, last.ID)
+		page.NextCursor = &cursor
+	}
+
+	return page, nil
+}
+
+// AuditExport is a validated audit log export, ready to be written
+type AuditExport struct {
+	Filter    models.AuditLogFilter
+	Format    string
+	Redact    string
+	CreatedAt time.Time
+}
+
+// FileName returns the name of the export file (audit-export-<UTC timestamp>.<format>)
+func (e *AuditExport) FileName() string {
+	return fmt.Sprintf("audit-export-%s.%s", e.CreatedAt.UTC().Format("20060102T150405Z"), e.Format)
+}
+
+// NewExport validates an export request. The filters are those of Search; every matching row is exported.
+// Without redact the export is redacted (hashed when AUDIT_NIK_KEY is set); plaintext needs redact=none.
+func (s *AuditService) NewExport(req *models.AuditExportRequest) (*AuditExport, error) {
+	filter, err := parseAuditSearch(&models.AuditLogSearchRequest{
+		PartnerID: req.PartnerID,
+		NIK:       req.NIK,
+		From:      req.From,
+		To:        req.To,
+		Outcome:   req.Outcome,
+		Scope:     req.Scope,
+	})
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = 0
+	filter.NIKToken = s.Privacy.NIKToken(filter.NIK)
+
+	export := &AuditExport{Filter: filter, Format: req.Format, Redact: req.Redact, CreatedAt: time.Now()}
+	if export.Format == "" {
+		export.Format = models.AuditExportFormatCSV
+	}
+	if export.Format != models.AuditExportFormatCSV && export.Format != models.AuditExportFormatNDJSON {
+		return nil, &utils.ValidationError{Field: "format", Message: "format must be csv or ndjson"}
+	}
+	if export.Redact == "" {
+		export.Redact = models.AuditRedactRedact
+		if s.Privacy.HasNIKKey() {
+			export.Redact = models.AuditRedactHash
+		}
+	}
+	switch export.Redact {
+	case models.AuditRedactNone, models.AuditRedactHash, models.AuditRedactRedact:
+	default:
+		return nil, &utils.ValidationError{Field: "redact", Message: fmt.Sprintf(
+			"redact must be %s, %s or %s", models.AuditRedactNone, models.AuditRedactHash, models.AuditRedactRedact)}
+	}
+	// A plain hash of a NIK or a birth date is reversed by brute force, so hash mode is keyed
+	if export.Redact == models.AuditRedactHash && !s.Privacy.HasNIKKey() {
+		return nil, &utils.ValidationError{Field: "redact", Message: "redact=hash requires AUDIT_NIK_KEY"}
+	}
+
+	return export, nil
+}
+
+// CheckExport runs the export query for a single row, so that database errors are reported
+// before the response is committed to a stream
+func (s *AuditService) CheckExport(ctx context.Context, export *AuditExport) error {
+	filter := export.Filter
+	filter.Limit = 0 // Search reads Limit+1 rows
+	if _, err := s.AuditRepo.Search(ctx, filter); err != nil {
+		return err
+	}
+	return nil
+}
+
+// WriteExport streams the matching audit logs, oldest first, to w as CSV or NDJSON and returns
+// the row count and the SHA-256 of the written bytes. Rows are never buffered in memory.
+func (s *AuditService) WriteExport(ctx context.Context, export *AuditExport, w io.Writer) (*models.AuditExportResult, error) {
+	hash := sha256.New()
+	out := io.MultiWriter(w, hash)
+	result := &models.AuditExportResult{}
+
+	var write func(*models.AuditLog) error
+	var flush func() error
+	if export.Format == models.AuditExportFormatNDJSON {
+		encoder := json.NewEncoder(out)
+		write = func(entry *models.AuditLog) error { return encoder.Encode(entry) }
+		flush = func() error { return nil }
+	} else {
+		writer := csv.NewWriter(out)
+		if err := writer.Write(auditExportColumns); err != nil {
+			return nil, err
+		}
+		record := make([]string, len(auditExportColumns))
+		write = func(entry *models.AuditLog) error {
+			record[0] = entry.ID
+			record[1] = entry.CreatedAt.UTC().Format(time.RFC3339Nano)
+			record[2] = entry.PartnerID
+			record[3] = derefString(entry.UserID)
+			record[4] = entry.NIK
+			record[5] = derefString(entry.ResultCode)
+			record[6] = derefString(entry.Purpose)
+			record[7] = derefString(entry.ConsentRef)
+			record[8] = string(entry.ScopesUsed)
+			record[9] = string(entry.RequestPayload)
+			record[10] = string(entry.ResponsePayload)
+			record[11] = derefString(entry.APIKeyID)
+			return writer.Write(record)
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	}
+
+	err := s.AuditRepo.Stream(ctx, export.Filter, func(entry *models.AuditLog) error {
+		if err := redactAuditLog(entry, export.Redact, s.Privacy); err != nil {
+			return fmt.Errorf("audit log %s: %w", entry.ID, err)
+		}
+		result.Rows++
+		return write(entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	result.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return result, nil
+}
+
+// redactAuditLog applies a redaction mode to the NIK and payloads of an exported audit log.
+// Hash mode uses the keyed NIK token and keyed value hashes of privacy.
+func redactAuditLog(entry *models.AuditLog, mode string, privacy *AuditPrivacy) error {
+	if mode == models.AuditRedactNone {
+		return nil
+	}
+
+	switch {
+	case utils.IsNIKToken(entry.NIK): // already pseudonymous, kept so rows of a worker stay linkable
+	case mode == models.AuditRedactHash:
+		entry.NIK = privacy.NIKToken(entry.NIK)
+	default:
+		entry.NIK = maskExportNIK(entry.NIK)
+	}
+
+	var err error
+	if entry.RequestPayload, err = redactPayload(entry.RequestPayload, mode, privacy); err != nil {
+		return fmt.Errorf("invalid request_payload: %w", err)
+	}
+	if entry.ResponsePayload, err = redactPayload(entry.ResponsePayload, mode, privacy); err != nil {
+		return fmt.Errorf("invalid response_payload: %w", err)
+	}
+	return nil
+}
+
+// maskExportNIK keeps the region code (first 6 digits) and the last 4 digits of a NIK
+func maskExportNIK(nik string) string {
+	if len(nik) != 16 {
+		return utils.MaskValue(nik, 0, false)
+	}
+	return nik[:6] + "******" + nik[12:]
+}
+
+// redactPayload replaces every leaf value of a JSON payload, except those under auditSafePayloadKeys,
+// by its keyed hash (hash mode, equal values stay joinable) or by redactedValue
+func redactPayload(raw json.RawMessage, mode string, privacy *AuditPrivacy) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		return raw, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var payload interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(redactValue(payload, "", mode, privacy))
+}
+
+// redactValue redacts a decoded JSON value found under key
+func redactValue(v interface{}, key, mode string, privacy *AuditPrivacy) interface{} {
+	var leaf string
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		for k, child := range val {
+			val[k] = redactValue(child, k, mode, privacy)
+		}
+		return val
+	case []interface{}:
+		for i, child := range val {
+			val[i] = redactValue(child, key, mode, privacy)
+		}
+		return val
+	case string:
+		leaf = val
+	case json.Number:
+		leaf = val.String()
+	case bool:
+		leaf = strconv.FormatBool(val)
+	}
+
+	if auditSafePayloadKeys[key] {
+		return v
+	}
+	if mode == models.AuditRedactHash {
+		return privacy.ValueHash(leaf)
+	}
+	return redactedValue
+}
+
+// derefString returns the value of an optional string ("" when nil)
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// parseAuditSearch converts the query string of a search into a repository filter
+func parseAuditSearch(req *models.AuditLogSearchRequest) (models.AuditLogFilter, error) {
+	filter := models.AuditLogFilter{
+		PartnerID: strings.TrimSpace(req.PartnerID),
+		NIK:       strings.TrimSpace(req.NIK),
+		Scope:     strings.TrimSpace(req.Scope),
+		Limit:     req.Limit,
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditPageSize
+	}
+	if filter.Limit < 1 || filter.Limit > maxAuditPageSize {
+		return filter, &utils.ValidationError{Field: "limit", Message: fmt.Sprintf("limit must be between 1 and %d", maxAuditPageSize)}
+	}
+	if filter.PartnerID != "" {
+		if _, err := uuid.Parse(filter.PartnerID); err != nil {
+			return filter, &utils.ValidationError{Field: "partner_id", Message: "partner_id must be a valid UUID"}
+		}
+	}
+	if filter.NIK != "" {
+		if _, err := utils.ParseNIK(filter.NIK); err != nil {
+			return filter, err
+		}
+	}
+
+	if req.From != "" {
+		from, _, err := parseAuditTime(req.From)
+		if err != nil {
+			return filter, &utils.ValidationError{Field: "from", Message: "from must be YYYY-MM-DD or RFC3339"}
+		}
+		filter.From = &from
+	}
+	if req.To != "" {
+		to, dateOnly, err := parseAuditTime(req.To)
+		if err != nil {
+			return filter, &utils.ValidationError{Field: "to", Message: "to must be YYYY-MM-DD or RFC3339"}
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1) // the whole day is included
+		}
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return filter, &utils.ValidationError{Field: "to", Message: "to must be after from"}
+	}
+
+	for _, outcome := range strings.Split(req.Outcome, ",") {
+		switch outcome = strings.TrimSpace(outcome); outcome {
+		case "":
+		case models.AuditOutcomeFound:
+			filter.ResultCodes = append(filter.ResultCodes, models.ResultMatched, models.ResultDataInactive)
+		case models.AuditOutcomeNotFound:
+			filter.ResultCodes = append(filter.ResultCodes, models.ResultNIKNotFound, models.ResultDOBMismatch)
+		case models.ResultMatched, models.ResultDataInactive, models.ResultNIKNotFound, models.ResultDOBMismatch:
+			filter.ResultCodes = append(filter.ResultCodes, outcome)
+		default:
+			return filter, &utils.ValidationError{Field: "outcome", Message: fmt.Sprintf(
+				"outcome must be %s, %s, %s, %s, %s or %s", models.AuditOutcomeFound, models.AuditOutcomeNotFound,
+				models.ResultMatched, models.ResultDataInactive, models.ResultNIKNotFound, models.ResultDOBMismatch)}
+		}
+	}
+
+	if req.Cursor != "" {
+		createdAt, id, err := decodeAuditCursor(req.Cursor)
+		if err != nil {
+			return filter, &utils.ValidationError{Field: "cursor", Message: "invalid cursor"}
+		}
+		filter.AfterCreatedAt = &createdAt
+		filter.AfterID = id
+	}
+
+	return filter, nil
+}
+
+// parseAuditTime parses YYYY-MM-DD (reported as dateOnly) or RFC3339
+func parseAuditTime(value string) (t time.Time, dateOnly bool, err error) {
+	if t, err = time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// encodeAuditCursor encodes a keyset position (created_at, id) as an opaque cursor
+func encodeAuditCursor(createdAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.UTC().Format(time.RFC3339Nano) + "," + id))
+}
+
+// decodeAuditCursor decodes a cursor produced by encodeAuditCursor
+func decodeAuditCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	createdAt, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return time.Time{}, "", fmt.Errorf("malformed cursor")
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return time.Time{}, "", err
+	}
+	return t, id, nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/username/go-gin-backend/internal/models"
+)
+
+func TestAuditCursorRoundTrip(t *testing.T) {
+	id := "6f1c2a9e-3f4b-4d4a-9a57-0c2f5d2b7e11"
+
+	tests := []struct {
+		name      string
+		createdAt time.Time
+	}{
+		{"microseconds", time.Date(2026, 9, 1, 8, 30, 0, 123456000, time.UTC)},
+		{"whole second", time.Date(2026, 9, 1, 8, 30, 0, 0, time.UTC)},
+		{"other time zone", time.Date(2026, 9, 1, 15, 30, 0, 500000000, time.FixedZone("WIB", 7*3600))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := encodeAuditCursor(tt.createdAt, id)
+			gotAt, gotID, err := decodeAuditCursor(cursor)
+			if err != nil {
+				t.Fatalf("decodeAuditCursor(%q) error = %v", cursor, err)
+			}
+			if !gotAt.Equal(tt.createdAt) || gotID != id {
+				t.Errorf("decodeAuditCursor(%q) = %v, %q, want %v, %q", cursor, gotAt, gotID, tt.createdAt, id)
+			}
+		})
+	}
+}
+
+func TestDecodeAuditCursorInvalid(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"missing separator", encode("2026-09-01T08:30:00Z")},
+		{"invalid time", encode("yesterday,6f1c2a9e-3f4b-4d4a-9a57-0c2f5d2b7e11")},
+		{"invalid id", encode("2026-09-01T08:30:00Z,1 OR 1=1")},
+		{"empty", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := decodeAuditCursor(tt.cursor); err == nil {
+				t.Errorf("decodeAuditCursor(%q): want error", tt.cursor)
+			}
+		})
+	}
+}
+
+func TestNewExportRedact(t *testing.T) {
+	withKey := &AuditService{Privacy: NewAuditPrivacy(nil, AuditPayloadMinimal, bytes.Repeat([]byte{1}, 32))}
+	withoutKey := &AuditService{Privacy: NewAuditPrivacy(nil, AuditPayloadMinimal, nil)}
+
+	tests := []struct {
+		name    string
+		service *AuditService
+		redact  string
+		want    string // "" = validation error
+	}{
+		{"default with NIK key", withKey, "", models.AuditRedactHash},
+		{"default without NIK key", withoutKey, "", models.AuditRedactRedact},
+		{"explicit none", withKey, models.AuditRedactNone, models.AuditRedactNone},
+		{"explicit redact with NIK key", withKey, models.AuditRedactRedact, models.AuditRedactRedact},
+		{"hash without NIK key", withoutKey, models.AuditRedactHash, ""},
+		{"unknown mode", withKey, "mask", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			export, err := tt.service.NewExport(&models.AuditExportRequest{Redact: tt.redact})
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("NewExport(redact=%q) = %s, want error", tt.redact, export.Redact)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewExport(redact=%q) error = %v", tt.redact, err)
+			}
+			if export.Redact != tt.want {
+				t.Errorf("NewExport(redact=%q) = %s, want %s", tt.redact, export.Redact, tt.want)
+			}
+		})
+	}
+}
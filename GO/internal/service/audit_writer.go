@@ -0,0 +1,466 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+)
+
+// Policies applied by the audit writer when its queue is full
+const (
+	AuditQueueBlock  = "block"  // the check waits until the queue has room (or its context ends)
+	AuditQueueDrop   = "drop"   // the audit log is discarded (counted in the metrics, ErrAuditDropped)
+	AuditQueueReject = "reject" // the check fails with ErrAuditQueueFull
+)
+
+// auditBatchTimeout bounds a single batch insert
+const auditBatchTimeout = 10 * time.Second
+
+// ErrAuditQueueFull is returned by Write under the reject policy when the queue is full
+var ErrAuditQueueFull = errors.New("audit queue is full, try again later")
+
+// ErrAuditDropped is returned by Write under the drop policy when the queue is full and the entry
+// was discarded. The check may still be answered, but nothing may refer to the audit row.
+var ErrAuditDropped = errors.New("audit log dropped, audit queue is full")
+
+// ErrAuditWriterStopped is returned by Write once the writer has been stopped
+var ErrAuditWriterStopped = errors.New("audit writer is stopped")
+
+// AuditWriterConfig configures the audit writer
+type AuditWriterConfig struct {
+	QueueSize      int           // entries buffered in memory
+	BatchSize      int           // entries per INSERT
+	FlushInterval  time.Duration // maximum time an entry waits for its batch
+	Policy         string        // block, drop or reject
+	SpillFile      string        // NDJSON file receiving audit logs that could not be inserted
+	QuarantineFile string        // NDJSON file receiving audit logs the database rejected
+}
+
+// AuditWriter writes audit logs through a bounded in-memory queue. A single background
+// goroutine drains the queue in batches (one multi-row INSERT each). A failed batch is retried
+// row by row: rows the database rejects go to the quarantine file, rows that still cannot be
+// inserted (database unavailable) are appended to the spill file and can be replayed with
+// ReplayAuditSpill.
+type AuditWriter struct {
+	AuditRepo *repository.AuditRepository
+	Privacy   *AuditPrivacy // redaction applied before queuing (nil: entries stored as given)
+	Config    AuditWriterConfig
+
+	queue   chan *models.CreateAuditLogRequest
+	space   chan struct{} // signalled when the consumer takes an entry out, wakes a waiting WriteBatch
+	mu      sync.RWMutex  // guards stopped against sends on the closed queue; held exclusively by WriteBatch while it fills the queue
+	stopped bool
+	done    chan struct{}
+
+	enqueued      atomic.Int64
+	written       atomic.Int64
+	dropped       atomic.Int64
+	rejected      atomic.Int64
+	spilled       atomic.Int64
+	quarantined   atomic.Int64
+	lost          atomic.Int64
+	failedBatches atomic.Int64
+	errMu         sync.Mutex
+	lastError     string
+	lastErrorAt   *time.Time
+}
+
+// NewAuditWriter creates a new audit writer; call Start before writing
+func NewAuditWriter(auditRepo *repository.AuditRepository, privacy *AuditPrivacy, cfg AuditWriterConfig) *AuditWriter {
+	if cfg.QueueSize < 1 {
+		cfg.QueueSize = 10000
+	}
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = 500
+	}
+	if cfg.BatchSize > repository.MaxAuditBatchSize {
+		log.Printf("WARNING: audit batch size %d exceeds the query parameter limit, using %d", cfg.BatchSize, repository.MaxAuditBatchSize)
+		cfg.BatchSize = repository.MaxAuditBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 200 * time.Millisecond
+	}
+	switch cfg.Policy {
+	case AuditQueueBlock, AuditQueueDrop, AuditQueueReject:
+	default:
+		log.Printf("WARNING: unknown audit queue policy %q, using %s", cfg.Policy, AuditQueueBlock)
+		cfg.Policy = AuditQueueBlock
+	}
+	if cfg.SpillFile == "" {
+		cfg.SpillFile = "audit-spill.ndjson"
+	}
+	if cfg.QuarantineFile == "" {
+		cfg.QuarantineFile = "audit-quarantine.ndjson"
+	}
+
+	return &AuditWriter{
+		AuditRepo: auditRepo,
+		Privacy:   privacy,
+		Config:    cfg,
+		queue:     make(chan *models.CreateAuditLogRequest, cfg.QueueSize),
+		space:     make(chan struct{}, 1),
+	}
+}
+
+// Start launches the background goroutine that drains the queue
+func (w *AuditWriter) Start() {
+	w.done = make(chan struct{})
+	go w.run()
+}
+
+// Stop closes the queue and waits until every queued entry has been inserted or spilled.
+// Call it after the HTTP server and the job worker have stopped, before closing the database.
+func (w *AuditWriter) Stop() {
+	w.mu.Lock()
+	if w.stopped || w.done == nil {
+		w.mu.Unlock()
+		return
+	}
+	w.stopped = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	<-w.done
+}
+
+// Write redacts and queues an audit log. When the queue is full the configured policy applies:
+// block waits until the queue has room or ctx ends (returning ctx.Err()), drop discards the entry
+// and returns ErrAuditDropped, reject returns ErrAuditQueueFull.
+func (w *AuditWriter) Write(ctx context.Context, entry *models.CreateAuditLogRequest) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	if w.Privacy != nil {
+		if err := w.Privacy.Apply(ctx, entry); err != nil {
+			return err
+		}
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.stopped {
+		return ErrAuditWriterStopped
+	}
+
+	switch w.Config.Policy {
+	case AuditQueueDrop:
+		select {
+		case w.queue <- entry:
+		default:
+			w.dropped.Add(1)
+			return ErrAuditDropped
+		}
+	case AuditQueueReject:
+		select {
+		case w.queue <- entry:
+		default:
+			w.rejected.Add(1)
+			return ErrAuditQueueFull
+		}
+	default:
+		select {
+		case w.queue <- entry:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	w.enqueued.Add(1)
+	return nil
+}
+
+// WriteBatch redacts and queues the audit logs of one request as a unit: either all of them are
+// queued or, when the queue lacks room for the whole batch, the policy applies to all of them
+// (drop discards them and returns ErrAuditDropped, reject returns ErrAuditQueueFull) and none is queued. Under the block
+// policy it waits until the queue has room for the whole batch or ctx ends (returning ctx.Err(), nothing queued).
+// A batch larger than the queue is never queued.
+func (w *AuditWriter) WriteBatch(ctx context.Context, entries []*models.CreateAuditLogRequest) error {
+	if len(entries) > cap(w.queue) {
+		return fmt.Errorf("audit batch of %d entries exceeds the audit queue size %d", len(entries), cap(w.queue))
+	}
+
+	now := time.Now()
+	for _, entry := range entries {
+		if entry.CreatedAt.IsZero() {
+			entry.CreatedAt = now
+		}
+		if w.Privacy != nil {
+			if err := w.Privacy.Apply(ctx, entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		queued, err := w.tryQueueBatch(entries)
+		if err != nil || queued {
+			return err
+		}
+
+		switch w.Config.Policy {
+		case AuditQueueDrop:
+			w.dropped.Add(int64(len(entries)))
+			return ErrAuditDropped
+		case AuditQueueReject:
+			w.rejected.Add(int64(len(entries)))
+			return ErrAuditQueueFull
+		}
+
+		// Block: wait for the consumer to make room without holding the lock, so Write and Stop
+		// are not held up. The timer covers a wake-up taken by another waiting batch.
+		select {
+		case <-w.space:
+		case <-time.After(w.Config.FlushInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// tryQueueBatch queues all entries if the queue has room for every one of them. The exclusive
+// lock keeps other writers out while the free space is checked and filled; the consumer only
+// takes entries out, so the free space cannot shrink in the meantime.
+func (w *AuditWriter) tryQueueBatch(entries []*models.CreateAuditLogRequest) (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		return false, ErrAuditWriterStopped
+	}
+	if cap(w.queue)-len(w.queue) < len(entries) {
+		return false, nil
+	}
+
+	for _, entry := range entries {
+		w.queue <- entry
+	}
+	w.enqueued.Add(int64(len(entries)))
+	return true, nil
+}
+
+// Metrics returns the queue backlog and the writer counters
+func (w *AuditWriter) Metrics() *models.AuditWriterMetrics {
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+
+	return &models.AuditWriterMetrics{
+		Policy:         w.Config.Policy,
+		QueueLength:    len(w.queue),
+		QueueCapacity:  cap(w.queue),
+		Enqueued:       w.enqueued.Load(),
+		Written:        w.written.Load(),
+		Dropped:        w.dropped.Load(),
+		Rejected:       w.rejected.Load(),
+		Spilled:        w.spilled.Load(),
+		Quarantined:    w.quarantined.Load(),
+		Lost:           w.lost.Load(),
+		FailedBatches:  w.failedBatches.Load(),
+		SpillFile:      w.Config.SpillFile,
+		QuarantineFile: w.Config.QuarantineFile,
+		LastError:      w.lastError,
+		LastErrorAt:    w.lastErrorAt,
+	}
+}
+
+// run batches queued entries until the queue is closed and drained
+func (w *AuditWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.Config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*models.CreateAuditLogRequest, 0, w.Config.BatchSize)
+	for {
+		select {
+		case entry, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, entry)
+			select {
+			case w.space <- struct{}{}:
+			default:
+			}
+			if len(batch) >= w.Config.BatchSize {
+				batch = w.flush(batch)
+			}
+		case <-ticker.C:
+			batch = w.flush(batch)
+		}
+	}
+}
+
+// flush inserts a batch, retrying it row by row when the insert fails, and returns the emptied batch
+func (w *AuditWriter) flush(batch []*models.CreateAuditLogRequest) []*models.CreateAuditLogRequest {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), auditBatchTimeout)
+	err := w.AuditRepo.CreateBatch(ctx, batch)
+	cancel()
+	if err == nil {
+		w.written.Add(int64(len(batch)))
+		return batch[:0]
+	}
+
+	w.failedBatches.Add(1)
+	w.setLastError(err)
+	log.Printf("AuditWriter - failed to insert %d audit logs, retrying row by row: %v", len(batch), err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), auditBatchTimeout)
+	written, rejected, failed := insertAuditRows(ctx, w.AuditRepo, batch)
+	cancel()
+	w.written.Add(int64(written))
+
+	if len(rejected) > 0 {
+		log.Printf("AuditWriter - %d audit logs rejected by the database, quarantining to %s", len(rejected), w.Config.QuarantineFile)
+		if err := spillAuditLogs(w.Config.QuarantineFile, rejected); err != nil {
+			w.lost.Add(int64(len(rejected)))
+			w.setLastError(err)
+			log.Printf("AuditWriter - failed to quarantine %d audit logs: %v", len(rejected), err)
+		} else {
+			w.quarantined.Add(int64(len(rejected)))
+		}
+	}
+
+	if len(failed) > 0 {
+		log.Printf("AuditWriter - %d audit logs not inserted, spilling to %s", len(failed), w.Config.SpillFile)
+		if err := spillAuditLogs(w.Config.SpillFile, failed); err != nil {
+			w.lost.Add(int64(len(failed)))
+			w.setLastError(err)
+			log.Printf("AuditWriter - failed to spill %d audit logs: %v", len(failed), err)
+		} else {
+			w.spilled.Add(int64(len(failed)))
+		}
+	}
+
+	return batch[:0]
+}
+
+// insertAuditRows inserts the entries of a failed batch one at a time. It returns how many were
+// inserted, the entries the database rejected (ErrAuditRowRejected) and those that failed for
+// another reason; after such a failure the remaining entries are not tried, since the database
+// is most likely unavailable.
+func insertAuditRows(ctx context.Context, auditRepo *repository.AuditRepository, entries []*models.CreateAuditLogRequest) (int, []*models.CreateAuditLogRequest, []*models.CreateAuditLogRequest) {
+	written := 0
+	var rejected []*models.CreateAuditLogRequest
+	for i, entry := range entries {
+		err := auditRepo.CreateBatch(ctx, entries[i:i+1])
+		switch {
+		case err == nil:
+			written++
+		case errors.Is(err, repository.ErrAuditRowRejected):
+			log.Printf("AuditWriter - audit log %s rejected: %v", entry.ID, err)
+			rejected = append(rejected, entry)
+		default:
+			return written, rejected, entries[i:]
+		}
+	}
+	return written, rejected, nil
+}
+
+// setLastError records the most recent write error for the metrics
+func (w *AuditWriter) setLastError(err error) {
+	now := time.Now()
+	w.errMu.Lock()
+	w.lastError = err.Error()
+	w.lastErrorAt = &now
+	w.errMu.Unlock()
+}
+
+// spillAuditLogs appends audit logs to an NDJSON file and syncs it to disk
+func spillAuditLogs(path string, entries []*models.CreateAuditLogRequest) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(f)
+	encoder := json.NewEncoder(buf)
+	for _, entry := range entries {
+		if err = encoder.Encode(entry); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// ReplayAuditSpill inserts the audit logs of a spill file in batches and returns how many were read
+// and how many were quarantined. A failed batch is retried row by row; rows the database rejects
+// are appended to quarantineFile instead of stopping the replay. Entries already in audit_logs are
+// skipped, so a partially replayed file can be replayed again.
+func ReplayAuditSpill(ctx context.Context, auditRepo *repository.AuditRepository, r io.Reader, batchSize int, quarantineFile string) (int, int, error) {
+	if batchSize < 1 || batchSize > repository.MaxAuditBatchSize {
+		return 0, 0, fmt.Errorf("batch size must be between 1 and %d", repository.MaxAuditBatchSize)
+	}
+
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber() // keep payload numbers as written
+
+	total, quarantined := 0, 0
+	insert := func(batch []*models.CreateAuditLogRequest) error {
+		err := auditRepo.CreateBatch(ctx, batch)
+		if err == nil {
+			return nil
+		}
+
+		_, rejected, failed := insertAuditRows(ctx, auditRepo, batch)
+		if len(rejected) > 0 {
+			if err := spillAuditLogs(quarantineFile, rejected); err != nil {
+				return fmt.Errorf("failed to quarantine %d audit logs: %w", len(rejected), err)
+			}
+			quarantined += len(rejected)
+		}
+		if len(failed) > 0 {
+			return err
+		}
+		return nil
+	}
+
+	batch := make([]*models.CreateAuditLogRequest, 0, batchSize)
+	for {
+		var entry models.CreateAuditLogRequest
+		err := decoder.Decode(&entry)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return total, quarantined, fmt.Errorf("invalid spill entry after %d entries: %w", total, err)
+		}
+
+		batch = append(batch, &entry)
+		if len(batch) >= batchSize {
+			if err := insert(batch); err != nil {
+				return total, quarantined, err
+			}
+			total += len(batch)
+			batch = batch[:0]
+		}
+	}
+
+	if err := insert(batch); err != nil {
+		return total, quarantined, err
+	}
+	return total + len(batch), quarantined, nil
+}
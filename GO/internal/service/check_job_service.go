@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+const (
+	checkJobLease       = 2 * time.Minute  // worker lease, renewed after every chunk
+	checkJobRetryDelay  = 30 * time.Second // delay before a failed job is picked up again
+	checkJobMaxAttempts = 5
+	checkJobPurgeEvery  = time.Hour // interval between purges of expired job results
+)
+
+// CheckJobService handles bulk check job uploads and their background processing
+type CheckJobService struct {
+	JobRepo         *repository.CheckJobRepository
+	PartnerRepo     *repository.PartnerRepository
+	ScopeRepo       *repository.ScopeRepository
+	CheckingService *CheckingService
+	MaxRows         int
+	ChunkSize       int
+	PollInterval    time.Duration
+	Retention       time.Duration // how long finished jobs and their results are kept, 0 keeps them
+
+	lastPurge time.Time
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewCheckJobService creates a new check job service
+func NewCheckJobService(
+	jobRepo *repository.CheckJobRepository,
+	partnerRepo *repository.PartnerRepository,
+	scopeRepo *repository.ScopeRepository,
+	checkingService *CheckingService,
+	maxRows, chunkSize int,
+	pollInterval, retention time.Duration,
+) *CheckJobService {
+	return &CheckJobService{
+		JobRepo:         jobRepo,
+		PartnerRepo:     partnerRepo,
+		ScopeRepo:       scopeRepo,
+		CheckingService: checkingService,
+		MaxRows:         maxRows,
+		ChunkSize:       chunkSize,
+		PollInterval:    pollInterval,
+		Retention:       retention,
+	}
+}
+
+// CreateJob parses an uploaded CSV (columns nik, tanggal_lahir) and stores it as a queued job.
+// A header row is optional; without it the first two columns are used.
+// The purpose and consent reference apply to every row and are authorized before the file is read;
+// the rows are audited with the API key that uploaded the file.
+func (s *CheckJobService) CreateJob(ctx context.Context, partnerID, apiKeyID, fileName, purpose, consentRef string, file io.Reader) (*models.CheckJob, error) {
+	if err := s.CheckingService.AuthorizePurpose(ctx, partnerID, purpose, consentRef); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	nikCol, dobCol := 0, 1
+	first := true
+	rows := 0
+
+	next := func() (string, string, error) {
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				if rows == 0 {
+					return "", "", &utils.ValidationError{Field: "file", Message: "CSV file contains no rows"}
+				}
+				return "", "", io.EOF
+			}
+			if err != nil {
+				return "", "", &utils.ValidationError{Field: "file", Message: fmt.Sprintf("invalid CSV: %v", err)}
+			}
+
+			if first {
+				first = false
+				if len(record) > 0 {
+					record[0] = strings.TrimPrefix(record[0], "\ufeff") // Excel BOM
+				}
+				if idx := indexOf(record, "nik"); idx >= 0 {
+					nikCol = idx
+					dobCol = indexOf(record, "tanggal_lahir")
+					if dobCol < 0 {
+						return "", "", &utils.ValidationError{Field: "file", Message: "CSV header must contain nik and tanggal_lahir columns"}
+					}
+					continue
+				}
+			}
+
+			rows++
+			if rows > s.MaxRows {
+				return "", "", &utils.ValidationError{Field: "file", Message: fmt.Sprintf("too many rows, maximum is %d", s.MaxRows)}
+			}
+			return column(record, nikCol), column(record, dobCol), nil
+		}
+	}
+
+	return s.JobRepo.CreateWithItems(ctx, partnerID, apiKeyID, fileName, purpose, consentRef, next)
+}
+
+// GetJob retrieves a job owned by the partner (nil if not found)
+func (s *CheckJobService) GetJob(ctx context.Context, jobID, partnerID string) (*models.CheckJob, error) {
+	return s.JobRepo.GetByIDForPartner(ctx, jobID, partnerID)
+}
+
+// ListJobs retrieves jobs of a partner
+func (s *CheckJobService) ListJobs(ctx context.Context, partnerID string, limit, offset int) ([]*models.CheckJob, error) {
+	return s.JobRepo.ListByPartner(ctx, partnerID, limit, offset)
+}
+
+// WriteResultsCSV streams job results as CSV. Result columns are the union of
+// fields disclosed across the job (depends on the partner's scopes).
+func (s *CheckJobService) WriteResultsCSV(ctx context.Context, job *models.CheckJob, w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	header := append([]string{"row_number", "input_nik", "input_tanggal_lahir", "status", "error"}, job.ResultColumns...)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(header))
+	err := s.JobRepo.StreamItems(ctx, job.ID, func(item *models.CheckJobItem) error {
+		record[0] = strconv.Itoa(item.RowNumber)
+		record[1] = item.NIK
+		record[2] = item.TanggalLahir
+		record[3] = item.Status
+		record[4] = ""
+		if item.Error != nil {
+			record[4] = *item.Error
+		}
+
+		var result map[string]interface{}
+		if len(item.Result) > 0 {
+			if err := json.Unmarshal(item.Result, &result); err != nil {
+				return fmt.Errorf("invalid result for row %d: %w", item.RowNumber, err)
+			}
+		}
+		for i, col := range job.ResultColumns {
+			record[5+i] = csvValue(result[col])
+		}
+
+		return writer.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteResultsNDJSON streams job results as newline-delimited JSON
+func (s *CheckJobService) WriteResultsNDJSON(ctx context.Context, job *models.CheckJob, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	return s.JobRepo.StreamItems(ctx, job.ID, func(item *models.CheckJobItem) error {
+		return encoder.Encode(item)
+	})
+}
+
+// Start launches the background worker that processes queued jobs
+func (s *CheckJobService) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.run(ctx)
+}
+
+// Stop signals the worker to stop and waits for it. A chunk in progress is rolled back
+// and resumed by the next worker once its lease expires.
+func (s *CheckJobService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+// run polls for claimable jobs until the context is cancelled
+func (s *CheckJobService) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.processAvailableJobs(ctx)
+		s.purgeExpiredJobs(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processAvailableJobs processes jobs one by one until none are left
+func (s *CheckJobService) processAvailableJobs(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := s.JobRepo.ClaimNext(ctx, checkJobLease)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("CheckJobService - claim error: %v", err)
+			}
+			return
+		}
+		if job == nil {
+			return
+		}
+
+		if err := s.processJob(ctx, job); err != nil {
+			if ctx.Err() != nil {
+				return // shutting down, job resumes after restart
+			}
+			log.Printf("CheckJobService - job %s failed: %v", job.ID, err)
+			if recErr := s.JobRepo.RecordFailure(context.Background(), job.ID, err.Error(), checkJobMaxAttempts, checkJobRetryDelay); recErr != nil {
+				log.Printf("CheckJobService - %v", recErr)
+			}
+		}
+	}
+}
+
+// purgeExpiredJobs deletes jobs finished longer than Retention ago, together with their rows
+// (input NIKs and per-NIK results). Runs at most once per checkJobPurgeEvery.
+func (s *CheckJobService) purgeExpiredJobs(ctx context.Context) {
+	if s.Retention <= 0 || time.Since(s.lastPurge) < checkJobPurgeEvery {
+		return
+	}
+	s.lastPurge = time.Now()
+
+	purged, err := s.JobRepo.DeleteFinishedBefore(ctx, time.Now().Add(-s.Retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("CheckJobService - purge error: %v", err)
+		}
+		return
+	}
+	if purged > 0 {
+		log.Printf("CheckJobService - purged %d finished jobs", purged)
+	}
+}
+
+// processJob checks all pending rows of a job in chunks
+func (s *CheckJobService) processJob(ctx context.Context, job *models.CheckJob) error {
+	partner, err := s.PartnerRepo.GetByID(ctx, job.PartnerID)
+	if err != nil {
+		return err
+	}
+	if partner.Status != models.PartnerStatusActive {
+		return s.JobRepo.RecordFailure(ctx, job.ID, "partner is inactive", 1, 0)
+	}
+
+	// Scopes are loaded once per run, exactly like PartnerAPIKeyAuth does per request
+	scopes, err := s.ScopeRepo.GetActiveByPartnerID(ctx, job.PartnerID)
+	if err != nil {
+		return err
+	}
+
+	for {
+		items, err := s.JobRepo.GetPendingItems(ctx, job.ID, s.ChunkSize)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return s.JobRepo.Complete(ctx, job.ID)
+		}
+
+		reqs := make([]models.CheckTKRequest, len(items))
+		for i, item := range items {
+			// The audit id is derived from the row, so a chunk checked again after its lease
+			// expired (the worker died before saving it) is not audited twice
+			reqs[i] = models.CheckTKRequest{
+				NIK:          item.NIK,
+				TanggalLahir: item.TanggalLahir,
+				AuditID:      uuid.NewSHA1(uuid.MustParse(job.ID), []byte(strconv.Itoa(item.RowNumber))).String(),
+			}
+			if job.Purpose != nil {
+				reqs[i].Purpose = *job.Purpose
+			}
+			if job.ConsentRef != nil {
+				reqs[i].ConsentRef = *job.ConsentRef
+			}
+		}
+
+		batch, err := s.CheckingService.CheckTKBatch(ctx, reqs, job.PartnerID, scopes, nil, derefString(job.APIKeyID))
+		if err != nil {
+			return err
+		}
+
+		columnSet := make(map[string]bool)
+		for i, result := range batch.Results {
+			items[i].Status = result.Status
+			if result.Error != "" {
+				errMsg := result.Error
+				items[i].Error = &errMsg
+			}
+			if result.Data != nil {
+				data, err := json.Marshal(result.Data)
+				if err != nil {
+					return fmt.Errorf("failed to marshal result: %w", err)
+				}
+				items[i].Result = data
+				for key := range result.Data {
+					columnSet[key] = true
+				}
+			}
+		}
+
+		columns := make([]string, 0, len(columnSet))
+		for key := range columnSet {
+			columns = append(columns, key)
+		}
+		sort.Strings(columns)
+
+		if err := s.JobRepo.SaveChunkResults(ctx, job.ID, items, batch.Found, batch.NotFound, batch.Invalid, columns, checkJobLease); err != nil {
+			return err
+		}
+	}
+}
+
+// indexOf returns the index of a header name (case-insensitive), or -1
+func indexOf(record []string, name string) int {
+	for i, v := range record {
+		if strings.EqualFold(strings.TrimSpace(v), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// column returns a trimmed CSV column or empty string when missing
+func column(record []string, idx int) string {
+	if idx < 0 || idx >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[idx])
+}
+
+// csvValue formats a JSON value for a CSV cell
+func csvValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case bool:
+		return strconv.FormatBool(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		b, _ := json.Marshal(val)
+		return string(b)
+	}
+}
@@ -2,24 +2,56 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/username/go-gin-backend/internal/models"
 	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/pkg/utils"
 )
 
+// ErrStatusHistoryScopeRequired is returned when as_of is used without the status_history scope
+var ErrStatusHistoryScopeRequired = errors.New("as_of requires the status_history scope")
+
+// ErrVerifyScopeRequired is returned when an attribute is sent for verification without a verify scope on its field
+var ErrVerifyScopeRequired = errors.New("verifying this attribute requires a verify scope")
+
+// verifyFuzzyThreshold is the minimum similarity reported as a fuzzy match instead of a mismatch
+const verifyFuzzyThreshold = 0.8
+
 // CheckingService handles TK checking business logic
 type CheckingService struct {
-	TKRepo    *repository.TKRepository
-	AuditRepo *repository.AuditRepository
+	TKRepo        *repository.TKRepository
+	Audit         *AuditWriter
+	HistoryRepo   *repository.TKHistoryRepository
+	ScopeRegistry *ScopeRegistry
+	PurposeRepo   *repository.PurposeRepository
+	ConsentRepo   *repository.ConsentRepository
+	Receipts      *ReceiptService // optional, signs CheckTK receipts
 }
 
 // NewCheckingService creates a new checking service
-func NewCheckingService(tkRepo *repository.TKRepository, auditRepo *repository.AuditRepository) *CheckingService {
+func NewCheckingService(
+	tkRepo *repository.TKRepository,
+	audit *AuditWriter,
+	historyRepo *repository.TKHistoryRepository,
+	scopeRegistry *ScopeRegistry,
+	purposeRepo *repository.PurposeRepository,
+	consentRepo *repository.ConsentRepository,
+	receipts *ReceiptService,
+) *CheckingService {
 	return &CheckingService{
-		TKRepo:    tkRepo,
-		AuditRepo: auditRepo,
+		TKRepo:        tkRepo,
+		Audit:         audit,
+		HistoryRepo:   historyRepo,
+		ScopeRegistry: scopeRegistry,
+		PurposeRepo:   purposeRepo,
+		ConsentRepo:   consentRepo,
+		Receipts:      receipts,
 	}
 }
 
@@ -30,75 +62,515 @@ func (s *CheckingService) CheckTK(
 	partnerID string,
 	scopes []models.PartnerScope,
 	userID *string,
+	apiKeyID string,
 ) (models.CheckTKResponse, error) {
 	// Parse tanggal lahir
 	dob, err := time.Parse("2006-01-02", req.TanggalLahir)
 	if err != nil {
-		return nil, fmt.Errorf("invalid date format, use YYYY-MM-DD")
+		return nil, &utils.ValidationError{Field: "tanggal_lahir", Message: "invalid date format, use YYYY-MM-DD"}
+	}
+
+	// Reject malformed NIKs and DOBs contradicting the NIK before the lookup
+	if err := utils.ValidateNIKWithDOB(req.NIK, dob); err != nil {
+		return nil, err
 	}
 
-	// Check TK data by NIK and DOB
-	tkData, err := s.TKRepo.CheckByNIKAndDOB(ctx, req.NIK, dob)
+	// Lawful basis: the purpose must be in the partner's contract
+	if err := s.AuthorizePurpose(ctx, partnerID, req.Purpose, req.ConsentRef); err != nil {
+		return nil, err
+	}
+
+	defs, err := s.ScopeRegistry.Definitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	allowed := enabledScopes(scopes, defs)
+
+	// Optional historical status date
+	var asOf time.Time
+	if req.AsOf != "" {
+		if asOf, err = parseAsOf(req.AsOf, allowed); err != nil {
+			return nil, err
+		}
+	}
+
+	// Look up TK data by NIK, DOB is compared here to tell a mismatch from a missing NIK
+	tkData, err := s.TKRepo.GetForCheck(ctx, req.NIK)
+	if err != nil {
+		return nil, err
+	}
+	code := resultCode(tkData, dob.Format("2006-01-02"))
 
 	// Prepare response
 	var response models.CheckTKResponse
 
-	if err != nil || tkData == nil {
+	if !isMatch(code) {
 		// TK not found or DOB mismatch
 		response = models.CheckTKResponse{
 			"found": false,
 		}
 	} else {
-		// TK found and verified - filter by scopes
-		response = filterByScopes(tkData, scopes)
+		// TK found and verified - filter by scopes, sensitive ones only with the worker's consent
+		consented, err := s.consentedScopes(ctx, partnerID, allowed, defs, []string{tkData.NIK})
+		if err != nil {
+			return nil, err
+		}
+		response = filterByScopes(tkData, withConsent(allowed, defs, consented[tkData.NIK]), defs)
 		response["found"] = true
+
+		if req.AsOf != "" {
+			query := models.StatusAsOfQuery{NIK: tkData.NIK, AsOf: asOf}
+			statuses, err := s.HistoryRepo.GetStatusesAsOf(ctx, []models.StatusAsOfQuery{query})
+			if err != nil {
+				return nil, err
+			}
+			setStatusAsOf(response, query, statuses)
+		}
+	}
+	if allowed[models.ScopeResultDetail] {
+		response["result_code"] = code
 	}
 
-	// Log the check to audit table (async, don't fail the request if audit fails)
-	go func() {
-		auditErr := s.AuditRepo.Create(
-			context.Background(),
-			partnerID,
-			userID,
-			req.NIK,
-			scopes,
-			req,
-			response,
-		)
-		if auditErr != nil {
-			fmt.Printf("Failed to create audit log: %v\n", auditErr)
+	// Signed receipt referencing the audit row written below
+	auditID := uuid.New().String()
+	if s.Receipts != nil {
+		outcome := receiptOutcome(code, allowed[models.ScopeResultDetail])
+		receipt, err := s.Receipts.Sign(auditID, partnerID, req.NIK, outcome, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign receipt: %w", err)
 		}
-	}()
+		response["receipt"] = receipt
+	}
+
+	// Log the check to the audit table (queued, see AuditWriter)
+	if err := s.logAudit(ctx, &models.CreateAuditLogRequest{
+		ID:              auditID,
+		PartnerID:       partnerID,
+		UserID:          userID,
+		NIK:             req.NIK,
+		ScopesUsed:      scopes,
+		RequestPayload:  req,
+		ResponsePayload: response,
+		ResultCode:      code,
+		Purpose:         req.Purpose,
+		ConsentRef:      req.ConsentRef,
+		APIKeyID:        apiKeyID,
+	}); err != nil {
+		if !errors.Is(err, ErrAuditDropped) {
+			return nil, err
+		}
+		// Drop policy: the check is answered, but without a receipt for an audit row that never exists
+		delete(response, "receipt")
+	}
 
 	return response, nil
 }
 
-// filterByScopes filters TK data based on enabled scopes
-func filterByScopes(tk *models.TKData, scopes []models.PartnerScope) models.CheckTKResponse {
+// VerifyTK compares partner-supplied attributes with the stored TK data and returns per-field
+// match results only. Each attribute needs an enabled verify scope on its tk_data field.
+func (s *CheckingService) VerifyTK(
+	ctx context.Context,
+	req models.VerifyTKRequest,
+	partnerID string,
+	scopes []models.PartnerScope,
+	userID *string,
+	apiKeyID string,
+) (*models.VerifyTKResponse, error) {
+	if req.NIK == "" {
+		return nil, &utils.ValidationError{Field: "nik", Message: "nik is required"}
+	}
+	dob, err := time.Parse("2006-01-02", req.TanggalLahir)
+	if err != nil {
+		return nil, &utils.ValidationError{Field: "tanggal_lahir", Message: "invalid date format, use YYYY-MM-DD"}
+	}
+	if err := utils.ValidateNIKWithDOB(req.NIK, dob); err != nil {
+		return nil, err
+	}
+	if err := s.AuthorizePurpose(ctx, partnerID, req.Purpose, req.ConsentRef); err != nil {
+		return nil, err
+	}
+
+	// Attributes to verify, keyed by tk_data field
+	attributes := map[string]string{"nama": req.Nama}
+	if req.Alamat != nil && strings.TrimSpace(*req.Alamat) != "" {
+		attributes["alamat"] = *req.Alamat
+	}
+	for field, value := range attributes {
+		if strings.TrimSpace(value) == "" {
+			return nil, &utils.ValidationError{Field: field, Message: field + " is required"}
+		}
+	}
+
+	defs, err := s.ScopeRegistry.Definitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	allowed := enabledScopes(scopes, defs)
+	verifiable := verifyFields(allowed, defs)
+	var missing []string
+	for field := range attributes {
+		if !verifiable[field] {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("%w: %s", ErrVerifyScopeRequired, strings.Join(missing, ", "))
+	}
+
+	tkData, err := s.TKRepo.GetForCheck(ctx, req.NIK)
+	if err != nil {
+		return nil, err
+	}
+	code := resultCode(tkData, dob.Format("2006-01-02"))
+
+	response := &models.VerifyTKResponse{NIK: req.NIK}
+	if allowed[models.ScopeResultDetail] {
+		response.ResultCode = code
+	}
+	if isMatch(code) {
+		response.Found = true
+		response.Matches = make(map[string]models.AttributeMatch, len(attributes))
+		for field, value := range attributes {
+			stored, _ := tkData.Fields[field].(string)
+			response.Matches[field] = matchAttribute(value, stored)
+		}
+	}
+
+	if err := s.logAudit(ctx, &models.CreateAuditLogRequest{
+		ID:              uuid.New().String(),
+		PartnerID:       partnerID,
+		UserID:          userID,
+		NIK:             req.NIK,
+		ScopesUsed:      scopes,
+		RequestPayload:  req,
+		ResponsePayload: response,
+		ResultCode:      code,
+		Purpose:         req.Purpose,
+		ConsentRef:      req.ConsentRef,
+		APIKeyID:        apiKeyID,
+	}); err != nil && !errors.Is(err, ErrAuditDropped) {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// resultCode determines the outcome of a check for a looked-up record (nil when the NIK does not exist)
+func resultCode(tk *models.TKData, dob string) string {
+	switch {
+	case tk == nil:
+		return models.ResultNIKNotFound
+	case tk.TanggalLahirStr != dob:
+		return models.ResultDOBMismatch
+	case tk.StatusKepesertaan == models.TKStatusNonaktif:
+		return models.ResultDataInactive
+	}
+	return models.ResultMatched
+}
+
+// isMatch reports whether a result code means NIK and DOB matched (data is disclosed)
+func isMatch(code string) bool {
+	return code == models.ResultMatched || code == models.ResultDataInactive
+}
+
+// matchAttribute compares a supplied value with the stored one (exact after normalization, else by similarity)
+func matchAttribute(supplied, stored string) models.AttributeMatch {
+	a, b := utils.NormalizeForMatch(supplied), utils.NormalizeForMatch(stored)
+	if b == "" {
+		return models.AttributeMatch{Result: models.MatchNoData}
+	}
+	if a == b {
+		return models.AttributeMatch{Result: models.MatchExact}
+	}
+	score := utils.Similarity(a, b)
+	if score >= verifyFuzzyThreshold {
+		return models.AttributeMatch{Result: models.MatchFuzzy, Score: &score}
+	}
+	return models.AttributeMatch{Result: models.MatchMismatch}
+}
+
+// verifyFields returns the tk_data fields the partner may verify through its allowed verify scopes
+func verifyFields(allowed map[string]bool, defs map[string]*models.ScopeDefinition) map[string]bool {
+	fields := make(map[string]bool)
+	for name := range allowed {
+		if def := defs[name]; def.Mode == models.ScopeModeVerify && def.TKField != nil {
+			fields[*def.TKField] = true
+		}
+	}
+	return fields
+}
+
+// CheckTKBatch performs TK verification for multiple NIK/DOB pairs using a single lookup.
+// Results are returned in the same order as the request items.
+func (s *CheckingService) CheckTKBatch(
+	ctx context.Context,
+	items []models.CheckTKRequest,
+	partnerID string,
+	scopes []models.PartnerScope,
+	userID *string,
+	apiKeyID string,
+) (*models.BatchCheckTKResponse, error) {
+	defs, err := s.ScopeRegistry.Definitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	allowed := enabledScopes(scopes, defs)
+
+	allowedPurposes, err := s.PurposeRepo.GetAllowedCodes(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.BatchCheckTKResponse{
+		Total:   len(items),
+		Results: make([]models.BatchCheckTKItemResult, len(items)),
+	}
+
+	// Validate items and collect NIKs for the lookup
+	dobs := make([]string, len(items))
+	asOfs := make([]time.Time, len(items))
+	niks := make([]string, 0, len(items))
+	for i, item := range items {
+		resp.Results[i] = models.BatchCheckTKItemResult{Index: i, NIK: item.NIK}
+
+		if item.NIK == "" {
+			resp.Results[i].Status = models.BatchItemInvalid
+			resp.Results[i].Error = "nik is required"
+			continue
+		}
+		dob, err := time.Parse("2006-01-02", item.TanggalLahir)
+		if err != nil {
+			resp.Results[i].Status = models.BatchItemInvalid
+			resp.Results[i].Error = "invalid date format, use YYYY-MM-DD"
+			continue
+		}
+		if err := utils.ValidateNIKWithDOB(item.NIK, dob); err != nil {
+			resp.Results[i].Status = models.BatchItemInvalid
+			resp.Results[i].Error = err.Error()
+			continue
+		}
+		if err := checkPurpose(item.Purpose, item.ConsentRef, allowedPurposes); err != nil {
+			resp.Results[i].Status = models.BatchItemInvalid
+			resp.Results[i].Error = err.Error()
+			continue
+		}
+		if item.AsOf != "" {
+			if asOfs[i], err = parseAsOf(item.AsOf, allowed); err != nil {
+				resp.Results[i].Status = models.BatchItemInvalid
+				resp.Results[i].Error = err.Error()
+				continue
+			}
+		}
+
+		dobs[i] = dob.Format("2006-01-02")
+		niks = append(niks, item.NIK)
+	}
+
+	// Single round trip for all valid items
+	tkByNIK, err := s.TKRepo.GetByNIKs(ctx, niks)
+	if err != nil {
+		return nil, err
+	}
+
+	// Historical statuses are resolved in one query as well
+	var queries []models.StatusAsOfQuery
+	for i, item := range items {
+		if tk := tkByNIK[item.NIK]; !asOfs[i].IsZero() && tk != nil && tk.TanggalLahirStr == dobs[i] {
+			queries = append(queries, models.StatusAsOfQuery{NIK: item.NIK, AsOf: asOfs[i]})
+		}
+	}
+	statuses, err := s.HistoryRepo.GetStatusesAsOf(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	// Worker consents for sensitive scopes, also in one query
+	consented, err := s.consentedScopes(ctx, partnerID, allowed, defs, niks)
+	if err != nil {
+		return nil, err
+	}
+
+	audits := make([]*models.CreateAuditLogRequest, 0, len(niks))
+	for i, item := range items {
+		result := &resp.Results[i]
+		if result.Status == models.BatchItemInvalid {
+			resp.Invalid++
+			continue
+		}
+
+		var response models.CheckTKResponse
+		tkData := tkByNIK[item.NIK]
+		code := resultCode(tkData, dobs[i])
+		if !isMatch(code) {
+			// TK not found or DOB mismatch
+			response = models.CheckTKResponse{
+				"found": false,
+			}
+			result.Status = models.BatchItemNotFound
+			resp.NotFound++
+		} else {
+			response = filterByScopes(tkData, withConsent(allowed, defs, consented[item.NIK]), defs)
+			response["found"] = true
+			if !asOfs[i].IsZero() {
+				setStatusAsOf(response, models.StatusAsOfQuery{NIK: item.NIK, AsOf: asOfs[i]}, statuses)
+			}
+			result.Status = models.BatchItemFound
+			resp.Found++
+		}
+		if allowed[models.ScopeResultDetail] {
+			response["result_code"] = code
+		}
+		result.Data = response
+
+		// One audit row per checked item
+		auditID := item.AuditID
+		if auditID == "" {
+			auditID = uuid.New().String()
+		}
+		audits = append(audits, &models.CreateAuditLogRequest{
+			ID:              auditID,
+			PartnerID:       partnerID,
+			UserID:          userID,
+			NIK:             item.NIK,
+			ScopesUsed:      scopes,
+			RequestPayload:  item,
+			ResponsePayload: response,
+			ResultCode:      code,
+			Purpose:         item.Purpose,
+			ConsentRef:      item.ConsentRef,
+			APIKeyID:        apiKeyID,
+		})
+	}
+
+	// The batch is answered only if every item is audited (or, under the drop policy, none is),
+	// and queued as a unit so a failure leaves no partial audit behind for a retried request
+	if err := s.Audit.WriteBatch(ctx, audits); err != nil && !errors.Is(err, ErrAuditDropped) {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// parseAsOf validates an as_of date (YYYY-MM-DD, not in the future) and the status_history scope
+func parseAsOf(value string, allowed map[string]bool) (time.Time, error) {
+	if !allowed[models.ScopeStatusHistory] {
+		return time.Time{}, ErrStatusHistoryScopeRequired
+	}
+	asOf, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, &utils.ValidationError{Field: "as_of", Message: "invalid date format, use YYYY-MM-DD"}
+	}
+	if asOf.After(time.Now()) {
+		return time.Time{}, &utils.ValidationError{Field: "as_of", Message: "as_of cannot be in the future"}
+	}
+	return asOf, nil
+}
+
+// setStatusAsOf adds the historical participation status to a check response
+func setStatusAsOf(response models.CheckTKResponse, query models.StatusAsOfQuery, statuses map[string]*string) {
+	response["as_of"] = query.AsOf.Format("2006-01-02")
+	response["status_kepesertaan_as_of"] = nil // no status recorded yet on that date
+	if status := statuses[query.Key()]; status != nil {
+		response["status_kepesertaan_as_of"] = *status
+	}
+}
+
+// logAudit queues the check for the audit table. ErrAuditDropped (drop policy with a full queue)
+// still lets the check be answered; any other error (reject policy with a full queue, or a
+// stopped writer) means the check cannot be audited and must not be answered.
+func (s *CheckingService) logAudit(ctx context.Context, entry *models.CreateAuditLogRequest) error {
+	return s.Audit.Write(ctx, entry)
+}
+
+// AuthorizePurpose checks that a purpose is given and allowed for the partner (also used for bulk job uploads)
+func (s *CheckingService) AuthorizePurpose(ctx context.Context, partnerID, purpose, consentRef string) error {
+	allowed, err := s.PurposeRepo.GetAllowedCodes(ctx, partnerID)
+	if err != nil {
+		return err
+	}
+	return checkPurpose(purpose, consentRef, allowed)
+}
+
+// enabledScopes returns the partner's enabled scopes that are registered and active
+func enabledScopes(scopes []models.PartnerScope, defs map[string]*models.ScopeDefinition) map[string]bool {
 	allowed := make(map[string]bool)
 	for _, s := range scopes {
-		if s.Enabled {
+		if def := defs[s.ScopeName]; s.Enabled && def != nil && def.Active {
 			allowed[s.ScopeName] = true
 		}
 	}
+	return allowed
+}
 
+// consentScope reports whether a scope needs the worker's consent: a sensitive scope exposing a tk_data field
+func consentScope(def *models.ScopeDefinition) bool {
+	return def.Sensitive && def.TKField != nil && def.Mode != models.ScopeModeVerify
+}
+
+// consentedScopes retrieves the scopes each NIK has consented to for the partner.
+// The lookup is skipped when none of the allowed scopes needs consent.
+func (s *CheckingService) consentedScopes(
+	ctx context.Context,
+	partnerID string,
+	allowed map[string]bool,
+	defs map[string]*models.ScopeDefinition,
+	niks []string,
+) (map[string]map[string]bool, error) {
+	for name := range allowed {
+		if consentScope(defs[name]) {
+			return s.ConsentRepo.GetConsentedScopes(ctx, partnerID, niks)
+		}
+	}
+	return nil, nil
+}
+
+// withConsent returns the allowed scopes without the sensitive ones the worker has not consented to,
+// so the response falls back to the partner's non-sensitive scopes (e.g. a masked variant)
+func withConsent(allowed map[string]bool, defs map[string]*models.ScopeDefinition, consented map[string]bool) map[string]bool {
+	gated := make(map[string]bool, len(allowed))
+	for name := range allowed {
+		if consentScope(defs[name]) && !consented[name] {
+			continue
+		}
+		gated[name] = true
+	}
+	return gated
+}
+
+// filterByScopes exposes the tk_data fields mapped to the allowed disclose and mask scopes in the registry.
+// A field allowed by both a disclose and a mask scope is returned in full.
+func filterByScopes(tk *models.TKData, allowed map[string]bool, defs map[string]*models.ScopeDefinition) models.CheckTKResponse {
 	resp := make(models.CheckTKResponse)
 
 	// Always include NIK
 	resp["nik"] = tk.NIK
 
-	// Filter based on scopes
-	if allowed[models.ScopeName] {
-		resp["nama"] = tk.Nama
-	}
-	if allowed[models.ScopeTanggalLahir] {
-		resp["tanggal_lahir"] = tk.TanggalLahirStr
-	}
-	if allowed[models.ScopeStatusBPJS] {
-		resp["status_kepesertaan"] = tk.StatusKepesertaan
+	// Filter based on scopes (permission-only scopes have no field, verify scopes never disclose it)
+	masked := make(map[string]*models.ScopeDefinition)
+	for name := range allowed {
+		def := defs[name]
+		if def.TKField == nil {
+			continue
+		}
+		switch def.Mode {
+		case models.ScopeModeDisclose:
+			if value, ok := tk.Fields[*def.TKField]; ok && value != nil {
+				resp[*def.TKField] = value
+			}
+		case models.ScopeModeMask:
+			masked[*def.TKField] = def
+		}
 	}
-	if allowed[models.ScopeAlamat] && tk.Alamat != nil {
-		resp["alamat"] = *tk.Alamat
+
+	// Masked values only for fields that are not disclosed in full
+	for field, def := range masked {
+		if _, full := resp[field]; full {
+			continue
+		}
+		if value, ok := tk.Fields[field]; ok && value != nil {
+			resp[field] = utils.MaskValue(fmt.Sprint(value), def.MaskVisible, def.MaskTokens == models.MaskTokensEach)
+		}
 	}
 
 	// Always include last update timestamp
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// consentImportBatchSize is the number of consents inserted per transaction
+const consentImportBatchSize = 500
+
+// ConsentService manages worker consents for sensitive scopes
+type ConsentService struct {
+	ConsentRepo   *repository.ConsentRepository
+	PartnerRepo   *repository.PartnerRepository
+	ScopeRegistry *ScopeRegistry
+}
+
+// NewConsentService creates a new consent service
+func NewConsentService(
+	consentRepo *repository.ConsentRepository,
+	partnerRepo *repository.PartnerRepository,
+	scopeRegistry *ScopeRegistry,
+) *ConsentService {
+	return &ConsentService{
+		ConsentRepo:   consentRepo,
+		PartnerRepo:   partnerRepo,
+		ScopeRegistry: scopeRegistry,
+	}
+}
+
+// List retrieves consents filtered by NIK and/or partner, newest first
+func (s *ConsentService) List(ctx context.Context, filter models.ConsentListFilter, limit, offset int) ([]*models.WorkerConsent, error) {
+	if filter.PartnerID != "" {
+		if _, err := uuid.Parse(filter.PartnerID); err != nil {
+			return nil, &utils.ValidationError{Field: "partner_id", Message: "partner_id must be a valid UUID"}
+		}
+	}
+	return s.ConsentRepo.List(ctx, filter, limit, offset)
+}
+
+// Get retrieves a consent (nil if not found)
+func (s *ConsentService) Get(ctx context.Context, id string) (*models.WorkerConsent, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil
+	}
+	return s.ConsentRepo.GetByID(ctx, id)
+}
+
+// Create validates and records a consent given by a worker to a partner
+func (s *ConsentService) Create(ctx context.Context, req *models.CreateConsentRequest, adminID *string) (*models.WorkerConsent, error) {
+	if _, err := uuid.Parse(req.PartnerID); err != nil {
+		return nil, &utils.ValidationError{Field: "partner_id", Message: "partner_id must be a valid UUID"}
+	}
+	if _, err := s.PartnerRepo.GetByID(ctx, req.PartnerID); err != nil {
+		return nil, err
+	}
+
+	defs, err := s.ScopeRegistry.Definitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	consent := &models.WorkerConsent{
+		NIK:        strings.TrimSpace(req.NIK),
+		PartnerID:  req.PartnerID,
+		Scopes:     req.Scopes,
+		ValidUntil: req.ValidUntil,
+		Source:     models.ConsentSourceAdmin,
+		CreatedBy:  adminID,
+	}
+	if req.ValidFrom != nil {
+		consent.ValidFrom = *req.ValidFrom
+	}
+	if ref := strings.TrimSpace(req.ConsentRef); ref != "" {
+		consent.ConsentRef = &ref
+	}
+	if err := validateConsent(consent, defs); err != nil {
+		return nil, err
+	}
+
+	return s.ConsentRepo.Create(ctx, consent)
+}
+
+// Revoke revokes a consent; checks stop disclosing its scopes immediately
+func (s *ConsentService) Revoke(ctx context.Context, id string, adminID *string, reason string) (*models.WorkerConsent, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, repository.ErrConsentNotFound
+	}
+	return s.ConsentRepo.Revoke(ctx, id, adminID, strings.TrimSpace(reason))
+}
+
+// Import records consents collected offline for one partner from a CSV/XLSX file with the columns
+// nik, scopes (separated by ";"), consent_ref and optional valid_from, valid_until. Rows whose
+// consent_ref is already recorded are reported as unchanged, so a file can be imported again.
+// With dryRun nothing is committed.
+func (s *ConsentService) Import(ctx context.Context, partnerID, fileName string, file io.Reader, dryRun bool, adminID *string) (*models.ConsentImportReport, error) {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, &utils.ValidationError{Field: "partner_id", Message: "partner_id must be a valid UUID"}
+	}
+	if _, err := s.PartnerRepo.GetByID(ctx, partnerID); err != nil {
+		return nil, err
+	}
+
+	defs, err := s.ScopeRegistry.Definitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := newImportRowReader(fileName, file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	header, err := reader.Next()
+	if err == io.EOF {
+		return nil, &utils.ValidationError{Field: "file", Message: "file is empty"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff") // Excel BOM
+	}
+
+	cols := map[string]int{}
+	for _, name := range []string{"nik", "scopes", "consent_ref", "valid_from", "valid_until"} {
+		cols[name] = indexOf(header, name)
+	}
+	for _, name := range []string{"nik", "scopes", "consent_ref"} {
+		if cols[name] < 0 {
+			return nil, &utils.ValidationError{Field: "file", Message: fmt.Sprintf("missing required column: %s", name)}
+		}
+	}
+
+	isXLSX := strings.EqualFold(filepath.Ext(fileName), ".xlsx")
+	report := &models.ConsentImportReport{DryRun: dryRun, Rows: []models.ConsentImportRowResult{}}
+	seen := make(map[string]int)
+	batch := make([]*models.WorkerConsent, 0, consentImportBatchSize)
+	batchIdx := make([]int, 0, consentImportBatchSize) // index of each batch row in report.Rows
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		statuses, err := s.ConsentRepo.InsertBatch(ctx, batch, dryRun)
+		if err != nil {
+			log.Printf("ConsentService.Import - %v", err)
+		}
+		for i, idx := range batchIdx {
+			result := &report.Rows[idx]
+			if err != nil {
+				result.Status = models.ImportRowRejected
+				result.Reason = importReasonDatabaseError
+				report.Rejected++
+				continue
+			}
+			result.Status = statuses[i]
+			if statuses[i] == models.ImportRowInserted {
+				report.Inserted++
+			} else {
+				report.Unchanged++
+			}
+		}
+		batch = batch[:0]
+		batchIdx = batchIdx[:0]
+	}
+
+	rowNum := 1 // header
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rowNum++
+		if isEmptyRecord(record) {
+			continue
+		}
+
+		report.Total++
+		consent, reason := parseConsentRecord(record, cols, isXLSX, defs)
+		if reason == "" {
+			if first, dup := seen[*consent.ConsentRef]; dup {
+				reason = fmt.Sprintf("duplicate consent_ref, first seen in row %d", first)
+			}
+		}
+		if reason != "" {
+			report.Rows = append(report.Rows, models.ConsentImportRowResult{
+				Row: rowNum, NIK: consent.NIK, Status: models.ImportRowRejected, Reason: reason,
+			})
+			report.Rejected++
+			continue
+		}
+
+		consent.PartnerID = partnerID
+		consent.Source = models.ConsentSourceImport
+		consent.CreatedBy = adminID
+		seen[*consent.ConsentRef] = rowNum
+		report.Rows = append(report.Rows, models.ConsentImportRowResult{Row: rowNum, NIK: consent.NIK})
+		batch = append(batch, consent)
+		batchIdx = append(batchIdx, len(report.Rows)-1)
+		if len(batch) >= consentImportBatchSize {
+			flush()
+		}
+	}
+	flush()
+
+	return report, nil
+}
+
+// parseConsentRecord validates a raw import row and returns the reason when it is rejected
+func parseConsentRecord(record []string, cols map[string]int, isXLSX bool, defs map[string]*models.ScopeDefinition) (*models.WorkerConsent, string) {
+	consent := &models.WorkerConsent{
+		NIK: column(record, cols["nik"]),
+		Scopes: strings.FieldsFunc(column(record, cols["scopes"]), func(r rune) bool {
+			return r == ';' || r == ',' || r == ' '
+		}),
+	}
+
+	ref := column(record, cols["consent_ref"])
+	if ref == "" {
+		return consent, "consent_ref is required"
+	}
+	consent.ConsentRef = &ref
+
+	for _, field := range []string{"valid_from", "valid_until"} {
+		value := column(record, cols[field])
+		if value == "" {
+			continue
+		}
+		t, err := parseImportDate(value, isXLSX)
+		if err != nil {
+			return consent, fmt.Sprintf("invalid %s, use YYYY-MM-DD", field)
+		}
+		if field == "valid_from" {
+			consent.ValidFrom = models.Date{Time: t}
+		} else {
+			consent.ValidUntil = &models.Date{Time: t}
+		}
+	}
+
+	if err := validateConsent(consent, defs); err != nil {
+		return consent, err.Error()
+	}
+
+	return consent, ""
+}
+
+// validateConsent validates a consent before it is stored: the NIK structure, the validity window
+// (valid_from defaults to today) and the scopes, which must be registered sensitive scopes
+func validateConsent(consent *models.WorkerConsent, defs map[string]*models.ScopeDefinition) error {
+	if _, err := utils.ParseNIK(consent.NIK); err != nil {
+		return err
+	}
+
+	if consent.ValidFrom.IsZero() {
+		now := time.Now()
+		consent.ValidFrom = models.Date{Time: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)}
+	}
+	if consent.ValidUntil != nil && consent.ValidUntil.IsZero() {
+		consent.ValidUntil = nil
+	}
+	if consent.ValidUntil != nil && consent.ValidUntil.Before(consent.ValidFrom.Time) {
+		return &utils.ValidationError{Field: "valid_until", Message: "valid_until must not be before valid_from"}
+	}
+	if consent.ConsentRef != nil && len(*consent.ConsentRef) > maxConsentRefLength {
+		return &utils.ValidationError{Field: "consent_ref", Message: fmt.Sprintf("consent_ref must be maximum %d characters", maxConsentRefLength)}
+	}
+
+	seen := make(map[string]bool, len(consent.Scopes))
+	scopes := make([]string, 0, len(consent.Scopes))
+	for _, name := range consent.Scopes {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		def := defs[name]
+		if def == nil {
+			return &utils.ValidationError{Field: "scopes", Message: fmt.Sprintf("unknown scope: %s", name)}
+		}
+		if !def.Sensitive {
+			return &utils.ValidationError{Field: "scopes", Message: fmt.Sprintf("scope %s is not sensitive and does not need consent", name)}
+		}
+		scopes = append(scopes, name)
+	}
+	if len(scopes) == 0 {
+		return &utils.ValidationError{Field: "scopes", Message: "at least one scope is required"}
+	}
+	sort.Strings(scopes)
+	consent.Scopes = scopes
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// ErrAPIKeyLimitReached is returned when a partner already holds maxActiveAPIKeys active keys
+var ErrAPIKeyLimitReached = errors.New("partner already has the maximum number of active API keys, revoke one first")
+
+// maxActiveAPIKeys bounds the API keys a partner can hold at the same time (rotation may exceed it briefly)
+const maxActiveAPIKeys = 10
+
+// maxAPIKeyGraceHours bounds how long a rotated key stays valid
+const maxAPIKeyGraceHours = 30 * 24
+
+// maxAPIKeyLabelLength matches partner_api_keys.label
+const maxAPIKeyLabelLength = 100
+
+// apiKeyTouchInterval limits last_used_at updates to one per key and interval
+const apiKeyTouchInterval = time.Minute
+
+// Label of the key issued with a new partner and by a reset
+const defaultAPIKeyLabel = "default"
+
+// PartnerAPIKeyService handles partner API keys: issuing, rotation with a grace period,
+// revocation and authentication
+type PartnerAPIKeyService struct {
+	APIKeyRepo    *repository.PartnerAPIKeyRepository
+	PartnerRepo   *repository.PartnerRepository
+	RotationGrace time.Duration // default time a rotated key stays valid
+
+	pepper []byte // HMAC key of the stored secret hashes
+}
+
+// NewPartnerAPIKeyService creates a new partner API key service
+func NewPartnerAPIKeyService(
+	apiKeyRepo *repository.PartnerAPIKeyRepository,
+	partnerRepo *repository.PartnerRepository,
+	pepper []byte,
+	rotationGrace time.Duration,
+) *PartnerAPIKeyService {
+	return &PartnerAPIKeyService{
+		APIKeyRepo:    apiKeyRepo,
+		PartnerRepo:   partnerRepo,
+		RotationGrace: rotationGrace,
+		pepper:        pepper,
+	}
+}
+
+// Authenticate returns the partner and the key of an API key, or nils when the key is unknown,
+// wrong, revoked or expired. pks_live_ keys are looked up by key ID and their secret hash compared
+// in constant time; other keys are legacy UUID keys, looked up by their SHA-256.
+func (s *PartnerAPIKeyService) Authenticate(ctx context.Context, apiKey string) (*models.Partner, *models.PartnerAPIKey, error) {
+	var key *models.PartnerAPIKey
+	var err error
+	if keyID, secret, ok := utils.ParseAPIKey(apiKey); ok {
+		key, err = s.APIKeyRepo.GetByKeyID(ctx, keyID)
+		if err != nil || key == nil {
+			return nil, nil, err
+		}
+		hash := utils.HashAPIKeySecret(s.pepper, secret)
+		if !hmac.Equal([]byte(hash), []byte(key.KeyHash)) {
+			return nil, nil, nil
+		}
+	} else {
+		key, err = s.APIKeyRepo.GetLegacyByHash(ctx, utils.HashLegacyAPIKey(apiKey))
+		if err != nil || key == nil {
+			return nil, nil, err
+		}
+	}
+	if key.State != models.APIKeyStateActive {
+		return nil, nil, nil
+	}
+
+	partner, err := s.PartnerRepo.GetByID(ctx, key.PartnerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if key.LastUsedAt == nil || time.Since(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.APIKeyRepo.TouchLastUsed(ctx, key.ID); err != nil {
+			log.Printf("PartnerAPIKeyService.Authenticate - %v", err)
+		}
+	}
+
+	return partner, key, nil
+}
+
+// List retrieves all API keys of a partner (active, expired and revoked)
+func (s *PartnerAPIKeyService) List(ctx context.Context, partnerID string) ([]*models.PartnerAPIKey, error) {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, repository.ErrPartnerNotFound
+	}
+	if _, err := s.PartnerRepo.GetByID(ctx, partnerID); err != nil {
+		return nil, err
+	}
+	return s.APIKeyRepo.ListByPartnerID(ctx, partnerID)
+}
+
+// Create adds a named API key to a partner and returns its plaintext once
+func (s *PartnerAPIKeyService) Create(ctx context.Context, partnerID string, req *models.CreatePartnerAPIKeyRequest, adminID *string) (*models.PartnerAPIKeyResponse, error) {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, repository.ErrPartnerNotFound
+	}
+	label := strings.TrimSpace(req.Label)
+	if label == "" {
+		return nil, &utils.ValidationError{Field: "label", Message: "label is required"}
+	}
+	if len(label) > maxAPIKeyLabelLength {
+		return nil, &utils.ValidationError{Field: "label", Message: fmt.Sprintf("label must be maximum %d characters", maxAPIKeyLabelLength)}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, &utils.ValidationError{Field: "expires_at", Message: "expires_at must be in the future"}
+	}
+
+	if _, err := s.PartnerRepo.GetByID(ctx, partnerID); err != nil {
+		return nil, err
+	}
+	active, err := s.APIKeyRepo.CountActive(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+	if active >= maxActiveAPIKeys {
+		return nil, ErrAPIKeyLimitReached
+	}
+
+	plain, key := s.newKey(partnerID, label, req.ExpiresAt, adminID)
+	created, err := s.APIKeyRepo.Create(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PartnerAPIKeyResponse{PartnerAPIKey: created, APIKeyPlain: plain}, nil
+}
+
+// Issue adds the first API key of a newly created partner
+func (s *PartnerAPIKeyService) Issue(ctx context.Context, partnerID string, adminID *string) (*models.PartnerAPIKeyResponse, error) {
+	plain, key := s.newKey(partnerID, defaultAPIKeyLabel, nil, adminID)
+	created, err := s.APIKeyRepo.Create(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PartnerAPIKeyResponse{PartnerAPIKey: created, APIKeyPlain: plain}, nil
+}
+
+// Rotate replaces an active key by a new one with the same label and expiry. The old key stays
+// valid for the grace period (req.GraceHours, default RotationGrace; 0 revokes it at once).
+func (s *PartnerAPIKeyService) Rotate(ctx context.Context, partnerID, id string, req *models.RotatePartnerAPIKeyRequest, adminID *string) (*models.PartnerAPIKeyRotation, error) {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, repository.ErrPartnerNotFound
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, repository.ErrAPIKeyNotFound
+	}
+
+	grace := s.RotationGrace
+	if req.GraceHours != nil {
+		if *req.GraceHours < 0 || *req.GraceHours > maxAPIKeyGraceHours {
+			return nil, &utils.ValidationError{Field: "grace_hours", Message: fmt.Sprintf("grace_hours must be between 0 and %d", maxAPIKeyGraceHours)}
+		}
+		grace = time.Duration(*req.GraceHours) * time.Hour
+	}
+
+	if _, err := s.PartnerRepo.GetByID(ctx, partnerID); err != nil {
+		return nil, err
+	}
+
+	plain, key := s.newKey(partnerID, "", nil, adminID)
+	created, old, err := s.APIKeyRepo.Rotate(ctx, partnerID, id, key, grace, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PartnerAPIKeyRotation{
+		New: &models.PartnerAPIKeyResponse{PartnerAPIKey: created, APIKeyPlain: plain},
+		Old: old,
+	}, nil
+}
+
+// Revoke revokes an API key of a partner immediately
+func (s *PartnerAPIKeyService) Revoke(ctx context.Context, partnerID, id string, adminID *string) (*models.PartnerAPIKey, error) {
+	if _, err := uuid.Parse(partnerID); err != nil {
+		return nil, repository.ErrPartnerNotFound
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, repository.ErrAPIKeyNotFound
+	}
+	if _, err := s.PartnerRepo.GetByID(ctx, partnerID); err != nil {
+		return nil, err
+	}
+	return s.APIKeyRepo.Revoke(ctx, partnerID, id, adminID)
+}
+
+// Reset revokes every key of a partner immediately and issues a single new one (leaked key)
+func (s *PartnerAPIKeyService) Reset(ctx context.Context, partnerID string, adminID *string) (*models.PartnerAPIKeyResponse, error) {
+	plain, key := s.newKey(partnerID, defaultAPIKeyLabel, nil, adminID)
+	created, err := s.APIKeyRepo.ReplaceAll(ctx, key, adminID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PartnerAPIKeyResponse{PartnerAPIKey: created, APIKeyPlain: plain}, nil
+}
+
+// newKey generates an API key and returns its plaintext with the row to store (key ID and secret hash)
+func (s *PartnerAPIKeyService) newKey(partnerID, label string, expiresAt *time.Time, adminID *string) (string, *models.PartnerAPIKey) {
+	plain, keyID, secret := utils.GenerateAPIKey()
+	return plain, &models.PartnerAPIKey{
+		PartnerID: partnerID,
+		Label:     label,
+		KeyID:     &keyID,
+		KeyHash:   utils.HashAPIKeySecret(s.pepper, secret),
+		CreatedBy: adminID,
+		ExpiresAt: expiresAt,
+	}
+}
@@ -2,30 +2,100 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/username/go-gin-backend/internal/models"
 	"github.com/username/go-gin-backend/internal/repository"
 	"github.com/username/go-gin-backend/pkg/utils"
 )
 
+// ErrPartnerAPIKeyNotIssued is returned with the created partner when its first API key could not be issued
+var ErrPartnerAPIKeyNotIssued = errors.New("partner created without an API key, add one with POST /admin/partners/:id/api-keys")
+
 // PartnerService handles partner business logic
 type PartnerService struct {
-	PartnerRepo *repository.PartnerRepository
-	ScopeRepo   *repository.ScopeRepository
+	PartnerRepo   *repository.PartnerRepository
+	ScopeRepo     *repository.ScopeRepository
+	PackageRepo   *repository.ScopePackageRepository
+	ScopeRegistry *ScopeRegistry
+	APIKeys       *PartnerAPIKeyService
+	Purposes      *PurposeService
+	// DefaultPurposes are put in the contract of a partner created without purposes
+	DefaultPurposes []string
 }
 
 // NewPartnerService creates a new partner service
-func NewPartnerService(partnerRepo *repository.PartnerRepository, scopeRepo *repository.ScopeRepository) *PartnerService {
+func NewPartnerService(
+	partnerRepo *repository.PartnerRepository,
+	scopeRepo *repository.ScopeRepository,
+	packageRepo *repository.ScopePackageRepository,
+	scopeRegistry *ScopeRegistry,
+	apiKeys *PartnerAPIKeyService,
+	purposes *PurposeService,
+	defaultPurposes []string,
+) *PartnerService {
 	return &PartnerService{
-		PartnerRepo: partnerRepo,
-		ScopeRepo:   scopeRepo,
+		PartnerRepo:     partnerRepo,
+		ScopeRepo:       scopeRepo,
+		PackageRepo:     packageRepo,
+		ScopeRegistry:   scopeRegistry,
+		APIKeys:         apiKeys,
+		Purposes:        purposes,
+		DefaultPurposes: defaultPurposes,
 	}
 }
 
-// CreatePartner creates a new partner with auto-generated API key, contract dates, and scopes
-func (s *PartnerService) CreatePartner(ctx context.Context, req *models.CreatePartnerRequest) (*models.PartnerResponse, error) {
+// CreatePartner creates a new partner with auto-generated API key, contract dates, scopes and the
+// purposes in its contract (DefaultPurposes when none are given)
+func (s *PartnerService) CreatePartner(ctx context.Context, req *models.CreatePartnerRequest, adminID *string) (*models.PartnerResponse, error) {
+	// Optional scope package; without one, scopes default to DefaultScopes
+	var packageID *string
+	if req.ScopePackageID != nil && *req.ScopePackageID != "" {
+		if _, err := uuid.Parse(*req.ScopePackageID); err != nil {
+			return nil, &utils.ValidationError{Field: "scope_package_id", Message: "scope_package_id must be a valid UUID"}
+		}
+		pkg, err := s.PackageRepo.GetByID(ctx, *req.ScopePackageID)
+		if err != nil {
+			return nil, err
+		}
+		if pkg == nil {
+			return nil, &utils.ValidationError{Field: "scope_package_id", Message: "scope package not found"}
+		}
+		packageID = &pkg.ID
+	}
+
+	// Create scopes (default jika tidak ada yang diberikan)
+	scopes := req.Scopes
+	if len(scopes) == 0 && packageID == nil {
+		scopes = models.DefaultScopes()
+	}
+
+	// Validate scopes against the registry before anything is created
+	items := make([]models.ScopeItem, len(scopes))
+	for i, name := range scopes {
+		items[i] = models.ScopeItem{ScopeName: name, Enabled: true}
+	}
+	if err := s.ScopeRegistry.ValidateScopes(ctx, items); err != nil {
+		return nil, err
+	}
+
+	// Purposes in the contract: without any, every check of the partner is rejected
+	purposes := req.Purposes
+	if len(purposes) == 0 {
+		purposes = s.DefaultPurposes
+	}
+	if len(purposes) == 0 {
+		return nil, &utils.ValidationError{Field: "purposes", Message: "purposes is required"}
+	}
+	purposes, err := s.Purposes.ValidateCodes(ctx, purposes)
+	if err != nil {
+		return nil, err
+	}
+
 	// Use provided company_id or generate one
 	companyID := req.CompanyID
 	if companyID == "" {
@@ -42,9 +112,6 @@ func (s *PartnerService) CreatePartner(ctx context.Context, req *models.CreatePa
 	
 	nomorPKS := utils.GeneratePKSNumber()
 
-	// Generate API key (plaintext, stored as-is in database)
-	apiKey := utils.GenerateAPIKey()
-
 	// Set contract dates (default to today and 1 year from today if not provided)
 	contractStart := time.Now()
 	if req.ContractStart != nil && !req.ContractStart.Time.IsZero() {
@@ -69,7 +136,6 @@ func (s *PartnerService) CreatePartner(ctx context.Context, req *models.CreatePa
 	partner := &models.Partner{
 		CompanyName:   req.CompanyName,
 		CompanyID:     companyID,
-		APIKey:        &apiKey,
 		CompanySecret: "", // Deprecated, kept empty
 		NomorPKS:      nomorPKS,
 		PICName:       req.PICName,
@@ -85,20 +151,30 @@ func (s *PartnerService) CreatePartner(ctx context.Context, req *models.CreatePa
 		return nil, fmt.Errorf("failed to create partner: %w", err)
 	}
 
-	// Create scopes (default jika tidak ada yang diberikan)
-	scopes := req.Scopes
-	if len(scopes) == 0 {
-		scopes = models.DefaultScopes()
+	if err := s.ScopeRepo.BulkCreate(ctx, partner.ID, scopes, adminID); err != nil {
+		return nil, fmt.Errorf("failed to create scopes: %w", err)
+	}
+	if packageID != nil {
+		if err := s.PackageRepo.AssignToPartner(ctx, partner.ID, packageID, adminID); err != nil {
+			return nil, fmt.Errorf("failed to assign scope package: %w", err)
+		}
+	}
+	if err := s.Purposes.PurposeRepo.SetForPartner(ctx, partner.ID, purposes); err != nil {
+		return nil, fmt.Errorf("failed to set partner purposes: %w", err)
 	}
 
-	if err := s.ScopeRepo.BulkCreate(ctx, partner.ID, scopes); err != nil {
-		return nil, fmt.Errorf("failed to create scopes: %w", err)
+	// First API key, labelled "default" (only the key ID and the secret hash are stored). The
+	// partner is already committed, so a failure returns it for the admin to add a key.
+	apiKey, err := s.APIKeys.Issue(ctx, partner.ID, adminID)
+	if err != nil {
+		log.Printf("PartnerService.CreatePartner - failed to issue API key for partner %s: %v", partner.ID, err)
+		return &models.PartnerResponse{Partner: partner}, ErrPartnerAPIKeyNotIssued
 	}
 
 	response := &models.PartnerResponse{
 		Partner:       partner,
-		CompanyIDInfo: fmt.Sprintf("Use API key '%s' in X-API-KEY header for API requests", apiKey),
-		APIKeyPlain:   apiKey, // Return plaintext API key (only once on creation)
+		CompanyIDInfo: fmt.Sprintf("Use API key '%s' in X-API-KEY header for API requests", apiKey.APIKeyPlain),
+		APIKeyPlain:   apiKey.APIKeyPlain, // Return plaintext API key (only once on creation)
 	}
 
 	return response, nil
@@ -143,22 +219,161 @@ func (s *PartnerService) DeletePartner(ctx context.Context, id string) error {
 	return s.PartnerRepo.Delete(ctx, id)
 }
 
-// GetPartnerScopes retrieves scopes for a partner
+// GetPartnerScopes retrieves scopes for a partner, including upcoming and expired grants
 func (s *PartnerService) GetPartnerScopes(ctx context.Context, partnerID string) ([]models.PartnerScope, error) {
 	return s.ScopeRepo.GetByPartnerID(ctx, partnerID)
 }
 
-// UpdatePartnerScopes updates scopes for a partner
-func (s *PartnerService) UpdatePartnerScopes(ctx context.Context, partnerID string, req *models.UpdateScopesRequest) error {
-	return s.ScopeRepo.BulkUpdate(ctx, partnerID, req.Scopes)
+// UpdatePartnerScopes updates scopes for a partner (recorded as a new scope version)
+func (s *PartnerService) UpdatePartnerScopes(ctx context.Context, partnerID string, req *models.UpdateScopesRequest, adminID *string) error {
+	if err := s.ScopeRegistry.ValidateScopes(ctx, req.Scopes); err != nil {
+		return err
+	}
+	for _, scope := range req.Scopes {
+		if scope.ValidFrom != nil && scope.ValidUntil != nil &&
+			!scope.ValidFrom.IsZero() && !scope.ValidUntil.IsZero() && scope.ValidUntil.Before(scope.ValidFrom.Time) {
+			return &utils.ValidationError{
+				Field:   "scopes",
+				Message: fmt.Sprintf("valid_until must not be before valid_from for scope %s", scope.ScopeName),
+			}
+		}
+	}
+	return s.ScopeRepo.BulkUpdate(ctx, partnerID, req.Scopes, adminID)
+}
+
+// ListScopeVersions retrieves the scope change history of a partner, newest first
+func (s *PartnerService) ListScopeVersions(ctx context.Context, partnerID string) ([]*models.PartnerScopeVersion, error) {
+	return s.ScopeRepo.GetVersions(ctx, partnerID)
+}
+
+// DiffScopeVersions compares the scope snapshots of two versions
+func (s *PartnerService) DiffScopeVersions(ctx context.Context, partnerID string, from, to int) (*models.ScopeVersionDiff, error) {
+	fromVersion, err := s.ScopeRepo.GetVersion(ctx, partnerID, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := s.ScopeRepo.GetVersion(ctx, partnerID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.ScopeVersionDiff{
+		PartnerID:   partnerID,
+		FromVersion: from,
+		ToVersion:   to,
+		Added:       []models.ScopeSnapshotItem{},
+		Removed:     []models.ScopeSnapshotItem{},
+		Changed:     []models.ScopeItemChange{},
+	}
+
+	before := make(map[string]models.ScopeSnapshotItem, len(fromVersion.Scopes))
+	for _, item := range fromVersion.Scopes {
+		before[item.ScopeName] = item
+	}
+	for _, after := range toVersion.Scopes {
+		old, ok := before[after.ScopeName]
+		delete(before, after.ScopeName)
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, after)
+		case !sameScopeItem(old, after):
+			diff.Changed = append(diff.Changed, models.ScopeItemChange{ScopeName: after.ScopeName, Before: old, After: after})
+		}
+	}
+	for _, item := range fromVersion.Scopes {
+		if _, removed := before[item.ScopeName]; removed {
+			diff.Removed = append(diff.Removed, item)
+		}
+	}
+	diff.Package = diffScopePackage(fromVersion, toVersion)
+
+	return diff, nil
+}
+
+// diffScopePackage compares the package and package scopes of two versions (nil when equal
+// or when either version predates package versioning)
+func diffScopePackage(from, to *models.PartnerScopeVersion) *models.ScopePackageChange {
+	if from.PackageScopes == nil || to.PackageScopes == nil {
+		return nil
+	}
+
+	change := &models.ScopePackageChange{
+		Before:        from.ScopePackageID,
+		After:         to.ScopePackageID,
+		AddedScopes:   []string{},
+		RemovedScopes: []string{},
+	}
+	before := make(map[string]bool, len(from.PackageScopes))
+	for _, name := range from.PackageScopes {
+		before[name] = true
+	}
+	for _, name := range to.PackageScopes {
+		if before[name] {
+			delete(before, name)
+		} else {
+			change.AddedScopes = append(change.AddedScopes, name)
+		}
+	}
+	for _, name := range from.PackageScopes {
+		if before[name] {
+			change.RemovedScopes = append(change.RemovedScopes, name)
+		}
+	}
+
+	if sameString(from.ScopePackageID, to.ScopePackageID) && len(change.AddedScopes) == 0 && len(change.RemovedScopes) == 0 {
+		return nil
+	}
+	return change
+}
+
+// sameString compares two optional strings
+func sameString(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// RollbackScopes restores the scope rows and the scope package of an earlier version. The
+// restore itself is recorded as a new version; scopes that are no longer registered or active
+// are rejected. The package gets its current scopes, not those recorded in the version.
+func (s *PartnerService) RollbackScopes(ctx context.Context, partnerID string, version int, adminID *string) (int, error) {
+	target, err := s.ScopeRepo.GetVersion(ctx, partnerID, version)
+	if err != nil {
+		return 0, err
+	}
+
+	items := make([]models.ScopeItem, len(target.Scopes))
+	for i, scope := range target.Scopes {
+		items[i] = models.ScopeItem{ScopeName: scope.ScopeName, Enabled: scope.Enabled}
+	}
+	if err := s.ScopeRegistry.ValidateScopes(ctx, items); err != nil {
+		return 0, err
+	}
+
+	return s.ScopeRepo.RestoreVersion(ctx, partnerID, version, adminID)
+}
+
+// sameScopeItem reports whether two snapshot rows grant the same access
+func sameScopeItem(a, b models.ScopeSnapshotItem) bool {
+	return a.Enabled == b.Enabled && sameDate(a.ValidFrom, b.ValidFrom) && sameDate(a.ValidUntil, b.ValidUntil)
+}
+
+// sameDate compares two optional dates
+func sameDate(a, b *models.Date) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Time.Equal(b.Time)
 }
 
 // IssuePartnerToken is DEPRECATED - No longer used, replaced by API Key authentication
 // This method is kept for backward compatibility but should not be used
 // func (s *PartnerService) IssuePartnerToken(...) - REMOVED
 
-// ResetAPIKey generates a new API key and returns plaintext once (for security when token is leaked)
-func (s *PartnerService) ResetAPIKey(ctx context.Context, partnerID string) (*models.PartnerResponse, error) {
+// ResetAPIKey revokes all API keys of the partner at once and returns a single new key in plaintext once
+// (for security when a key is leaked; use PartnerAPIKeyService.Rotate to replace a key with a grace period)
+func (s *PartnerService) ResetAPIKey(ctx context.Context, partnerID string, adminID *string) (*models.PartnerResponse, error) {
 	partner, err := s.PartnerRepo.GetByID(ctx, partnerID)
 	if err != nil {
 		return nil, err
@@ -167,21 +382,16 @@ func (s *PartnerService) ResetAPIKey(ctx context.Context, partnerID string) (*mo
 		return nil, fmt.Errorf("partner not found")
 	}
 
-	// Generate new API key
-	newAPIKey := utils.GenerateAPIKey()
-
-	// Update API key in database (old key becomes invalid immediately)
-	if err := s.PartnerRepo.UpdateAPIKey(ctx, partnerID, newAPIKey); err != nil {
+	// Revoke every key and issue a new one (old keys, legacy or not, become invalid immediately)
+	apiKey, err := s.APIKeys.Reset(ctx, partnerID, adminID)
+	if err != nil {
 		return nil, fmt.Errorf("failed to reset API key: %w", err)
 	}
 
-	// Update partner object with new API key
-	partner.APIKey = &newAPIKey
-
 	resp := &models.PartnerResponse{
 		Partner:       partner,
-		CompanyIDInfo: fmt.Sprintf("Use API key '%s' in X-API-KEY header for API requests", newAPIKey),
-		APIKeyPlain:   newAPIKey, // Return plaintext API key (only once on reset)
+		CompanyIDInfo: fmt.Sprintf("Use API key '%s' in X-API-KEY header for API requests", apiKey.APIKeyPlain),
+		APIKeyPlain:   apiKey.APIKeyPlain, // Return plaintext API key (only once on reset)
 	}
 	return resp, nil
 }
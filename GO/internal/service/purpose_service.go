@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// ErrPurposeNotAllowed is returned when a check states a purpose outside the partner's contract
+var ErrPurposeNotAllowed = errors.New("purpose is not allowed by the partner contract")
+
+// maxConsentRefLength matches audit_logs.consent_ref
+const maxConsentRefLength = 100
+
+var purposeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+// PurposeService manages purposes of use and the purposes allowed per partner
+type PurposeService struct {
+	PurposeRepo *repository.PurposeRepository
+	PartnerRepo *repository.PartnerRepository
+}
+
+// NewPurposeService creates a new purpose service
+func NewPurposeService(purposeRepo *repository.PurposeRepository, partnerRepo *repository.PartnerRepository) *PurposeService {
+	return &PurposeService{
+		PurposeRepo: purposeRepo,
+		PartnerRepo: partnerRepo,
+	}
+}
+
+// List retrieves all purposes
+func (s *PurposeService) List(ctx context.Context) ([]*models.Purpose, error) {
+	return s.PurposeRepo.GetAll(ctx)
+}
+
+// Create validates and adds a purpose
+func (s *PurposeService) Create(ctx context.Context, req *models.CreatePurposeRequest) (*models.Purpose, error) {
+	req.Code = strings.TrimSpace(req.Code)
+	if !purposeCodePattern.MatchString(req.Code) {
+		return nil, &utils.ValidationError{Field: "code", Message: "code must be 2-50 characters of lowercase letters, digits or underscore, starting with a letter"}
+	}
+
+	purpose := &models.Purpose{
+		Code:        req.Code,
+		Description: strings.TrimSpace(req.Description),
+		Active:      true,
+	}
+	if err := s.PurposeRepo.Create(ctx, purpose); err != nil {
+		return nil, err
+	}
+
+	return purpose, nil
+}
+
+// Update updates a purpose (deactivating it blocks its use in checks without touching contracts)
+func (s *PurposeService) Update(ctx context.Context, code string, req *models.UpdatePurposeRequest) (*models.Purpose, error) {
+	if req.Description == nil && req.Active == nil {
+		return nil, &utils.ValidationError{Field: "body", Message: "no fields to update"}
+	}
+	return s.PurposeRepo.Update(ctx, code, req)
+}
+
+// GetPartnerPurposes retrieves the purposes in a partner's contract
+func (s *PurposeService) GetPartnerPurposes(ctx context.Context, partnerID string) ([]*models.Purpose, error) {
+	if _, err := s.PartnerRepo.GetByID(ctx, partnerID); err != nil {
+		return nil, err
+	}
+	return s.PurposeRepo.GetByPartnerID(ctx, partnerID)
+}
+
+// SetPartnerPurposes replaces the purposes in a partner's contract; every code must exist and be active
+func (s *PurposeService) SetPartnerPurposes(ctx context.Context, partnerID string, codes []string) ([]*models.Purpose, error) {
+	if _, err := s.PartnerRepo.GetByID(ctx, partnerID); err != nil {
+		return nil, err
+	}
+
+	unique, err := s.ValidateCodes(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.PurposeRepo.SetForPartner(ctx, partnerID, unique); err != nil {
+		return nil, err
+	}
+
+	return s.PurposeRepo.GetByPartnerID(ctx, partnerID)
+}
+
+// ValidateCodes checks that every purpose code exists and is active and returns the codes without duplicates
+func (s *PurposeService) ValidateCodes(ctx context.Context, codes []string) ([]string, error) {
+	all, err := s.PurposeRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	active := make(map[string]bool, len(all))
+	for _, p := range all {
+		active[p.Code] = p.Active
+	}
+
+	seen := make(map[string]bool, len(codes))
+	unique := make([]string, 0, len(codes))
+	var invalid []string
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		if !active[code] {
+			invalid = append(invalid, code)
+			continue
+		}
+		unique = append(unique, code)
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return nil, &utils.ValidationError{Field: "purposes", Message: fmt.Sprintf("unknown or inactive purpose: %s", strings.Join(invalid, ", "))}
+	}
+
+	return unique, nil
+}
+
+// checkPurpose validates the purpose and consent reference of a check against the partner's allowed purposes
+func checkPurpose(purpose, consentRef string, allowed map[string]bool) error {
+	if purpose == "" {
+		return &utils.ValidationError{Field: "purpose", Message: "purpose is required"}
+	}
+	if len(consentRef) > maxConsentRefLength {
+		return &utils.ValidationError{Field: "consent_ref", Message: fmt.Sprintf("consent_ref must be maximum %d characters", maxConsentRefLength)}
+	}
+	if !allowed[purpose] {
+		return fmt.Errorf("%w: %s", ErrPurposeNotAllowed, purpose)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// receiptAuditSkew bounds the difference between a receipt's iat and its audit row's created_at
+// (audit rows are written asynchronously right after the receipt is signed)
+const receiptAuditSkew = 5 * time.Minute
+
+// ReceiptService signs verification receipts for checks and verifies them against audit_logs
+type ReceiptService struct {
+	AuditRepo *repository.AuditRepository
+	Privacy   *AuditPrivacy // keyed nik_hash, matched against tokenized NIKs
+
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// NewReceiptService creates a new receipt service signing with the given Ed25519 key
+func NewReceiptService(auditRepo *repository.AuditRepository, privacy *AuditPrivacy, key ed25519.PrivateKey, keyID string) *ReceiptService {
+	return &ReceiptService{
+		AuditRepo: auditRepo,
+		Privacy:   privacy,
+		key:       key,
+		keyID:     keyID,
+	}
+}
+
+// Sign issues a compact JWS receipt for an audited check
+func (s *ReceiptService) Sign(auditID, partnerID, nik, outcome string, issuedAt time.Time) (string, error) {
+	return utils.SignReceipt(&utils.ReceiptClaims{
+		AuditID:   auditID,
+		PartnerID: partnerID,
+		NIKHash:   s.Privacy.NIKHash(nik),
+		Outcome:   outcome,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt: jwt.NewNumericDate(issuedAt),
+		},
+	}, s.key, s.keyID)
+}
+
+// JWKS returns the public key used to sign receipts
+func (s *ReceiptService) JWKS() models.JWKS {
+	return models.JWKS{Keys: []models.JWK{{
+		KeyType: "OKP",
+		Curve:   "Ed25519",
+		X:       base64.RawURLEncoding.EncodeToString(s.key.Public().(ed25519.PublicKey)),
+		KeyID:   s.keyID,
+		Alg:     jwt.SigningMethodEdDSA.Alg(),
+		Use:     "sig",
+	}}}
+}
+
+// Verify checks a receipt's signature and that it matches the audit log it references.
+// An invalid receipt is not an error: the result carries Valid=false and the reason.
+func (s *ReceiptService) Verify(ctx context.Context, receipt string) (*models.ReceiptVerification, error) {
+	claims, err := utils.ParseReceipt(receipt, s.key.Public().(ed25519.PublicKey), s.keyID)
+	if err != nil {
+		return &models.ReceiptVerification{Valid: false, Reason: "invalid signature or malformed receipt"}, nil
+	}
+
+	result := &models.ReceiptVerification{
+		AuditID:   claims.AuditID,
+		PartnerID: claims.PartnerID,
+		NIKHash:   claims.NIKHash,
+		Outcome:   claims.Outcome,
+	}
+	if claims.IssuedAt != nil {
+		result.IssuedAt = &claims.IssuedAt.Time
+	}
+
+	if _, err := uuid.Parse(claims.AuditID); err != nil {
+		result.Reason = "audit log not found"
+		return result, nil
+	}
+	entry, err := s.AuditRepo.GetByID(ctx, claims.AuditID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case entry == nil:
+		result.Reason = "audit log not found"
+	case entry.PartnerID != claims.PartnerID:
+		result.Reason = "partner does not match the audit log"
+	case claims.NIKHash != "" && !s.Privacy.MatchesNIKHash(entry.NIK, claims.NIKHash): // no nik_hash: bound by audit_id only
+		result.Reason = "nik hash does not match the audit log"
+	case entry.ResultCode == nil || !outcomeMatches(claims.Outcome, *entry.ResultCode):
+		result.Reason = "outcome does not match the audit log"
+	case result.IssuedAt == nil || absDuration(entry.CreatedAt.Sub(*result.IssuedAt)) > receiptAuditSkew:
+		result.Reason = "timestamp does not match the audit log"
+	default:
+		result.Valid = true
+	}
+
+	return result, nil
+}
+
+// receiptOutcome returns the outcome stated in a receipt: the result code with the result_detail
+// scope, otherwise only whether the worker was found
+func receiptOutcome(code string, detailed bool) string {
+	if detailed {
+		return code
+	}
+	if isMatch(code) {
+		return models.ReceiptOutcomeFound
+	}
+	return models.ReceiptOutcomeNotFound
+}
+
+// outcomeMatches reports whether a receipt outcome is consistent with the audited result code
+func outcomeMatches(outcome, code string) bool {
+	return outcome == code || outcome == receiptOutcome(code, false)
+}
+
+// absDuration returns the absolute value of a duration
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// ScopePackageService handles scope packages and their assignment to partners
+type ScopePackageService struct {
+	PackageRepo   *repository.ScopePackageRepository
+	ScopeRegistry *ScopeRegistry
+}
+
+// NewScopePackageService creates a new scope package service
+func NewScopePackageService(packageRepo *repository.ScopePackageRepository, scopeRegistry *ScopeRegistry) *ScopePackageService {
+	return &ScopePackageService{
+		PackageRepo:   packageRepo,
+		ScopeRegistry: scopeRegistry,
+	}
+}
+
+// List retrieves all scope packages
+func (s *ScopePackageService) List(ctx context.Context) ([]*models.ScopePackage, error) {
+	return s.PackageRepo.GetAll(ctx)
+}
+
+// Get retrieves a scope package by ID (nil if not found)
+func (s *ScopePackageService) Get(ctx context.Context, id string) (*models.ScopePackage, error) {
+	return s.PackageRepo.GetByID(ctx, id)
+}
+
+// Create validates and creates a scope package
+func (s *ScopePackageService) Create(ctx context.Context, req *models.CreateScopePackageRequest) (*models.ScopePackage, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, &utils.ValidationError{Field: "name", Message: "name is required"}
+	}
+	if len(req.Name) > 100 {
+		return nil, &utils.ValidationError{Field: "name", Message: "name must be maximum 100 characters"}
+	}
+	if err := s.validatePackageScopes(ctx, req.Scopes); err != nil {
+		return nil, err
+	}
+
+	pkg := &models.ScopePackage{
+		Name:        req.Name,
+		Description: strings.TrimSpace(req.Description),
+		Scopes:      req.Scopes,
+	}
+	if err := s.PackageRepo.Create(ctx, pkg); err != nil {
+		return nil, err
+	}
+
+	return s.PackageRepo.GetByID(ctx, pkg.ID)
+}
+
+// Update validates and updates a scope package. Partners on the package pick up the
+// change immediately because effective scopes are resolved on every request; a scope
+// change is recorded as a scope version of each of them.
+func (s *ScopePackageService) Update(ctx context.Context, id string, req *models.UpdateScopePackageRequest, adminID *string) (*models.ScopePackage, error) {
+	if req.Name == nil && req.Description == nil && req.Scopes == nil {
+		return nil, &utils.ValidationError{Field: "body", Message: "no fields to update"}
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" || len(name) > 100 {
+			return nil, &utils.ValidationError{Field: "name", Message: "name must be 1-100 characters"}
+		}
+		req.Name = &name
+	}
+	if req.Scopes != nil {
+		if err := s.validatePackageScopes(ctx, req.Scopes); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.PackageRepo.Update(ctx, id, req, adminID); err != nil {
+		return nil, err
+	}
+
+	return s.PackageRepo.GetByID(ctx, id)
+}
+
+// Delete deletes a scope package that is not assigned to any partner
+func (s *ScopePackageService) Delete(ctx context.Context, id string) error {
+	return s.PackageRepo.Delete(ctx, id)
+}
+
+// GetPartnerPackage retrieves the package assigned to a partner (nil if none)
+func (s *ScopePackageService) GetPartnerPackage(ctx context.Context, partnerID string) (*models.ScopePackage, error) {
+	return s.PackageRepo.GetByPartnerID(ctx, partnerID)
+}
+
+// AssignToPartner assigns a package to a partner, or removes the assignment when packageID is nil.
+// Per-partner scope rows are kept and keep overriding the package. The change is recorded as a scope version.
+func (s *ScopePackageService) AssignToPartner(ctx context.Context, partnerID string, packageID *string, adminID *string) (*models.ScopePackage, error) {
+	if packageID != nil && *packageID == "" {
+		packageID = nil
+	}
+	if err := s.PackageRepo.AssignToPartner(ctx, partnerID, packageID, adminID); err != nil {
+		return nil, err
+	}
+	return s.PackageRepo.GetByPartnerID(ctx, partnerID)
+}
+
+// validatePackageScopes checks that a package has scopes and all of them are registered and active
+func (s *ScopePackageService) validatePackageScopes(ctx context.Context, scopes []string) error {
+	if len(scopes) == 0 {
+		return &utils.ValidationError{Field: "scopes", Message: "scopes is required"}
+	}
+	items := make([]models.ScopeItem, len(scopes))
+	for i, name := range scopes {
+		items[i] = models.ScopeItem{ScopeName: name, Enabled: true}
+	}
+	return s.ScopeRegistry.ValidateScopes(ctx, items)
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// scopeRegistryTTL bounds how long another process (e.g. the job worker) may use a stale registry
+const scopeRegistryTTL = time.Minute
+
+var scopeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+// ScopeRegistry manages scope definitions and keeps a cached copy for request-time filtering
+type ScopeRegistry struct {
+	ScopeDefRepo *repository.ScopeDefinitionRepository
+
+	mu       sync.RWMutex
+	defs     map[string]*models.ScopeDefinition
+	loadedAt time.Time
+}
+
+// NewScopeRegistry creates a new scope registry
+func NewScopeRegistry(scopeDefRepo *repository.ScopeDefinitionRepository) *ScopeRegistry {
+	return &ScopeRegistry{
+		ScopeDefRepo: scopeDefRepo,
+	}
+}
+
+// Definitions returns all scope definitions keyed by name (cached)
+func (r *ScopeRegistry) Definitions(ctx context.Context) (map[string]*models.ScopeDefinition, error) {
+	r.mu.RLock()
+	defs, loadedAt := r.defs, r.loadedAt
+	r.mu.RUnlock()
+	if defs != nil && time.Since(loadedAt) < scopeRegistryTTL {
+		return defs, nil
+	}
+
+	list, err := r.ScopeDefRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defs = make(map[string]*models.ScopeDefinition, len(list))
+	for _, d := range list {
+		defs[d.Name] = d
+	}
+
+	r.mu.Lock()
+	r.defs, r.loadedAt = defs, time.Now()
+	r.mu.Unlock()
+
+	return defs, nil
+}
+
+// invalidate drops the cache so the next lookup reloads from the database
+func (r *ScopeRegistry) invalidate() {
+	r.mu.Lock()
+	r.defs = nil
+	r.mu.Unlock()
+}
+
+// List retrieves all scope definitions
+func (r *ScopeRegistry) List(ctx context.Context) ([]*models.ScopeDefinition, error) {
+	return r.ScopeDefRepo.GetAll(ctx)
+}
+
+// Create validates and registers a new scope
+func (r *ScopeRegistry) Create(ctx context.Context, req *models.CreateScopeDefinitionRequest) (*models.ScopeDefinition, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if !scopeNamePattern.MatchString(req.Name) {
+		return nil, &utils.ValidationError{Field: "name", Message: "name must be 2-50 characters of lowercase letters, digits or underscore, starting with a letter"}
+	}
+	if err := r.validateTKField(ctx, req.TKField); err != nil {
+		return nil, err
+	}
+
+	def := &models.ScopeDefinition{
+		Name:        req.Name,
+		Description: strings.TrimSpace(req.Description),
+		Mode:        models.ScopeModeDisclose,
+		MaskVisible: 1,
+		MaskTokens:  models.MaskTokensEach,
+		Sensitive:   req.Sensitive,
+		Active:      true,
+	}
+	if req.TKField != nil && *req.TKField != "" {
+		def.TKField = req.TKField
+	}
+	if req.Mode != "" {
+		def.Mode = req.Mode
+	}
+	if req.MaskVisible != nil {
+		def.MaskVisible = *req.MaskVisible
+	}
+	if req.MaskTokens != "" {
+		def.MaskTokens = req.MaskTokens
+	}
+	if err := validateScopeMode(def.Mode, def.TKField); err != nil {
+		return nil, err
+	}
+	if err := validateMaskRule(def.MaskVisible, def.MaskTokens); err != nil {
+		return nil, err
+	}
+
+	if err := r.ScopeDefRepo.Create(ctx, def); err != nil {
+		return nil, err
+	}
+	r.invalidate()
+
+	return def, nil
+}
+
+// Update validates and updates a scope definition
+func (r *ScopeRegistry) Update(ctx context.Context, name string, req *models.UpdateScopeDefinitionRequest) (*models.ScopeDefinition, error) {
+	if req.Description == nil && req.TKField == nil && req.Mode == nil && req.MaskVisible == nil && req.MaskTokens == nil &&
+		req.Sensitive == nil && req.Active == nil {
+		return nil, &utils.ValidationError{Field: "body", Message: "no fields to update"}
+	}
+	if err := r.validateTKField(ctx, req.TKField); err != nil {
+		return nil, err
+	}
+
+	// Validate the resulting mode/field combination against the stored definition
+	current, err := r.Definitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if existing := current[name]; existing != nil {
+		mode, field := existing.Mode, existing.TKField
+		if req.Mode != nil {
+			mode = *req.Mode
+		}
+		if req.TKField != nil {
+			field = req.TKField
+			if *field == "" {
+				field = nil
+			}
+		}
+		if err := validateScopeMode(mode, field); err != nil {
+			return nil, err
+		}
+
+		visible, tokens := existing.MaskVisible, existing.MaskTokens
+		if req.MaskVisible != nil {
+			visible = *req.MaskVisible
+		}
+		if req.MaskTokens != nil {
+			tokens = *req.MaskTokens
+		}
+		if err := validateMaskRule(visible, tokens); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.ScopeDefRepo.Update(ctx, name, req); err != nil {
+		return nil, err
+	}
+	r.invalidate()
+
+	defs, err := r.Definitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return defs[name], nil
+}
+
+// Delete removes a scope definition that is not assigned to any partner
+func (r *ScopeRegistry) Delete(ctx context.Context, name string) error {
+	if err := r.ScopeDefRepo.Delete(ctx, name); err != nil {
+		return err
+	}
+	r.invalidate()
+	return nil
+}
+
+// ValidateScopes checks that every scope being enabled is registered and active.
+// Disabling an unknown or inactive scope is allowed so stale rows can be cleaned up.
+func (r *ScopeRegistry) ValidateScopes(ctx context.Context, scopes []models.ScopeItem) error {
+	defs, err := r.Definitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	var invalid []string
+	for _, s := range scopes {
+		if !s.Enabled {
+			continue
+		}
+		if def := defs[s.ScopeName]; def == nil || !def.Active {
+			invalid = append(invalid, s.ScopeName)
+		}
+	}
+	if len(invalid) == 0 {
+		return nil
+	}
+
+	sort.Strings(invalid)
+	return &utils.ValidationError{
+		Field:   "scopes",
+		Message: fmt.Sprintf("unknown or inactive scope: %s", strings.Join(invalid, ", ")),
+	}
+}
+
+// validateScopeMode checks the scope mode and that verify scopes have a field to compare
+func validateScopeMode(mode string, field *string) error {
+	switch mode {
+	case models.ScopeModeDisclose:
+		return nil
+	case models.ScopeModeVerify, models.ScopeModeMask:
+		if field == nil {
+			return &utils.ValidationError{Field: "tk_field", Message: mode + " scopes require tk_field"}
+		}
+		return nil
+	}
+	return &utils.ValidationError{Field: "mode", Message: "mode must be disclose, verify or mask"}
+}
+
+// validateMaskRule checks the masking rule of a scope (visible characters 0-10, token handling each/whole)
+func validateMaskRule(visible int, tokens string) error {
+	if visible < 0 || visible > 10 {
+		return &utils.ValidationError{Field: "mask_visible", Message: "mask_visible must be between 0 and 10"}
+	}
+	if tokens != models.MaskTokensEach && tokens != models.MaskTokensWhole {
+		return &utils.ValidationError{Field: "mask_tokens", Message: "mask_tokens must be each or whole"}
+	}
+	return nil
+}
+
+// validateTKField checks that a tk_field (when provided and not empty) is an existing tk_data column
+func (r *ScopeRegistry) validateTKField(ctx context.Context, field *string) error {
+	if field == nil || *field == "" {
+		return nil
+	}
+	exists, err := r.ScopeDefRepo.TKFieldExists(ctx, *field)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &utils.ValidationError{Field: "tk_field", Message: fmt.Sprintf("tk_data has no column %q", *field)}
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/pkg/utils"
+	"github.com/xuri/excelize/v2"
+)
+
+// tkImportBatchSize is the number of rows upserted per transaction
+const tkImportBatchSize = 500
+
+// XLSX parts (worksheet, shared strings) larger than xlsxMemoryLimit are extracted to a temporary
+// file and read by the Rows iterator from there; only the compressed upload stays in memory.
+// xlsxUnzipLimit bounds the total uncompressed size (zip bombs).
+const (
+	xlsxMemoryLimit = 1 << 20
+	xlsxUnzipLimit  = 2 << 30
+)
+
+// Reasons of rows rejected by the database (the database error itself is only logged)
+const (
+	importReasonValueRejected = "rejected by database: invalid value"
+	importReasonBatchFailed   = "not imported: another row of the same batch was rejected, import it again"
+	importReasonDatabaseError = "not imported: database error, import it again"
+)
+
+// importRowReader reads raw rows from an import file
+type importRowReader interface {
+	Next() ([]string, error) // returns io.EOF when there are no more rows
+	Close() error
+}
+
+// csvImportReader streams rows from a CSV file
+type csvImportReader struct {
+	reader *csv.Reader
+}
+
+func (r *csvImportReader) Next() ([]string, error) {
+	record, err := r.reader.Read()
+	if err != nil && err != io.EOF {
+		return nil, &utils.ValidationError{Field: "file", Message: fmt.Sprintf("invalid CSV: %v", err)}
+	}
+	return record, err
+}
+
+func (r *csvImportReader) Close() error { return nil }
+
+// xlsxImportReader streams rows from the first sheet of an XLSX file
+type xlsxImportReader struct {
+	file *excelize.File
+	rows *excelize.Rows
+}
+
+func (r *xlsxImportReader) Next() ([]string, error) {
+	if !r.rows.Next() {
+		if err := r.rows.Error(); err != nil {
+			return nil, &utils.ValidationError{Field: "file", Message: fmt.Sprintf("invalid XLSX: %v", err)}
+		}
+		return nil, io.EOF
+	}
+	// Raw values keep date cells as Excel serial numbers instead of locale formatting
+	return r.rows.Columns(excelize.Options{RawCellValue: true})
+}
+
+func (r *xlsxImportReader) Close() error {
+	r.rows.Close()
+	return r.file.Close()
+}
+
+// newImportRowReader picks a reader based on the file extension (.csv or .xlsx)
+func newImportRowReader(fileName string, file io.Reader) (importRowReader, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return &csvImportReader{reader: reader}, nil
+	case ".xlsx":
+		f, err := excelize.OpenReader(file, excelize.Options{UnzipXMLSizeLimit: xlsxMemoryLimit, UnzipSizeLimit: xlsxUnzipLimit})
+		if err != nil {
+			return nil, &utils.ValidationError{Field: "file", Message: fmt.Sprintf("invalid XLSX: %v", err)}
+		}
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			f.Close()
+			return nil, &utils.ValidationError{Field: "file", Message: "XLSX file has no sheets"}
+		}
+		rows, err := f.Rows(sheets[0])
+		if err != nil {
+			f.Close()
+			return nil, &utils.ValidationError{Field: "file", Message: fmt.Sprintf("invalid XLSX: %v", err)}
+		}
+		return &xlsxImportReader{file: f, rows: rows}, nil
+	default:
+		return nil, &utils.ValidationError{Field: "file", Message: "unsupported file type, use .csv or .xlsx"}
+	}
+}
+
+// Import streams a CSV/XLSX file into tk_data. Each row is validated; valid rows are
+// upserted in batched transactions. With dryRun nothing is committed but the report
+// shows exactly what would be inserted, updated or rejected.
+func (s *TKService) Import(ctx context.Context, fileName string, file io.Reader, dryRun bool) (*models.TKImportReport, error) {
+	reader, err := newImportRowReader(fileName, file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	header, err := reader.Next()
+	if err == io.EOF {
+		return nil, &utils.ValidationError{Field: "file", Message: "file is empty"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff") // Excel BOM
+	}
+
+	cols := map[string]int{}
+	for _, name := range []string{"nik", "nama", "tanggal_lahir", "alamat", "status_kepesertaan"} {
+		cols[name] = indexOf(header, name)
+	}
+	for _, name := range []string{"nik", "nama", "tanggal_lahir", "status_kepesertaan"} {
+		if cols[name] < 0 {
+			return nil, &utils.ValidationError{Field: "file", Message: fmt.Sprintf("missing required column: %s", name)}
+		}
+	}
+
+	isXLSX := strings.EqualFold(filepath.Ext(fileName), ".xlsx")
+	report := &models.TKImportReport{DryRun: dryRun, Rows: []models.TKImportRowResult{}}
+	seen := make(map[string]int)
+	batch := make([]models.TKImportRow, 0, tkImportBatchSize)
+	batchIdx := make([]int, 0, tkImportBatchSize) // index of each batch row in report.Rows
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		statuses, err := s.TKRepo.UpsertBatch(ctx, batch, dryRun)
+		var rowErr *repository.TKImportRowError
+		if err != nil {
+			log.Printf("TKService.Import - %v", err)
+			errors.As(err, &rowErr)
+		}
+		for i, idx := range batchIdx {
+			result := &report.Rows[idx]
+			if err != nil {
+				result.Status = models.ImportRowRejected
+				result.Reason = importReason(err, rowErr, batch[i].Row)
+				report.Rejected++
+				continue
+			}
+			result.Status = statuses[i]
+			switch statuses[i] {
+			case models.ImportRowInserted:
+				report.Inserted++
+			case models.ImportRowUpdated:
+				report.Updated++
+			default:
+				report.Unchanged++
+			}
+		}
+		batch = batch[:0]
+		batchIdx = batchIdx[:0]
+	}
+
+	rowNum := 1 // header
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rowNum++
+		if isEmptyRecord(record) {
+			continue
+		}
+
+		report.Total++
+		row, reason := parseImportRecord(record, cols, rowNum, isXLSX)
+		if reason == "" {
+			if first, dup := seen[row.NIK]; dup {
+				reason = fmt.Sprintf("duplicate NIK, first seen in row %d", first)
+			}
+		}
+		if reason != "" {
+			report.Rows = append(report.Rows, models.TKImportRowResult{
+				Row: rowNum, NIK: row.NIK, Status: models.ImportRowRejected, Reason: reason,
+			})
+			report.Rejected++
+			continue
+		}
+
+		seen[row.NIK] = rowNum
+		report.Rows = append(report.Rows, models.TKImportRowResult{Row: rowNum, NIK: row.NIK})
+		batch = append(batch, row)
+		batchIdx = append(batchIdx, len(report.Rows)-1)
+		if len(batch) >= tkImportBatchSize {
+			flush()
+		}
+	}
+	flush()
+
+	return report, nil
+}
+
+// importReason returns the reason of a row of a batch that UpsertBatch failed to store
+func importReason(err error, rowErr *repository.TKImportRowError, row int) string {
+	switch {
+	case rowErr == nil:
+		return importReasonDatabaseError
+	case rowErr.Row != row:
+		return importReasonBatchFailed
+	case errors.Is(err, repository.ErrTKValueRejected):
+		return importReasonValueRejected
+	default:
+		return importReasonDatabaseError
+	}
+}
+
+// parseImportRecord validates a raw row and returns the reason when it is rejected
+func parseImportRecord(record []string, cols map[string]int, rowNum int, isXLSX bool) (models.TKImportRow, string) {
+	row := models.TKImportRow{
+		Row:               rowNum,
+		NIK:               column(record, cols["nik"]),
+		Nama:              column(record, cols["nama"]),
+		Alamat:            column(record, cols["alamat"]),
+		StatusKepesertaan: strings.ToLower(column(record, cols["status_kepesertaan"])),
+	}
+
+	if _, err := utils.ParseNIK(row.NIK); err != nil {
+		return row, err.Error()
+	}
+	if row.Nama == "" {
+		return row, "nama is required"
+	}
+	if len(row.Nama) > 200 {
+		return row, "nama must be maximum 200 characters"
+	}
+
+	dob, err := parseImportDate(column(record, cols["tanggal_lahir"]), isXLSX)
+	if err != nil {
+		return row, err.Error()
+	}
+	if dob.After(time.Now()) {
+		return row, "tanggal_lahir cannot be in the future"
+	}
+	if err := utils.ValidateNIKWithDOB(row.NIK, dob); err != nil {
+		return row, err.Error()
+	}
+	row.TanggalLahir = dob
+
+	if !models.IsValidTKStatus(row.StatusKepesertaan) {
+		return row, fmt.Sprintf("status_kepesertaan must be one of: %s, %s, %s", models.TKStatusAktif, models.TKStatusNonaktif, models.TKStatusUnknown)
+	}
+
+	return row, ""
+}
+
+// parseImportDate parses YYYY-MM-DD (as TKRepository.Create does). XLSX date cells
+// are additionally accepted as Excel serial numbers.
+func parseImportDate(value string, isXLSX bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("tanggal_lahir is required")
+	}
+	if dob, err := time.Parse("2006-01-02", value); err == nil {
+		return dob, nil
+	}
+	if isXLSX {
+		if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 {
+			if t, err := excelize.ExcelDateToTime(serial, false); err == nil {
+				return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date format, use YYYY-MM-DD")
+}
+
+// isEmptyRecord reports whether every column of a row is blank
+func isEmptyRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/username/go-gin-backend/internal/models"
+	"github.com/username/go-gin-backend/internal/repository"
+	"github.com/username/go-gin-backend/pkg/utils"
+)
+
+// TKService handles admin management of TK master data
+type TKService struct {
+	TKRepo      *repository.TKRepository
+	HistoryRepo *repository.TKHistoryRepository
+}
+
+// NewTKService creates a new TK service
+func NewTKService(tkRepo *repository.TKRepository, historyRepo *repository.TKHistoryRepository) *TKService {
+	return &TKService{
+		TKRepo:      tkRepo,
+		HistoryRepo: historyRepo,
+	}
+}
+
+// List retrieves TK data with search (NIK prefix or name) and page-based pagination
+func (s *TKService) List(ctx context.Context, search string, page, limit int) (*models.TKListResponse, error) {
+	items, total, err := s.TKRepo.Search(ctx, strings.TrimSpace(search), limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TKListResponse{
+		Items: items,
+		Pagination: models.Pagination{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: (total + limit - 1) / limit,
+		},
+	}, nil
+}
+
+// Get retrieves TK data by NIK (nil if not found)
+func (s *TKService) Get(ctx context.Context, nik string) (*models.TKData, error) {
+	return s.TKRepo.GetByNIK(ctx, nik)
+}
+
+// Create validates and creates TK data
+func (s *TKService) Create(ctx context.Context, req *models.CreateTKDataRequest) (*models.TKData, error) {
+	req.NIK = strings.TrimSpace(req.NIK)
+	req.Nama = strings.TrimSpace(req.Nama)
+	req.Alamat = strings.TrimSpace(req.Alamat)
+
+	if req.NIK == "" {
+		return nil, &utils.ValidationError{Field: "nik", Message: "nik is required"}
+	}
+	if req.Nama == "" {
+		return nil, &utils.ValidationError{Field: "nama", Message: "nama is required"}
+	}
+	if err := validateTanggalLahir(req.TanggalLahir, true); err != nil {
+		return nil, err
+	}
+	// Same rules as checking, so every stored record can be checked
+	if err := validateNIKWithDOB(req.NIK, req.TanggalLahir); err != nil {
+		return nil, err
+	}
+	if req.StatusKepesertaan == "" {
+		req.StatusKepesertaan = models.TKStatusUnknown
+	}
+	if err := validateTKStatus(req.StatusKepesertaan); err != nil {
+		return nil, err
+	}
+
+	if err := s.TKRepo.Create(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return s.TKRepo.GetByNIK(ctx, req.NIK)
+}
+
+// Update validates and updates TK data (only provided fields are changed)
+func (s *TKService) Update(ctx context.Context, nik string, req *models.UpdateTKDataRequest) (*models.TKData, error) {
+	req.Nama = strings.TrimSpace(req.Nama)
+	req.Alamat = strings.TrimSpace(req.Alamat)
+
+	if req.Nama == "" && req.TanggalLahir == "" && req.Alamat == "" && req.StatusKepesertaan == "" {
+		return nil, &utils.ValidationError{Field: "body", Message: "no fields to update"}
+	}
+	if err := validateTanggalLahir(req.TanggalLahir, false); err != nil {
+		return nil, err
+	}
+	if req.TanggalLahir != "" {
+		if err := validateNIKWithDOB(nik, req.TanggalLahir); err != nil {
+			return nil, err
+		}
+	}
+	if req.StatusKepesertaan != "" {
+		if err := validateTKStatus(req.StatusKepesertaan); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.TKRepo.Update(ctx, nik, req); err != nil {
+		return nil, err
+	}
+
+	return s.TKRepo.GetByNIK(ctx, nik)
+}
+
+// Delete deletes TK data by NIK
+func (s *TKService) Delete(ctx context.Context, nik string) error {
+	return s.TKRepo.Delete(ctx, nik)
+}
+
+// History retrieves the participation status timeline of a worker. History of deleted
+// workers is kept, so ErrTKNotFound is only returned when nothing is known about the NIK.
+func (s *TKService) History(ctx context.Context, nik string) (*models.TKStatusTimeline, error) {
+	tk, err := s.TKRepo.GetByNIK(ctx, nik)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.HistoryRepo.GetByNIK(ctx, nik)
+	if err != nil {
+		return nil, err
+	}
+	if tk == nil && len(history) == 0 {
+		return nil, repository.ErrTKNotFound
+	}
+
+	timeline := &models.TKStatusTimeline{NIK: nik, Timeline: history}
+	if tk != nil {
+		timeline.CurrentStatus = &tk.StatusKepesertaan
+	}
+
+	return timeline, nil
+}
+
+// validateTanggalLahir checks the YYYY-MM-DD format and that the date is not in the future
+func validateTanggalLahir(value string, required bool) error {
+	if value == "" {
+		if required {
+			return &utils.ValidationError{Field: "tanggal_lahir", Message: "tanggal_lahir is required"}
+		}
+		return nil
+	}
+
+	dob, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return &utils.ValidationError{Field: "tanggal_lahir", Message: "invalid date format, use YYYY-MM-DD"}
+	}
+	if dob.After(time.Now()) {
+		return &utils.ValidationError{Field: "tanggal_lahir", Message: "tanggal_lahir cannot be in the future"}
+	}
+
+	return nil
+}
+
+// validateNIKWithDOB checks the NIK structure and that a YYYY-MM-DD date of birth (already
+// validated by validateTanggalLahir) agrees with the date encoded in it
+func validateNIKWithDOB(nik, tanggalLahir string) error {
+	dob, err := time.Parse("2006-01-02", tanggalLahir)
+	if err != nil {
+		return &utils.ValidationError{Field: "tanggal_lahir", Message: "invalid date format, use YYYY-MM-DD"}
+	}
+	return utils.ValidateNIKWithDOB(nik, dob)
+}
+
+// validateTKStatus checks status_kepesertaan against tk_status_enum
+func validateTKStatus(status string) error {
+	if !models.IsValidTKStatus(status) {
+		return &utils.ValidationError{
+			Field:   "status_kepesertaan",
+			Message: fmt.Sprintf("status_kepesertaan must be one of: %s, %s, %s", models.TKStatusAktif, models.TKStatusNonaktif, models.TKStatusUnknown),
+		}
+	}
+	return nil
+}
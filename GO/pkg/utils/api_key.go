@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APIKeyPrefix starts every partner API key: pks_live_<key id>_<secret>
+const APIKeyPrefix = "pks_live_"
+
+// Sizes of the random parts of an API key in bytes (hex encoded in the key)
+const (
+	apiKeyIDSize     = 8
+	apiKeySecretSize = 32
+)
+
+// minAPIKeyPepperSize is the minimum length of the API key pepper in bytes
+const minAPIKeyPepperSize = 32
+
+// LoadAPIKeyPepper decodes a base64 API key pepper (at least 32 bytes). An empty value returns a nil pepper.
+func LoadAPIKeyPepper(encoded string) ([]byte, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+
+	pepper, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("API key pepper must be base64: %w", err)
+	}
+	if len(pepper) < minAPIKeyPepperSize {
+		return nil, fmt.Errorf("API key pepper must be at least %d bytes", minAPIKeyPepperSize)
+	}
+
+	return pepper, nil
+}
+
+// GenerateAPIKey generates a random API key and returns it with its key ID and secret
+func GenerateAPIKey() (key, keyID, secret string) {
+	id := make([]byte, apiKeyIDSize)
+	sec := make([]byte, apiKeySecretSize)
+	rand.Read(id)
+	rand.Read(sec)
+
+	keyID = hex.EncodeToString(id)
+	secret = hex.EncodeToString(sec)
+	return APIKeyPrefix + keyID + "_" + secret, keyID, secret
+}
+
+// ParseAPIKey splits a pks_live_ API key into its hex key ID and secret
+func ParseAPIKey(key string) (keyID, secret string, ok bool) {
+	rest, found := strings.CutPrefix(key, APIKeyPrefix)
+	if !found {
+		return "", "", false
+	}
+	keyID, secret, found = strings.Cut(rest, "_")
+	if !found || len(keyID) != 2*apiKeyIDSize || len(secret) != 2*apiKeySecretSize || !isHex(keyID) || !isHex(secret) {
+		return "", "", false
+	}
+	return keyID, secret, true
+}
+
+// isHex reports whether s only holds lowercase hex digits, as GenerateAPIKey writes them
+func isHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// HashAPIKeySecret returns the stored hash of an API key secret: HMAC-SHA256 keyed with the pepper
+func HashAPIKeySecret(pepper []byte, secret string) string {
+	mac := hmac.New(sha256.New, pepper)
+	mac.Write([]byte(secret))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HashLegacyAPIKey returns the stored hash of a legacy (UUID) API key: SHA-256 hex of the whole key,
+// as computed by migration V20
+func HashLegacyAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
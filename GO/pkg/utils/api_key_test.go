@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAPIKey(t *testing.T) {
+	keyID := "0123456789abcdef"
+	secret := strings.Repeat("ab", 32)
+
+	tests := []struct {
+		name   string
+		key    string
+		wantID string
+		wantOK bool
+	}{
+		{"valid", APIKeyPrefix + keyID + "_" + secret, keyID, true},
+		{"legacy UUID key", "550e8400-e29b-41d4-a716-446655440000", "", false},
+		{"missing prefix", keyID + "_" + secret, "", false},
+		{"other prefix", "pks_test_" + keyID + "_" + secret, "", false},
+		{"missing separator", APIKeyPrefix + keyID + secret, "", false},
+		{"short key ID", APIKeyPrefix + keyID[:15] + "_" + secret, "", false},
+		{"long key ID", APIKeyPrefix + keyID + "0_" + secret, "", false},
+		{"short secret", APIKeyPrefix + keyID + "_" + secret[:63], "", false},
+		{"long secret", APIKeyPrefix + keyID + "_" + secret + "0", "", false},
+		{"extra separator in secret", APIKeyPrefix + keyID + "_" + secret[:32] + "_" + secret[33:], "", false},
+		{"key ID not hex", APIKeyPrefix + "0123456789abcdeg_" + secret, "", false},
+		{"uppercase secret", APIKeyPrefix + keyID + "_" + strings.ToUpper(secret), "", false},
+		{"empty", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotID, gotSecret, ok := ParseAPIKey(tt.key)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseAPIKey(%q) ok = %v, want %v", tt.key, ok, tt.wantOK)
+			}
+			if !ok {
+				if gotID != "" || gotSecret != "" {
+					t.Errorf("ParseAPIKey(%q) = %q, %q, want empty values", tt.key, gotID, gotSecret)
+				}
+				return
+			}
+			if gotID != tt.wantID || gotSecret != secret {
+				t.Errorf("ParseAPIKey(%q) = %q, %q, want %q, %q", tt.key, gotID, gotSecret, tt.wantID, secret)
+			}
+		})
+	}
+}
+
+func TestGenerateAPIKeyParses(t *testing.T) {
+	key, keyID, secret := GenerateAPIKey()
+	gotID, gotSecret, ok := ParseAPIKey(key)
+	if !ok || gotID != keyID || gotSecret != secret {
+		t.Errorf("ParseAPIKey(GenerateAPIKey()) = %q, %q, %v, want %q, %q, true", gotID, gotSecret, ok, keyID, secret)
+	}
+}
+
+func TestHashAPIKeySecret(t *testing.T) {
+	pepper := []byte("key")
+	otherPepper := []byte("another key")
+	secret := "The quick brown fox jumps over the lazy dog"
+
+	tests := []struct {
+		name   string
+		pepper []byte
+		secret string
+		want   string
+	}{
+		// Published HMAC-SHA256 test vector
+		{"known answer", pepper, secret, "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"},
+		// Without a pepper the hash is HMAC with an empty key (startup refuses it in production)
+		{"empty pepper", nil, "", "b613679a0814d9ec772f95d778c35fc5ff1697c493715653c6c712144292c5ad"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HashAPIKeySecret(tt.pepper, tt.secret); got != tt.want {
+				t.Errorf("HashAPIKeySecret() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if HashAPIKeySecret(pepper, secret) == HashAPIKeySecret(otherPepper, secret) {
+		t.Error("HashAPIKeySecret() does not depend on the pepper")
+	}
+	if HashAPIKeySecret(pepper, secret) == HashLegacyAPIKey(secret) {
+		t.Error("HashAPIKeySecret() equals the unkeyed legacy hash")
+	}
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// testAuditRecord returns a chained record as written before V21 (no api_key_id)
+func testAuditRecord() AuditChainRecord {
+	return AuditChainRecord{
+		ID:              "6f1c2a9e-3f4b-4d4a-9a57-0c2f5d2b7e11",
+		PartnerID:       "0b7e6c1d-8a2f-4e3b-9c5d-1f2a3b4c5d6e",
+		NIK:             "3201011501900001",
+		ScopesUsed:      json.RawMessage(`["name"]`),
+		RequestPayload:  json.RawMessage(`{"nik":"3201011501900001","purpose":"kyc_onboarding"}`),
+		ResponsePayload: json.RawMessage(`{"found":true}`),
+		CreatedAt:       time.Date(2026, 9, 1, 8, 30, 0, 123456789, time.UTC),
+		ChainSeq:        1,
+		PrevHash:        AuditChainGenesis,
+	}
+}
+
+func TestAuditRowHashCanonicalForm(t *testing.T) {
+	// Exact bytes hashed for a row without api_key_id: changing them breaks every stored chain
+	canonical := `{"id":"6f1c2a9e-3f4b-4d4a-9a57-0c2f5d2b7e11","partner_id":"0b7e6c1d-8a2f-4e3b-9c5d-1f2a3b4c5d6e",` +
+		`"user_id":null,"nik":"3201011501900001","scopes_used":["name"],` +
+		`"request_payload":{"nik":"3201011501900001","purpose":"kyc_onboarding"},"response_payload":{"found":true},` +
+		`"result_code":null,"purpose":null,"consent_ref":null,"chain_seq":1,` +
+		`"prev_hash":"` + AuditChainGenesis + `","created_at":"2026-09-01T08:30:00.123456Z"}`
+	sum := sha256.Sum256([]byte(canonical))
+	want := hex.EncodeToString(sum[:])
+
+	got, err := AuditRowHash(testAuditRecord())
+	if err != nil {
+		t.Fatalf("AuditRowHash() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("AuditRowHash() = %s, want %s", got, want)
+	}
+}
+
+func TestAuditRowHash(t *testing.T) {
+	base, err := AuditRowHash(testAuditRecord())
+	if err != nil {
+		t.Fatalf("AuditRowHash() error = %v", err)
+	}
+	apiKeyID := "9d3c1b2a-4e5f-4a6b-8c7d-0e1f2a3b4c5d"
+	empty := ""
+
+	tests := []struct {
+		name   string
+		modify func(*AuditChainRecord)
+		same   bool
+	}{
+		{"nanoseconds below the microsecond", func(r *AuditChainRecord) {
+			r.CreatedAt = r.CreatedAt.Add(-789 * time.Nanosecond)
+		}, true},
+		{"same instant in another time zone", func(r *AuditChainRecord) {
+			r.CreatedAt = r.CreatedAt.In(time.FixedZone("WIB", 7*3600))
+		}, true},
+		{"payload keys reordered and spaced as JSONB returns them", func(r *AuditChainRecord) {
+			r.RequestPayload = json.RawMessage(`{"purpose": "kyc_onboarding", "nik": "3201011501900001"}`)
+		}, true},
+		{"api_key_id explicitly nil", func(r *AuditChainRecord) {
+			r.APIKeyID = nil
+		}, true},
+		{"another microsecond", func(r *AuditChainRecord) {
+			r.CreatedAt = r.CreatedAt.Add(time.Microsecond)
+		}, false},
+		{"api_key_id set", func(r *AuditChainRecord) {
+			r.APIKeyID = &apiKeyID
+		}, false},
+		{"api_key_id empty instead of absent", func(r *AuditChainRecord) {
+			r.APIKeyID = &empty
+		}, false},
+		{"payload value changed", func(r *AuditChainRecord) {
+			r.ResponsePayload = json.RawMessage(`{"found":false}`)
+		}, false},
+		{"previous hash changed", func(r *AuditChainRecord) {
+			r.PrevHash = HashNIK("tampered")
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := testAuditRecord()
+			tt.modify(&rec)
+			got, err := AuditRowHash(rec)
+			if err != nil {
+				t.Fatalf("AuditRowHash() error = %v", err)
+			}
+			if (got == base) != tt.same {
+				t.Errorf("AuditRowHash() = %s, base %s, want same = %v", got, base, tt.same)
+			}
+		})
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"empty is null", ``, `null`},
+		{"keys sorted", `{"b":1,"a":{"d":true,"c":null}}`, `{"a":{"c":null,"d":true},"b":1}`},
+		{"whitespace removed", "[ 1, \"x\" ]\n", `[1,"x"]`},
+		{"large number as JSONB prints it", `{"n":1e+21}`, `{"n":1e+21}`},
+		{"large number written out", `{"n":1000000000000000000000}`, `{"n":1e+21}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := canonicalJSON(json.RawMessage(tt.raw))
+			if err != nil {
+				t.Fatalf("canonicalJSON(%q) error = %v", tt.raw, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("canonicalJSON(%q) = %s, want %s", tt.raw, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := canonicalJSON(json.RawMessage(`{"a":`)); err == nil {
+		t.Error("canonicalJSON() of invalid JSON: want error")
+	}
+}
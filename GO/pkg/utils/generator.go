@@ -44,8 +44,3 @@ func GeneratePKSNumber() string {
 	suffix := uuid.New().String()[:8]
 	return fmt.Sprintf("PKS-%d-%s", year, strings.ToUpper(suffix))
 }
-
-// GenerateAPIKey generates a random API key
-func GenerateAPIKey() string {
-	return uuid.New().String()
-}
@@ -0,0 +1,40 @@
+package utils
+
+import "strings"
+
+// maskChar replaces hidden characters in masked values
+const maskChar = '*'
+
+// MaskValue masks a value leaving `visible` characters at the start of each token.
+// With perToken every whitespace-separated word is masked on its own ("Budi Santoso" → "B*** S******"),
+// otherwise the whole value is one token and spaces are masked too, hiding the word lengths
+// ("B***********"). At least one character of a token is always hidden, so short tokens are
+// never returned in full.
+func MaskValue(value string, visible int, perToken bool) string {
+	if !perToken {
+		return maskToken([]rune(value), visible)
+	}
+
+	var b strings.Builder
+	for i, word := range strings.Fields(value) {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(maskToken([]rune(word), visible))
+	}
+	return b.String()
+}
+
+// maskToken keeps the first `visible` runes of a token (fewer for short tokens) and masks the rest
+func maskToken(token []rune, visible int) string {
+	if visible > len(token)-1 {
+		visible = len(token) - 1
+	}
+	if visible < 0 {
+		visible = 0
+	}
+	for i := visible; i < len(token); i++ {
+		token[i] = maskChar
+	}
+	return string(token)
+}
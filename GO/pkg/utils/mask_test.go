@@ -0,0 +1,32 @@
+package utils
+
+import "testing"
+
+func TestMaskValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		visible  int
+		perToken bool
+		want     string
+	}{
+		{"each word", "Budi Santoso", 1, true, "B*** S******"},
+		{"each word collapses whitespace", "  Budi   Santoso ", 1, true, "B*** S******"},
+		{"whole value masks spaces", "Budi Santoso", 1, false, "B***********"},
+		{"whole value keeps its length", "Jl. Merdeka 1", 3, false, "Jl.**********"},
+		{"short word keeps one character hidden", "Al Budi", 2, true, "A* Bu**"},
+		{"single character", "A", 1, false, "*"},
+		{"nothing visible", "Budi", 0, false, "****"},
+		{"negative visible", "Budi", -1, true, "****"},
+		{"multi-byte runes", "Ñoño Ángel", 1, true, "Ñ*** Á****"},
+		{"empty", "", 1, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaskValue(tt.value, tt.visible, tt.perToken); got != tt.want {
+				t.Errorf("MaskValue(%q, %d, %v) = %q, want %q", tt.value, tt.visible, tt.perToken, got, tt.want)
+			}
+		})
+	}
+}
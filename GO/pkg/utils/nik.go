@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// provinceCodes lists the province codes used as the first two NIK digits
+// (including the older and newer Papua codes)
+var provinceCodes = map[string]bool{
+	"11": true, "12": true, "13": true, "14": true, "15": true, "16": true, "17": true, "18": true, "19": true,
+	"21": true,
+	"31": true, "32": true, "33": true, "34": true, "35": true, "36": true,
+	"51": true, "52": true, "53": true,
+	"61": true, "62": true, "63": true, "64": true, "65": true,
+	"71": true, "72": true, "73": true, "74": true, "75": true, "76": true,
+	"81": true, "82": true,
+	"91": true, "92": true, "93": true, "94": true, "95": true, "96": true,
+}
+
+// NIKInfo holds the parts encoded in a NIK: PPKKCC DDMMYY SSSS
+type NIKInfo struct {
+	ProvinceCode string
+	RegencyCode  string
+	DistrictCode string
+	BirthDay     int // real day of birth (40 already subtracted for women)
+	BirthMonth   int
+	BirthYear    int  // last two digits of the birth year
+	Female       bool // encoded day was above 40
+	Serial       string
+}
+
+// ParseNIK validates the structure of a NIK (16 digits, known province code, non-zero regency,
+// district and serial, valid encoded birth date) and returns its parts
+func ParseNIK(nik string) (*NIKInfo, error) {
+	if len(nik) != 16 {
+		return nil, &ValidationError{Field: "nik", Message: "nik must be exactly 16 digits"}
+	}
+	for _, r := range nik {
+		if r < '0' || r > '9' {
+			return nil, &ValidationError{Field: "nik", Message: "nik must contain only digits"}
+		}
+	}
+
+	info := &NIKInfo{
+		ProvinceCode: nik[0:2],
+		RegencyCode:  nik[2:4],
+		DistrictCode: nik[4:6],
+		BirthDay:     atoi2(nik[6:8]),
+		BirthMonth:   atoi2(nik[8:10]),
+		BirthYear:    atoi2(nik[10:12]),
+		Serial:       nik[12:16],
+	}
+
+	if !provinceCodes[info.ProvinceCode] {
+		return nil, &ValidationError{Field: "nik", Message: fmt.Sprintf("nik has an unknown province code %s", info.ProvinceCode)}
+	}
+	if info.RegencyCode == "00" || info.DistrictCode == "00" {
+		return nil, &ValidationError{Field: "nik", Message: "nik has an invalid regency or district code"}
+	}
+	if info.Serial == "0000" {
+		return nil, &ValidationError{Field: "nik", Message: "nik has an invalid serial number"}
+	}
+
+	if info.BirthDay > 40 {
+		info.BirthDay -= 40
+		info.Female = true
+	}
+	if !validEncodedDate(info.BirthDay, info.BirthMonth, info.BirthYear) {
+		return nil, &ValidationError{Field: "nik", Message: "nik does not encode a valid birth date"}
+	}
+
+	return info, nil
+}
+
+// MatchesDOB reports whether a date of birth agrees with the date encoded in the NIK
+// (the century is not encoded, so only the last two digits of the year are compared)
+func (n *NIKInfo) MatchesDOB(dob time.Time) bool {
+	return dob.Day() == n.BirthDay && int(dob.Month()) == n.BirthMonth && dob.Year()%100 == n.BirthYear
+}
+
+// ValidateNIKWithDOB parses a NIK and checks that the supplied date of birth does not contradict it
+func ValidateNIKWithDOB(nik string, dob time.Time) error {
+	info, err := ParseNIK(nik)
+	if err != nil {
+		return err
+	}
+	if !info.MatchesDOB(dob) {
+		return &ValidationError{Field: "tanggal_lahir", Message: "tanggal_lahir does not match the birth date encoded in the nik"}
+	}
+	return nil
+}
+
+// validEncodedDate checks a day/month against a two-digit year (29 February is accepted for any year divisible by 4)
+func validEncodedDate(day, month, year int) bool {
+	if month < 1 || month > 12 || day < 1 {
+		return false
+	}
+	fullYear := 2000 + year // in 2000-2099 every year divisible by 4 is a leap year
+	return day <= time.Date(fullYear, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// atoi2 converts two ASCII digits to an int
+func atoi2(s string) int {
+	return int(s[0]-'0')*10 + int(s[1]-'0')
+}
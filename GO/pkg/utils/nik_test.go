@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseNIK(t *testing.T) {
+	tests := []struct {
+		name       string
+		nik        string
+		wantDay    int
+		wantMonth  int
+		wantYear   int
+		wantFemale bool
+	}{
+		{"male", "3201011501900001", 15, 1, 90, false},
+		{"female day plus 40", "3201015501900001", 15, 1, 90, true},
+		{"female first day", "3201014101900001", 1, 1, 90, true},
+		{"female day 71 in a 31-day month", "3201017107900001", 31, 7, 90, true},
+		{"29 February in a leap year", "3201012902960001", 29, 2, 96, false},
+		{"29 February in 2000", "3201012902000001", 29, 2, 0, false},
+		{"female 29 February in a leap year", "3201016902960001", 29, 2, 96, true},
+		{"newer Papua province code", "9601011501900001", 15, 1, 90, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseNIK(tt.nik)
+			if err != nil {
+				t.Fatalf("ParseNIK(%q) error = %v", tt.nik, err)
+			}
+			if info.BirthDay != tt.wantDay || info.BirthMonth != tt.wantMonth || info.BirthYear != tt.wantYear || info.Female != tt.wantFemale {
+				t.Errorf("ParseNIK(%q) = %02d-%02d-%02d female %v, want %02d-%02d-%02d female %v", tt.nik,
+					info.BirthDay, info.BirthMonth, info.BirthYear, info.Female, tt.wantDay, tt.wantMonth, tt.wantYear, tt.wantFemale)
+			}
+		})
+	}
+}
+
+func TestParseNIKInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		nik  string
+	}{
+		{"empty", ""},
+		{"15 digits", "320101150190000"},
+		{"17 digits", "32010115019000011"},
+		{"letter", "32010115019000A1"},
+		{"space", "3201011501 900001"},
+		{"full-width digits", "３２０１０１１５０１９００００１"},
+		{"province 00", "0001011501900001"},
+		{"unknown province 20", "2001011501900001"},
+		{"unknown province 99", "9901011501900001"},
+		{"regency 00", "3200011501900001"},
+		{"district 00", "3201001501900001"},
+		{"serial 0000", "3201011501900000"},
+		{"day 00", "3201010001900001"},
+		{"day 32", "3201013201900001"},
+		{"day 40", "3201014001900001"},
+		{"day 72", "3201017201900001"},
+		{"day 99", "3201019901900001"},
+		{"31 April", "3201013104900001"},
+		{"female 31 April", "3201017104900001"},
+		{"29 February in a non-leap year", "3201012902990001"},
+		{"female 29 February in a non-leap year", "3201016902990001"},
+		{"30 February in a leap year", "3201013002960001"},
+		{"month 00", "3201011500900001"},
+		{"month 13", "3201011513900001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseNIK(tt.nik)
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != "nik" {
+				t.Errorf("ParseNIK(%q) = %+v, %v, want a nik validation error", tt.nik, info, err)
+			}
+		})
+	}
+}
+
+func TestNIKMatchesDOB(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		nik  string
+		dob  time.Time
+		want bool
+	}{
+		{"same date", "3201011501900001", date(1990, time.January, 15), true},
+		{"female", "3201015501900001", date(1990, time.January, 15), true},
+		{"other century, same two-digit year", "3201011501900001", date(2090, time.January, 15), true},
+		{"year 05 in the 2000s", "3201011501050001", date(2005, time.January, 15), true},
+		{"year 05 in the 1900s", "3201011501050001", date(1905, time.January, 15), true},
+		{"year 05 is not 1950", "3201011501050001", date(1950, time.January, 15), false},
+		{"other year", "3201011501900001", date(1991, time.January, 15), false},
+		{"other month", "3201011501900001", date(1990, time.February, 15), false},
+		{"other day", "3201011501900001", date(1990, time.January, 16), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseNIK(tt.nik)
+			if err != nil {
+				t.Fatalf("ParseNIK(%q) error = %v", tt.nik, err)
+			}
+			if got := info.MatchesDOB(tt.dob); got != tt.want {
+				t.Errorf("MatchesDOB(%s) for %q = %v, want %v", tt.dob.Format("2006-01-02"), tt.nik, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateNIKWithDOB(t *testing.T) {
+	dob := time.Date(1990, time.January, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		nik       string
+		dob       time.Time
+		wantField string // "" = valid
+	}{
+		{"valid", "3201011501900001", dob, ""},
+		{"malformed nik", "3201011501", dob, "nik"},
+		{"date of birth contradicts the nik", "3201011501900001", dob.AddDate(0, 0, 1), "tanggal_lahir"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNIKWithDOB(tt.nik, tt.dob)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("ValidateNIKWithDOB(%q) error = %v", tt.nik, err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.wantField {
+				t.Errorf("ValidateNIKWithDOB(%q) error = %v, want a %s validation error", tt.nik, err, tt.wantField)
+			}
+		})
+	}
+}
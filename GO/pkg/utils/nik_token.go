@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// NIKTokenPrefix marks a NIK stored as a keyed token in audit_logs.nik
+const NIKTokenPrefix = "nikh1:"
+
+// minNIKKeySize is the minimum length of the NIK token key in bytes
+const minNIKKeySize = 32
+
+// LoadNIKKey decodes a base64 NIK token key (at least 32 bytes). An empty value returns a nil key.
+func LoadNIKKey(encoded string) ([]byte, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("NIK token key must be base64: %w", err)
+	}
+	if len(key) < minNIKKeySize {
+		return nil, fmt.Errorf("NIK token key must be at least %d bytes", minNIKKeySize)
+	}
+
+	return key, nil
+}
+
+// NIKKeyedHash returns the keyed hash of a NIK: HMAC-SHA256 hex over its SHA-256 hex (HashNIK).
+// It is the nik_hash of receipts and, prefixed, the token stored in audit_logs.nik; without the
+// key it cannot be brute-forced from the small NIK space.
+func NIKKeyedHash(key []byte, nik string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(HashNIK(nik)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NIKToken returns the keyed token of a NIK stored in audit_logs.nik
+func NIKToken(key []byte, nik string) string {
+	return NIKTokenPrefix + NIKKeyedHash(key, nik)
+}
+
+// NIKTokenOfHash returns the keyed token of a NIK from its SHA-256 hex (HashNIK), the nik_hash
+// of receipts signed before nik_hash was keyed
+func NIKTokenOfHash(key []byte, nikHash string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(nikHash))
+	return NIKTokenPrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsNIKToken reports whether a stored NIK is a keyed token rather than a plaintext NIK
+func IsNIKToken(nik string) bool {
+	return strings.HasPrefix(nik, NIKTokenPrefix)
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWS types of the tokens signed with the receipt key. Receipts and audit checkpoints share the
+// key and kid, so each verifier only accepts its own typ and one cannot pass for the other.
+const (
+	ReceiptTokenType    = "pks-receipt+jwt"
+	CheckpointTokenType = "pks-audit-checkpoint+jwt"
+
+	// legacyTokenType is the typ of receipts and checkpoints signed before they had their own,
+	// still accepted so those keep verifying
+	legacyTokenType = "JWT"
+)
+
+// ReceiptClaims represents the claims of a signed verification receipt
+type ReceiptClaims struct {
+	AuditID   string `json:"audit_id"`
+	PartnerID string `json:"partner_id"`
+	NIKHash   string `json:"nik_hash,omitempty"` // keyed hash of the NIK (NIKKeyedHash), omitted without AUDIT_NIK_KEY
+	Outcome   string `json:"outcome"`            // result code, or FOUND/NOT_FOUND without the result_detail scope
+	jwt.RegisteredClaims
+}
+
+// LoadReceiptKey decodes a base64 Ed25519 seed (32 bytes). An empty seed generates a new key,
+// reported through generated, whose receipts cannot be verified after a restart.
+func LoadReceiptKey(seed string) (key ed25519.PrivateKey, generated bool, err error) {
+	if seed == "" {
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+		return key, true, err
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(seed)
+	if err != nil {
+		return nil, false, fmt.Errorf("receipt signing key must be base64: %w", err)
+	}
+	if len(raw) != ed25519.SeedSize {
+		return nil, false, fmt.Errorf("receipt signing key must be a %d-byte Ed25519 seed", ed25519.SeedSize)
+	}
+
+	return ed25519.NewKeyFromSeed(raw), false, nil
+}
+
+// SignReceipt signs receipt claims as a compact JWS (EdDSA) with the key ID and ReceiptTokenType in the header
+func SignReceipt(claims *ReceiptClaims, key ed25519.PrivateKey, keyID string) (string, error) {
+	return signTyped(claims, ReceiptTokenType, key, keyID)
+}
+
+// ParseReceipt verifies a receipt signature against the public key with the given key ID
+func ParseReceipt(receipt string, key ed25519.PublicKey, keyID string) (*ReceiptClaims, error) {
+	token, err := jwt.ParseWithClaims(receipt, &ReceiptClaims{}, typedKeyFunc(ReceiptTokenType, key, keyID),
+		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}), jwt.WithIssuedAt())
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*ReceiptClaims); ok && token.Valid {
+		return claims, nil
+	}
+
+	return nil, jwt.ErrSignatureInvalid
+}
+
+// signTyped signs claims as a compact JWS (EdDSA) with the given typ and key ID in the header
+func signTyped(claims jwt.Claims, typ string, key ed25519.PrivateKey, keyID string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["typ"] = typ
+	token.Header["kid"] = keyID
+	return token.SignedString(key)
+}
+
+// typedKeyFunc returns the verification key of a token with the given typ (or the legacy one) and key ID
+func typedKeyFunc(typ string, key ed25519.PublicKey, keyID string) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if t, _ := token.Header["typ"].(string); t != typ && t != legacyTokenType {
+			return nil, fmt.Errorf("unexpected token type %q", t)
+		}
+		if kid, _ := token.Header["kid"].(string); kid != keyID {
+			return nil, fmt.Errorf("unknown key ID %q", kid)
+		}
+		return key, nil
+	}
+}
+
+// HashNIK returns the SHA-256 hex digest of a NIK. It is reversible by brute force and only
+// published keyed (NIKKeyedHash).
+func HashNIK(nik string) string {
+	sum := sha256.Sum256([]byte(nik))
+	return hex.EncodeToString(sum[:])
+}
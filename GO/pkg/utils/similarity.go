@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// NormalizeForMatch lowercases a value and reduces punctuation and whitespace to single spaces,
+// so "Budi  Santoso," and "budi santoso" compare as equal
+func NormalizeForMatch(value string) string {
+	fields := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// Similarity returns a score between 0 and 1 for two normalized values, based on the
+// Levenshtein distance. Word order is ignored, so "Santoso Budi" scores 1 against "Budi Santoso".
+func Similarity(a, b string) float64 {
+	score := math.Max(levenshteinRatio(a, b), levenshteinRatio(sortWords(a), sortWords(b)))
+	return math.Round(score*100) / 100
+}
+
+// levenshteinRatio converts the edit distance into a 0-1 similarity relative to the longer value
+func levenshteinRatio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein computes the edit distance between two rune slices (two-row dynamic programming)
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+// sortWords sorts the words of a normalized value
+func sortWords(value string) string {
+	words := strings.Fields(value)
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
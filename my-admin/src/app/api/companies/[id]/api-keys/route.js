@@ -3,14 +3,10 @@ import { API_CONFIG } from "@/lib/config";
 
 export async function GET(request, { params }) {
   try {
+    // In Next.js 16+, params is a Promise and must be awaited
     const { id } = await params;
-    if (!id || id === "undefined" || id === "null") {
-      return NextResponse.json(
-        { success: false, message: "Invalid company ID" },
-        { status: 400 }
-      );
-    }
 
+    // Get admin token from header
     const authHeader = request.headers.get("authorization");
     if (!authHeader || !authHeader.startsWith("Bearer ")) {
       return NextResponse.json(
@@ -18,9 +14,20 @@ export async function GET(request, { params }) {
         { status: 401 }
       );
     }
+
     const token = authHeader.replace("Bearer ", "");
 
-    const backendUrl = `${API_CONFIG.baseURL}${API_CONFIG.endpoints.partners.reveal(id)}`;
+    // Validate ID
+    if (!id || id === "undefined" || id === "null") {
+      console.error("Invalid ID in params:", id);
+      return NextResponse.json(
+        { success: false, message: "Invalid company ID" },
+        { status: 400 }
+      );
+    }
+
+    // Call backend API
+    const backendUrl = `${API_CONFIG.baseURL}${API_CONFIG.endpoints.partners.apiKeys(id)}`;
     let response;
     try {
       response = await fetch(backendUrl, {
@@ -31,20 +38,24 @@ export async function GET(request, { params }) {
         },
       });
     } catch (fetchError) {
+      console.error("Network error calling backend:", fetchError);
       return NextResponse.json(
-        {
-          success: false,
-          message: `Network error: ${fetchError.message || "Failed to connect to backend"}`,
-        },
+        { success: false, message: `Network error: ${fetchError.message || "Failed to connect to backend"}` },
         { status: 500 }
       );
     }
 
-    let data = {};
+    // Try to parse response body
+    let data;
     try {
-      const text = await response.text();
-      data = text ? JSON.parse(text) : {};
+      const responseText = await response.text();
+      if (responseText) {
+        data = JSON.parse(responseText);
+      } else {
+        data = {};
+      }
     } catch (parseError) {
+      console.error("Failed to parse backend response:", parseError);
       return NextResponse.json(
         { success: false, message: `Invalid response from backend (${response.status})` },
         { status: response.status || 500 }
@@ -53,27 +64,27 @@ export async function GET(request, { params }) {
 
     if (!response.ok) {
       return NextResponse.json(
-        { success: false, message: data.message || data.error || "Failed to reveal API key" },
+        { success: false, message: data.message || data.error || "Failed to fetch API keys" },
         { status: response.status }
       );
     }
 
+    if (data.success && data.data) {
+      return NextResponse.json(
+        { success: true, data: data.data },
+        { status: 200 }
+      );
+    }
+
     return NextResponse.json(
-      { success: true, data: data.data || {} },
+      { success: true, data: Array.isArray(data) ? data : [] },
       { status: 200 }
     );
   } catch (err) {
+    console.error("Error fetching API keys:", err);
     return NextResponse.json(
       { success: false, message: err.message || "Internal server error" },
       { status: 500 }
     );
   }
 }
-
-
-
-
-
-
-
-
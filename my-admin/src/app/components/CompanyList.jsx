@@ -325,12 +325,11 @@ function DetailModal({ company, onClose }) {
   const [error, setError] = useState("");
   const [companyData, setCompanyData] = useState(company);
   const [scopes, setScopes] = useState([]);
+  const [apiKeys, setApiKeys] = useState([]);
   const [validCompanyId, setValidCompanyId] = useState(null);
-  const [revealing, setRevealing] = useState(false);
   const [resetting, setResetting] = useState(false);
   const [showSecretModal, setShowSecretModal] = useState(false);
   const [newSecret, setNewSecret] = useState("");
-  const [secretMode, setSecretMode] = useState("reset"); // "reset" or "reveal"
   const ALLOWED_SCOPE_NAMES = ["name", "tanggal_lahir", "status_bpjs", "alamat"];
 
   useEffect(() => {
@@ -510,6 +509,24 @@ function DetailModal({ company, onClose }) {
           // Ignore scope fetch errors, just log
           console.warn("Failed to fetch scopes:", scopeErr);
         }
+
+        try {
+          const keysRes = await fetch(`/api/companies/${encodeURIComponent(companyId)}/api-keys`, {
+            method: "GET",
+            headers: {
+              "Content-Type": "application/json",
+              Authorization: `Bearer ${token}`,
+            },
+          });
+
+          if (keysRes.ok) {
+            const keysData = await keysRes.json();
+            setApiKeys(keysData.data || []);
+          }
+        } catch (keyErr) {
+          // Ignore API key fetch errors, just log
+          console.warn("Failed to fetch API keys:", keyErr);
+        }
       }
     } catch (err) {
       console.error("Error in fetchCompanyDetail:", err);
@@ -530,44 +547,6 @@ function DetailModal({ company, onClose }) {
     }
   }
 
-  async function handleRevealAPIKey() {
-    if (!validCompanyId) return;
-    setRevealing(true);
-    setError("");
-    try {
-      const token = getAdminToken();
-      if (!token) {
-        throw new Error("Not authenticated");
-      }
-      const res = await fetch(
-        `/api/companies/${encodeURIComponent(validCompanyId)}/reveal-api-key`,
-        {
-          method: "GET",
-          headers: {
-            "Content-Type": "application/json",
-            Authorization: `Bearer ${token}`,
-          },
-        }
-      );
-      const data = await res.json().catch(() => ({}));
-      if (!res.ok || data.success === false) {
-        throw new Error(data.message || "Failed to reveal API key");
-      }
-
-      const apiKey = data?.data?.api_key;
-      if (apiKey) {
-        setNewSecret(apiKey);
-        setSecretMode("reveal");
-        setShowSecretModal(true);
-      }
-    } catch (err) {
-      console.error("Reveal API key error:", err);
-      setError(err.message || "Failed to reveal API key");
-    } finally {
-      setRevealing(false);
-    }
-  }
-
   async function handleResetAPIKey() {
     if (!validCompanyId) return;
     setResetting(true);
@@ -595,7 +574,6 @@ function DetailModal({ company, onClose }) {
       const apiKey = data?.data?.api_key;
       if (apiKey) {
         setNewSecret(apiKey);
-        setSecretMode("reset");
         setShowSecretModal(true);
         // Refresh company data after reset
         if (validCompanyId) {
@@ -663,26 +641,43 @@ function DetailModal({ company, onClose }) {
               </div>
               <div className="col-span-2">
                 <div className="flex-1 mb-2">
-                  <label className="text-sm font-medium text-slate-500">API Key</label>
-                  <p className="mt-1 text-sm font-mono text-slate-900 bg-slate-50 px-2 py-1 rounded">
-                    ************ (hidden)
-                  </p>
+                  <label className="text-sm font-medium text-slate-500">API Keys</label>
+                  {apiKeys.length > 0 ? (
+                    <ul className="mt-1 space-y-1">
+                      {apiKeys.map((key) => (
+                        <li
+                          key={key.id}
+                          className="flex items-center justify-between text-sm bg-slate-50 px-2 py-1 rounded"
+                        >
+                          <span className="font-mono text-slate-900">
+                            {key.label}:{" "}
+                            {key.key_id ? `pks_live_${key.key_id}_************` : "************ (format lama)"}
+                          </span>
+                          <span
+                            className={`text-xs font-semibold ${
+                              key.state === "active" ? "text-green-700" : "text-slate-500"
+                            }`}
+                          >
+                            {key.state}
+                            {key.state === "active" && key.expires_at
+                              ? ` s/d ${new Date(key.expires_at).toLocaleString()}`
+                              : ""}
+                          </span>
+                        </li>
+                      ))}
+                    </ul>
+                  ) : (
+                    <p className="mt-1 text-sm text-slate-500">Belum ada API key</p>
+                  )}
                   <p className="text-xs text-slate-500 mt-1">
-                    API Key hanya tampil sekali saat dibuat, reveal, atau reset. Gunakan di header X-API-KEY untuk autentikasi.
+                    API Key hanya tampil sekali saat dibuat, dirotasi atau reset dan tidak bisa dilihat lagi. Reset mencabut semua key partner. Gunakan di header X-API-KEY untuk autentikasi.
                   </p>
                 </div>
                 <div className="flex gap-2">
-                  <button
-                    onClick={handleRevealAPIKey}
-                    className="h-10 rounded-md bg-slate-600 px-4 text-sm font-semibold text-white shadow-sm hover:bg-slate-500"
-                    disabled={revealing || resetting}
-                  >
-                    {revealing ? "Revealing..." : "Reveal API Key"}
-                  </button>
                   <button
                     onClick={handleResetAPIKey}
                     className="h-10 rounded-md bg-indigo-600 px-4 text-sm font-semibold text-white shadow-sm hover:bg-indigo-500"
-                    disabled={revealing || resetting}
+                    disabled={resetting}
                   >
                     {resetting ? "Resetting..." : "Reset API Key"}
                   </button>
@@ -763,7 +758,7 @@ function DetailModal({ company, onClose }) {
                       .filter((scope) => ALLOWED_SCOPE_NAMES.includes(scope.scope_name))
                       .map((scope) => (
                       <div
-                        key={scope.id}
+                        key={`${scope.source || "partner"}-${scope.scope_name}`}
                         className="flex items-center gap-2 text-sm"
                       >
                         {scope.enabled ? (
@@ -798,6 +793,9 @@ function DetailModal({ company, onClose }) {
                             l.toUpperCase()
                           )}
                         </span>
+                        {scope.state && scope.state !== "active" && (
+                          <span className="text-xs text-amber-600">({scope.state})</span>
+                        )}
                       </div>
                     ))}
                   </div>
@@ -821,7 +819,6 @@ function DetailModal({ company, onClose }) {
       {showSecretModal && (
         <SecretModal
           secret={newSecret}
-          mode={secretMode}
           onClose={() => {
             setShowSecretModal(false);
             setNewSecret("");
@@ -833,7 +830,7 @@ function DetailModal({ company, onClose }) {
 }
 
 // API Key Modal Component
-function SecretModal({ secret, mode = "reset", onClose }) {
+function SecretModal({ secret, onClose }) {
   const [copied, setCopied] = useState(false);
   const textRef = React.useRef(null);
 
@@ -912,12 +909,10 @@ function SecretModal({ secret, mode = "reset", onClose }) {
       <div className="bg-white rounded-2xl shadow-xl max-w-md w-full">
         <div className="border-b border-slate-200 px-6 py-4">
           <h3 className="text-lg font-semibold text-slate-900">
-            {mode === "reveal" ? "API Key Aktif" : "API Key Baru"}
+            API Key Baru
           </h3>
           <p className="text-sm text-slate-500 mt-1">
-            {mode === "reveal"
-              ? "Ini adalah API key aktif saat ini. Copy sekarang, tidak akan ditampilkan lagi."
-              : "API key baru sudah dibuat. Copy sekarang, key lama otomatis tidak berlaku."}
+            API key baru sudah dibuat. Copy sekarang, key lama otomatis tidak berlaku dan key ini tidak akan ditampilkan lagi.
             {" "}Gunakan di header X-API-KEY untuk autentikasi.
           </p>
         </div>
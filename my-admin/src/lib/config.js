@@ -13,7 +13,7 @@ export const API_CONFIG = {
       update: (id) => `/admin/partners/${id}`,
       delete: (id) => `/admin/partners/${id}`,
       scopes: (id) => `/admin/partners/${id}/scopes`,
-      reveal: (id) => `/admin/partners/${id}/reveal-api-key`,
+      apiKeys: (id) => `/admin/partners/${id}/api-keys`,
       reset: (id) => `/admin/partners/${id}/reset-api-key`,
     },
   },
@@ -28,7 +28,7 @@ export function convertScopesToBackend(frontendScopes) {
 
 /**
  * Convert backend scope array to frontend scope object
- * @param {Array<{scope_name: string, enabled: boolean}>} backendScopes
+ * @param {Array<{scope_name: string, enabled: boolean, state?: string}>} backendScopes
  * @returns {Object} Object with frontend keys
  */
 export function convertScopesFromBackend(backendScopes) {
@@ -39,11 +39,12 @@ export function convertScopesFromBackend(backendScopes) {
     frontendScopes[frontendKey] = false;
   }
   
-  // Set enabled scopes
+  // Set enabled scopes (only grants active today; upcoming/expired grants are listed too)
   for (const scope of backendScopes || []) {
     const frontendKey = REVERSE_SCOPE_MAPPING[scope.scope_name];
     if (frontendKey) {
-      frontendScopes[frontendKey] = scope.enabled;
+      const active = !scope.state || scope.state === "active";
+      frontendScopes[frontendKey] = frontendScopes[frontendKey] || (scope.enabled && active);
     }
   }
   